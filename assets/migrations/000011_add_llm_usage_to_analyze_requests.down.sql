@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS idx_analyze_requests_created_at;
+
+ALTER TABLE analyze_requests DROP COLUMN IF EXISTS llm_cost;
+ALTER TABLE analyze_requests DROP COLUMN IF EXISTS search_queries;
+ALTER TABLE analyze_requests DROP COLUMN IF EXISTS total_tokens;
+ALTER TABLE analyze_requests DROP COLUMN IF EXISTS output_tokens;
+ALTER TABLE analyze_requests DROP COLUMN IF EXISTS audio_tokens;
+ALTER TABLE analyze_requests DROP COLUMN IF EXISTS video_tokens;
+ALTER TABLE analyze_requests DROP COLUMN IF EXISTS prompt_tokens;
+ALTER TABLE analyze_requests DROP COLUMN IF EXISTS llm_model;
+
+ALTER TABLE analyze_requests DROP COLUMN IF EXISTS platform;
@@ -0,0 +1,12 @@
+ALTER TABLE analyze_requests ADD COLUMN IF NOT EXISTS platform VARCHAR(50) NOT NULL DEFAULT 'instagram';
+
+ALTER TABLE analyze_requests ADD COLUMN IF NOT EXISTS llm_model VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE analyze_requests ADD COLUMN IF NOT EXISTS prompt_tokens INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE analyze_requests ADD COLUMN IF NOT EXISTS video_tokens INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE analyze_requests ADD COLUMN IF NOT EXISTS audio_tokens INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE analyze_requests ADD COLUMN IF NOT EXISTS output_tokens INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE analyze_requests ADD COLUMN IF NOT EXISTS total_tokens INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE analyze_requests ADD COLUMN IF NOT EXISTS search_queries INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE analyze_requests ADD COLUMN IF NOT EXISTS llm_cost NUMERIC(12, 6) NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_analyze_requests_created_at ON analyze_requests(created_at);
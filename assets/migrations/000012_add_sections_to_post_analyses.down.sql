@@ -0,0 +1 @@
+ALTER TABLE post_analyses DROP COLUMN IF EXISTS sections;
@@ -0,0 +1,4 @@
+ALTER TABLE post_analyses ADD COLUMN IF NOT EXISTS sections JSONB NOT NULL DEFAULT '[]';
+
+-- analyses stored before streaming are always complete
+UPDATE post_analyses SET sections = '["summary", "content", "analysis", "remix", "publish"]';
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_post_contents_embedding ON post_contents USING hnsw (embedding vector_cosine_ops);
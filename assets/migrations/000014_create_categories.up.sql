@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS categories
+(
+    id         uuid PRIMARY KEY     DEFAULT uuid_generate_v4(),
+    name       VARCHAR(255) NOT NULL,
+    path       TEXT         NOT NULL UNIQUE,
+    suggested  BOOLEAN      NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS post_categories
+(
+    post_id     VARCHAR(255) NOT NULL REFERENCES posts (id) ON DELETE CASCADE,
+    category_id uuid         NOT NULL REFERENCES categories (id) ON DELETE CASCADE,
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (post_id, category_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_post_categories_category_id ON post_categories(category_id);
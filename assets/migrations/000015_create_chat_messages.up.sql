@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS chat_messages
+(
+    id         uuid PRIMARY KEY      DEFAULT uuid_generate_v4(),
+    user_id    uuid         NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    post_id    VARCHAR(255) NOT NULL REFERENCES posts (id) ON DELETE CASCADE,
+    role       VARCHAR(20)  NOT NULL,
+    content    TEXT         NOT NULL,
+    created_at TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_chat_messages_user_post ON chat_messages(user_id, post_id, created_at);
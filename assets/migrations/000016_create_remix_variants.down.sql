@@ -0,0 +1 @@
+DROP TABLE IF EXISTS remix_variants;
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS remix_variants
+(
+    id            uuid PRIMARY KEY      DEFAULT uuid_generate_v4(),
+    user_id       uuid         NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    post_id       VARCHAR(255) NOT NULL REFERENCES posts (id) ON DELETE CASCADE,
+    batch_id      uuid         NOT NULL,
+    section       VARCHAR(20)  NOT NULL,
+    tone          VARCHAR(50)  NOT NULL DEFAULT '',
+    target_length VARCHAR(20)  NOT NULL DEFAULT '',
+    language      VARCHAR(10)  NOT NULL DEFAULT '',
+    content       TEXT         NOT NULL,
+    favorite      BOOLEAN      NOT NULL DEFAULT FALSE,
+    created_at    TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_remix_variants_user_post ON remix_variants(user_id, post_id, section, created_at);
@@ -0,0 +1,2 @@
+ALTER TABLE post_analyses
+    DROP COLUMN IF EXISTS viral_score_breakdown;
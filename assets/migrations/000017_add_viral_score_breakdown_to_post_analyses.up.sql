@@ -0,0 +1,2 @@
+ALTER TABLE post_analyses
+    ADD COLUMN IF NOT EXISTS viral_score_breakdown JSONB;
@@ -0,0 +1,5 @@
+UPDATE post_analyses pa
+SET metrics = (SELECT COALESCE(jsonb_agg(t.metric - 'key' ORDER BY t.ord), '[]'::jsonb)
+               FROM jsonb_array_elements(pa.metrics) WITH ORDINALITY AS t(metric, ord))
+WHERE jsonb_typeof(pa.metrics) = 'array'
+  AND jsonb_array_length(pa.metrics) > 0;
@@ -0,0 +1,19 @@
+-- metrics were matched to the score by substrings of their label, the first match wins
+UPDATE post_analyses pa
+SET metrics = (SELECT COALESCE(jsonb_agg(
+                                       CASE
+                                           WHEN t.metric ? 'key' THEN t.metric
+                                           ELSE t.metric || jsonb_strip_nulls(jsonb_build_object('key',
+                                                   CASE
+                                                       WHEN LOWER(t.metric ->> 'label') LIKE '%top%' THEN 'topic'
+                                                       WHEN LOWER(t.metric ->> 'label') LIKE '%hook%' THEN 'hook'
+                                                       WHEN LOWER(t.metric ->> 'label') LIKE '%pacing%' THEN 'pacing'
+                                                       WHEN LOWER(t.metric ->> 'label') LIKE '%value%' THEN 'value'
+                                                       WHEN LOWER(t.metric ->> 'label') LIKE '%share%' THEN 'shareability'
+                                                       WHEN LOWER(t.metric ->> 'label') LIKE '%cta%' THEN 'cta'
+                                                       END))
+                                           END
+                                       ORDER BY t.ord), '[]'::jsonb)
+               FROM jsonb_array_elements(pa.metrics) WITH ORDINALITY AS t(metric, ord))
+WHERE jsonb_typeof(pa.metrics) = 'array'
+  AND jsonb_array_length(pa.metrics) > 0;
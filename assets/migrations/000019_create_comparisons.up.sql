@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS comparisons
+(
+    id         uuid PRIMARY KEY     DEFAULT uuid_generate_v4(),
+    user_id    uuid        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    post_ids   JSONB       NOT NULL DEFAULT '[]',
+    posts      JSONB       NOT NULL DEFAULT '[]',
+    metrics    JSONB       NOT NULL DEFAULT '[]',
+    narrative  TEXT        NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_comparisons_user_id ON comparisons(user_id, created_at);
@@ -0,0 +1,2 @@
+ALTER TABLE post_analyses
+    ADD COLUMN IF NOT EXISTS outlier_stats JSONB;
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS post_stat_snapshots
+(
+    id            uuid PRIMARY KEY      DEFAULT uuid_generate_v4(),
+    post_id       VARCHAR(255) NOT NULL REFERENCES posts (id) ON DELETE CASCADE,
+    day_offset    INT          NOT NULL,
+    viral_score   INT          NOT NULL DEFAULT 0,
+    status        VARCHAR(16)  NOT NULL DEFAULT 'pending',
+    attempts      INT          NOT NULL DEFAULT 0,
+    due_at        TIMESTAMPTZ  NOT NULL,
+    taken_at      TIMESTAMPTZ,
+    like_count    BIGINT       NOT NULL DEFAULT 0,
+    comment_count BIGINT       NOT NULL DEFAULT 0,
+    play_count    BIGINT       NOT NULL DEFAULT 0,
+    created_at    TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    UNIQUE (post_id, day_offset)
+);
+
+CREATE INDEX IF NOT EXISTS idx_post_stat_snapshots_due ON post_stat_snapshots(status, due_at);
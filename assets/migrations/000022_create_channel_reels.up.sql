@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS channel_reels
+(
+    channel_id    uuid         NOT NULL REFERENCES channels (id) ON DELETE CASCADE,
+    code          VARCHAR(255) NOT NULL,
+    taken_at      TIMESTAMPTZ  NOT NULL,
+    play_count    BIGINT       NOT NULL DEFAULT 0,
+    like_count    BIGINT       NOT NULL DEFAULT 0,
+    comment_count BIGINT       NOT NULL DEFAULT 0,
+    created_at    TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (channel_id, code)
+);
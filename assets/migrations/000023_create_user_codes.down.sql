@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_user_codes_code_hash;
+
+DROP TABLE IF EXISTS user_codes;
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS user_codes
+(
+    id          uuid PRIMARY KEY     DEFAULT uuid_generate_v4(),
+    user_id     uuid        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    purpose     VARCHAR(32) NOT NULL,
+    code_hash   VARCHAR(64) NOT NULL,
+    attempts    INT         NOT NULL DEFAULT 0,
+    expires_at  TIMESTAMPTZ NOT NULL,
+    consumed_at TIMESTAMPTZ,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_codes_user_id ON user_codes(user_id, purpose, created_at);
+CREATE INDEX IF NOT EXISTS idx_user_codes_code_hash ON user_codes(code_hash);
@@ -0,0 +1,2 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS sessions_revoked_at TIMESTAMPTZ;
@@ -0,0 +1,10 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS provider_id VARCHAR(255);
+
+UPDATE users u
+SET provider_id = i.provider_id
+FROM user_identities i
+WHERE i.user_id = u.id
+  AND i.provider = u.provider;
+
+DROP TABLE IF EXISTS user_identities;
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS user_identities
+(
+    id          uuid PRIMARY KEY      DEFAULT uuid_generate_v4(),
+    user_id     uuid         NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    provider    VARCHAR(32)  NOT NULL,
+    provider_id VARCHAR(255) NOT NULL,
+    email       VARCHAR(255) NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    UNIQUE (provider, provider_id),
+    UNIQUE (user_id, provider)
+);
+
+INSERT INTO user_identities (user_id, provider, provider_id, email)
+SELECT id, provider, provider_id, email
+FROM users
+WHERE provider_id IS NOT NULL
+  AND provider <> 'local'
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS provider_id;
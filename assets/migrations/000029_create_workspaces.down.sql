@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_analyze_requests_workspace_id;
+
+ALTER TABLE analyze_requests
+    DROP COLUMN IF EXISTS workspace_id;
+
+DROP TABLE IF EXISTS workspace_invitations;
+DROP TABLE IF EXISTS workspace_members;
+DROP TABLE IF EXISTS workspaces;
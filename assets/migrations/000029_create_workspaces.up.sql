@@ -0,0 +1,40 @@
+CREATE TABLE IF NOT EXISTS workspaces
+(
+    id                 uuid PRIMARY KEY      DEFAULT uuid_generate_v4(),
+    name               VARCHAR(100) NOT NULL,
+    require_two_factor BOOLEAN      NOT NULL DEFAULT FALSE,
+    created_by         uuid         REFERENCES users (id) ON DELETE SET NULL,
+    created_at         TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at         TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS workspace_members
+(
+    workspace_id uuid        NOT NULL REFERENCES workspaces (id) ON DELETE CASCADE,
+    user_id      uuid        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role         VARCHAR(16) NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (workspace_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_workspace_members_user_id ON workspace_members(user_id);
+
+CREATE TABLE IF NOT EXISTS workspace_invitations
+(
+    id           uuid PRIMARY KEY      DEFAULT uuid_generate_v4(),
+    workspace_id uuid         NOT NULL REFERENCES workspaces (id) ON DELETE CASCADE,
+    email        VARCHAR(255) NOT NULL,
+    role         VARCHAR(16)  NOT NULL,
+    token_hash   VARCHAR(64)  NOT NULL UNIQUE,
+    invited_by   uuid         REFERENCES users (id) ON DELETE SET NULL,
+    expires_at   TIMESTAMPTZ  NOT NULL,
+    accepted_at  TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_workspace_invitations_workspace_id ON workspace_invitations(workspace_id);
+
+ALTER TABLE analyze_requests
+    ADD COLUMN IF NOT EXISTS workspace_id uuid REFERENCES workspaces (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_analyze_requests_workspace_id ON analyze_requests(workspace_id);
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
 	"time"
@@ -24,13 +25,36 @@ type Client interface {
 		averageStats map[string]float64,
 		publishedAt time.Time,
 		targetRegion string,
-	) (string, string, *AnalysisResponse, error)
+	) (string, string, *AnalysisResponse, *Usage, error)
+	AnalyzeVideoStream(
+		platform model.SocialPlatform,
+		videoURL string,
+		caption string,
+		coauthors []string,
+		comments []string,
+		stats map[string]float64,
+		averageStats map[string]float64,
+		publishedAt time.Time,
+		targetRegion string,
+		onSection SectionHandler,
+	) (string, string, *AnalysisResponse, *Usage, error)
+
+	UploadFile(filePath, mimeType string) (*File, error)
+	GetFile(name string) (*File, error)
+	WaitForFileActive(name string, timeout time.Duration) (*File, error)
+	DeleteFile(name string) error
 }
 
+// AnalysisModel is the Gemini model used for video analysis.
+const AnalysisModel = "gemini-2.5-flash"
+
 type client struct {
 	BaseUrl    string
 	Token      string
 	HTTPClient *http.Client
+
+	// FilePollInterval is the delay between two state checks of an uploaded file.
+	FilePollInterval time.Duration
 }
 
 func NewClient(baseUrl, token string) Client {
@@ -41,10 +65,12 @@ func NewClient(baseUrl, token string) Client {
 			// Increased timeout for streaming
 			Timeout: 10 * time.Minute,
 		},
+		FilePollInterval: 2 * time.Second,
 	}
 }
 
-func (c *client) AnalyzeVideo(
+// analysisRequestBody builds the generateContent payload used to analyze a video.
+func analysisRequestBody(
 	platform model.SocialPlatform,
 	videoURL string,
 	caption string,
@@ -54,8 +80,7 @@ func (c *client) AnalyzeVideo(
 	averageStats map[string]float64,
 	publishedAt time.Time,
 	targetRegion string,
-) (string, string, *AnalysisResponse, error) {
-
+) ([]byte, error) {
 	currentTime := time.Now().Format(time.RFC1123)
 	pubTime := publishedAt.Format(time.RFC1123)
 	statsContext, _ := json.Marshal(stats)
@@ -126,6 +151,10 @@ MANDATORY ANALYSIS RULES
 - Scope should be derived from language, cultural references, detected personalities, hashtags, and web trend validation.
 - Scope confidence reflects how strongly signals align with that classification.
 
+7. METRICS
+- Return exactly one metric for each key: hook, topic, pacing, value, shareability, cta.
+- The key is always one of these English values, the label may be written in the output language.
+
 
 --------------------------------
 SEARCH STRATEGY (REQUIRED)
@@ -180,6 +209,7 @@ Scores must be realistic and justified.
     },
     "metrics": [
       {
+        "key": "hook | topic | pacing | value | shareability | cta",
         "label": "Hook Strength | Topic Potential | Pacing | Value Delivery | Shareability | CTA",
         "score": 0-100,
         "explanation": "Data-backed rationale for the score.",
@@ -292,7 +322,7 @@ FINAL INSTRUCTIONS
 					"analysis": map[string]interface{}{
 						"type": "OBJECT",
 						"properties": map[string]interface{}{
-							"score": map[string]interface{}{
+							"scope": map[string]interface{}{
 								"type": "OBJECT",
 								"properties": map[string]interface{}{
 									"level":      map[string]interface{}{"type": "STRING"},
@@ -304,11 +334,13 @@ FINAL INSTRUCTIONS
 								"items": map[string]interface{}{
 									"type": "OBJECT",
 									"properties": map[string]interface{}{
+										"key":         map[string]interface{}{"type": "STRING", "enum": model.MetricKeyValues()},
 										"label":       map[string]interface{}{"type": "STRING"},
 										"score":       map[string]interface{}{"type": "INTEGER"},
 										"explanation": map[string]interface{}{"type": "STRING"},
 										"suggestion":  map[string]interface{}{"type": "STRING"},
 									},
+									"required": []string{"key", "label", "score"},
 								},
 							},
 							"strengths":  map[string]interface{}{"type": "ARRAY", "items": map[string]string{"type": "STRING"}},
@@ -344,70 +376,84 @@ FINAL INSTRUCTIONS
 
 	body, err := json.Marshal(requestBody)
 	if err != nil {
-		return string(body), "", nil, errors.Wrap(err, "failed to marshal request body")
+		return nil, errors.Wrap(err, "failed to marshal request body")
 	}
+	return body, nil
+}
 
-	endpoint := "/v1beta/models/gemini-2.5-flash:generateContent"
+func (c *client) AnalyzeVideo(
+	platform model.SocialPlatform,
+	videoURL string,
+	caption string,
+	coauthors []string,
+	comments []string,
+	stats map[string]float64,
+	averageStats map[string]float64,
+	publishedAt time.Time,
+	targetRegion string,
+) (string, string, *AnalysisResponse, *Usage, error) {
+	body, err := analysisRequestBody(platform, videoURL, caption, coauthors, comments, stats, averageStats, publishedAt, targetRegion)
+	if err != nil {
+		return "", "", nil, nil, err
+	}
+
+	endpoint := fmt.Sprintf("/v1beta/models/%s:generateContent", AnalysisModel)
 	resp, err := c.doPost(endpoint, body, nil)
 	if err != nil {
-		return string(body), "", nil, err
+		return string(body), "", nil, nil, err
 	}
 	defer resp.Body.Close()
 
 	// 4. Parse the raw Google response
-	var googleResp struct {
-		Candidates []struct {
-			Content struct {
-				Parts []struct {
-					Text string `json:"text"`
-				} `json:"parts"`
-			} `json:"content"`
-		} `json:"candidates"`
-	}
-
+	var googleResp generateContentResponse
 	if err := json.NewDecoder(resp.Body).Decode(&googleResp); err != nil {
-		return string(body), "", nil, errors.Wrap(err, "failed to decode google response")
+		return string(body), "", nil, nil, errors.Wrap(err, "failed to decode google response")
 	}
 
-	if len(googleResp.Candidates) == 0 || len(googleResp.Candidates[0].Content.Parts) == 0 {
-		var apiErr struct {
-			Error struct {
-				Code    int    `json:"code"`
-				Message string `json:"message"`
-				Status  string `json:"status"`
-			} `json:"error"`
-		}
+	usage := googleResp.usage(AnalysisModel)
 
-		if err := json.NewDecoder(resp.Body).Decode(&apiErr); err == nil && apiErr.Error.Code != 0 {
-			e, _ := json.Marshal(apiErr)
-			return string(body), string(e), nil, errs.Newf(errs.Internal, err, apiErr.Error.Message)
+	if len(googleResp.Candidates) == 0 || len(googleResp.Candidates[0].Content.Parts) == 0 {
+		if googleResp.Error != nil && googleResp.Error.Code != 0 {
+			e, _ := json.Marshal(googleResp.Error)
+			return string(body), string(e), nil, usage, errs.Newf(errs.Internal, nil, "%s", googleResp.Error.Message)
 		}
 
 		m := "google api returned no candidates and no error details"
-		return string(body), m, nil, errors.New(m)
+		return string(body), m, nil, usage, errors.New(m)
 	}
 
 	// 5. Unmarshal the actual JSON string from the response part into our struct
-	var finalResult AnalysisResponse
-	actualJson := googleResp.Candidates[0].Content.Parts[0].Text
+	actualJson, finalResult, err := parseAnalysis(googleResp.Candidates[0].Content.Parts[0].Text)
+	if err != nil {
+		return string(body), err.Error(), nil, usage, err
+	}
+
+	return string(body), actualJson, finalResult, usage, nil
+}
 
+// parseAnalysis unmarshals the analysis json returned by the model.
+// It returns the cleaned up json along with the parsed result.
+func parseAnalysis(text string) (string, *AnalysisResponse, error) {
 	// Clean up markdown markers if present
-	actualJson = strings.ReplaceAll(actualJson, "```json", "")
+	actualJson := strings.ReplaceAll(text, "```json", "")
 	actualJson = strings.ReplaceAll(actualJson, "```", "")
 	actualJson = strings.TrimSpace(actualJson)
 
+	var finalResult AnalysisResponse
 	if err := json.Unmarshal([]byte(actualJson), &finalResult); err != nil {
-		m := errors.Wrap(err, "failed to unmarshal analysis result")
-		return string(body), m.Error(), nil, m
+		return actualJson, nil, errors.Wrap(err, "failed to unmarshal analysis result")
 	}
 
-	return string(body), actualJson, &finalResult, nil
+	return actualJson, &finalResult, nil
 }
 
 func (c *client) doPost(endpoint string, body []byte, headers map[string]string) (*http.Response, error) {
 	url := fmt.Sprintf("%s%s", c.BaseUrl, endpoint)
+	return c.doRequest(http.MethodPost, url, bytes.NewReader(body), headers)
+}
 
-	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+func (c *client) doRequest(method, url string, body io.Reader, headers map[string]string) (*http.Response, error) {
+	req, err := http.NewRequest(method, url, body)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create request")
 	}
@@ -416,7 +462,7 @@ func (c *client) doPost(endpoint string, body []byte, headers map[string]string)
 	req.Header.Set("x-goog-api-key", c.Token)
 
 	// Only set Content-Type if not already provided in headers
-	if headers == nil || headers["Content-Type"] == "" {
+	if body != nil && (headers == nil || headers["Content-Type"] == "") {
 		req.Header.Set("Content-Type", "application/json")
 	}
 
@@ -0,0 +1,269 @@
+package gemini
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/amahdian/cliplab-be/domain/model"
+)
+
+// fakeGemini is a minimal stand-in for the Files API and generateContent endpoints.
+type fakeGemini struct {
+	t      *testing.T
+	server *httptest.Server
+
+	mu            sync.Mutex
+	uploaded      []byte
+	getCalls      int
+	readyAfter    int // number of GetFile calls before the file becomes ACTIVE
+	failFile      bool
+	deleted       bool
+	promptFileUri string
+	// responseSchema is the response schema of the last analysis request.
+	responseSchema map[string]interface{}
+}
+
+func newFakeGemini(t *testing.T) *fakeGemini {
+	f := &fakeGemini{t: t, readyAfter: 2}
+	f.server = httptest.NewServer(http.HandlerFunc(f.handle))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeGemini) file(state FileState) map[string]interface{} {
+	file := map[string]interface{}{
+		"name":     "files/abc123",
+		"mimeType": "video/mp4",
+		"uri":      f.server.URL + "/v1beta/files/abc123",
+		"state":    state,
+	}
+	if state == FileStateFailed {
+		file["error"] = map[string]interface{}{"code": 400, "message": "unsupported codec"}
+	}
+	return file
+}
+
+func (f *fakeGemini) handle(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Header.Get("x-goog-api-key") != "test-token" && r.URL.Path != "/upload/session/1" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/upload/v1beta/files":
+		if r.Header.Get("X-Goog-Upload-Command") != "start" || r.Header.Get("X-Goog-Upload-Protocol") != "resumable" {
+			f.t.Errorf("unexpected upload start headers: %v", r.Header)
+		}
+		if r.Header.Get("X-Goog-Upload-Header-Content-Type") != "video/mp4" {
+			f.t.Errorf("unexpected upload content type: %s", r.Header.Get("X-Goog-Upload-Header-Content-Type"))
+		}
+		w.Header().Set("X-Goog-Upload-URL", f.server.URL+"/upload/session/1")
+		w.WriteHeader(http.StatusOK)
+
+	case r.Method == http.MethodPost && r.URL.Path == "/upload/session/1":
+		if r.Header.Get("X-Goog-Upload-Command") != "upload, finalize" {
+			f.t.Errorf("unexpected upload command: %s", r.Header.Get("X-Goog-Upload-Command"))
+		}
+		f.uploaded, _ = io.ReadAll(r.Body)
+		writeJson(w, map[string]interface{}{"file": f.file(FileStateProcessing)})
+
+	case r.Method == http.MethodGet && r.URL.Path == "/v1beta/files/abc123":
+		f.getCalls++
+		state := FileStateProcessing
+		if f.getCalls >= f.readyAfter {
+			state = FileStateActive
+			if f.failFile {
+				state = FileStateFailed
+			}
+		}
+		writeJson(w, f.file(state))
+
+	case r.Method == http.MethodDelete && r.URL.Path == "/v1beta/files/abc123":
+		f.deleted = true
+		writeJson(w, map[string]interface{}{})
+
+	case r.Method == http.MethodPost && r.URL.Path == "/v1beta/models/"+AnalysisModel+":generateContent":
+		var body struct {
+			Contents []struct {
+				Parts []struct {
+					FileData *struct {
+						FileUri string `json:"file_uri"`
+					} `json:"file_data"`
+				} `json:"parts"`
+			} `json:"contents"`
+			GenerationConfig struct {
+				ResponseSchema map[string]interface{} `json:"response_schema"`
+			} `json:"generation_config"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if fd := body.Contents[0].Parts[0].FileData; fd != nil {
+			f.promptFileUri = fd.FileUri
+		}
+		f.responseSchema = body.GenerationConfig.ResponseSchema
+		writeJson(w, map[string]interface{}{
+			"candidates": []map[string]interface{}{
+				{
+					"content": map[string]interface{}{
+						"parts": []map[string]interface{}{
+							{"text": "```json\n{\"summary\": {\"big_idea\": \"cats are great\", \"sentiment_score\": 80}}\n```"},
+						},
+					},
+					"groundingMetadata": map[string]interface{}{
+						"webSearchQueries": []string{"cat trends", "cat memes"},
+					},
+				},
+			},
+			"usageMetadata": map[string]interface{}{
+				"promptTokenCount":        1200,
+				"candidatesTokenCount":    300,
+				"thoughtsTokenCount":      50,
+				"toolUsePromptTokenCount": 100,
+				"totalTokenCount":         1650,
+				"promptTokensDetails": []map[string]interface{}{
+					{"modality": "TEXT", "tokenCount": 200},
+					{"modality": "VIDEO", "tokenCount": 800},
+					{"modality": "AUDIO", "tokenCount": 200},
+				},
+			},
+		})
+
+	default:
+		f.t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func writeJson(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func newTestClient(baseUrl string) *client {
+	c := NewClient(baseUrl, "test-token").(*client)
+	c.FilePollInterval = time.Millisecond
+	return c
+}
+
+func writeTempVideo(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "reel.mp4")
+	if err := os.WriteFile(path, []byte("fake video bytes"), 0o600); err != nil {
+		t.Fatalf("failed to write temp video: %v", err)
+	}
+	return path
+}
+
+func TestUploadAnalyzeAndDeleteFile(t *testing.T) {
+	fake := newFakeGemini(t)
+	c := newTestClient(fake.server.URL)
+
+	file, err := c.UploadFile(writeTempVideo(t), "video/mp4")
+	if err != nil {
+		t.Fatalf("UploadFile returned error: %v", err)
+	}
+	if string(fake.uploaded) != "fake video bytes" {
+		t.Errorf("unexpected uploaded content: %q", fake.uploaded)
+	}
+	if file.State != FileStateProcessing {
+		t.Errorf("expected uploaded file to be processing, got %s", file.State)
+	}
+
+	active, err := c.WaitForFileActive(file.Name, time.Second)
+	if err != nil {
+		t.Fatalf("WaitForFileActive returned error: %v", err)
+	}
+	if active.State != FileStateActive || fake.getCalls != 2 {
+		t.Errorf("expected file to be active after 2 polls, got %s after %d", active.State, fake.getCalls)
+	}
+
+	_, _, result, usage, err := c.AnalyzeVideo(model.PlatformInstagram, active.Uri, "caption", nil, nil, nil, nil, time.Now(), "en")
+	if err != nil {
+		t.Fatalf("AnalyzeVideo returned error: %v", err)
+	}
+	if fake.promptFileUri != active.Uri {
+		t.Errorf("expected prompt to reference %s, got %s", active.Uri, fake.promptFileUri)
+	}
+	if result.Summary.BigIdea != "cats are great" {
+		t.Errorf("unexpected analysis result: %+v", result.Summary)
+	}
+
+	want := Usage{
+		Model:         AnalysisModel,
+		PromptTokens:  300,
+		VideoTokens:   800,
+		AudioTokens:   200,
+		OutputTokens:  350,
+		TotalTokens:   1650,
+		SearchQueries: 2,
+	}
+	if usage == nil || *usage != want {
+		t.Errorf("unexpected usage: got %+v, want %+v", usage, want)
+	}
+
+	if err := c.DeleteFile(active.Name); err != nil {
+		t.Fatalf("DeleteFile returned error: %v", err)
+	}
+	if !fake.deleted {
+		t.Error("expected file to be deleted")
+	}
+}
+
+func TestWaitForFileActiveFailure(t *testing.T) {
+	fake := newFakeGemini(t)
+	fake.failFile = true
+	c := newTestClient(fake.server.URL)
+
+	_, err := c.WaitForFileActive("files/abc123", time.Second)
+	if err == nil || !strings.Contains(err.Error(), "unsupported codec") {
+		t.Fatalf("expected processing failure, got %v", err)
+	}
+}
+
+func TestWaitForFileActiveTimeout(t *testing.T) {
+	fake := newFakeGemini(t)
+	fake.readyAfter = 1000
+	c := newTestClient(fake.server.URL)
+
+	_, err := c.WaitForFileActive("files/abc123", 20*time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "not active") {
+		t.Fatalf("expected timeout error, got %v", err)
+	}
+}
+
+func TestAnalysisSchemaDeclaresScope(t *testing.T) {
+	fake := newFakeGemini(t)
+	c := newTestClient(fake.server.URL)
+
+	_, _, _, _, err := c.AnalyzeVideo(model.PlatformInstagram, "", "caption", nil, nil, nil, nil, time.Now(), "en")
+	if err != nil {
+		t.Fatalf("AnalyzeVideo returned error: %v", err)
+	}
+
+	properties := func(schema interface{}, path string) map[string]interface{} {
+		props, _ := schema.(map[string]interface{})["properties"].(map[string]interface{})
+		if props == nil {
+			t.Fatalf("schema of %s has no properties", path)
+		}
+		return props
+	}
+	analysis := properties(properties(fake.responseSchema, "response")["analysis"], "analysis")
+	scope, ok := analysis["scope"]
+	if !ok {
+		t.Fatalf("analysis schema misses scope: %v", analysis)
+	}
+	for _, field := range []string{"level", "confidence"} {
+		if _, ok = properties(scope, "scope")[field]; !ok {
+			t.Errorf("scope schema misses %s", field)
+		}
+	}
+}
@@ -28,6 +28,7 @@ type AnalysisResponse struct {
 			Confidence int    `json:"confidence"`
 		} `json:"scope"`
 		Metrics []struct {
+			Key         string `json:"key"`
 			Label       string `json:"label"`
 			Score       int    `json:"score"`
 			Explanation string `json:"explanation"`
@@ -49,3 +50,98 @@ type AnalysisResponse struct {
 		Hashtags []string `json:"hashtags"`
 	} `json:"publish"`
 }
+
+// Usage summarizes the tokens billed for a single generateContent call.
+type Usage struct {
+	Model         string
+	PromptTokens  int // text/image prompt tokens, including tool-use prompts
+	VideoTokens   int
+	AudioTokens   int
+	OutputTokens  int // response tokens, including thinking tokens
+	TotalTokens   int
+	SearchQueries int // google search queries issued for grounding
+}
+
+type generateContentResponse struct {
+	Candidates []struct {
+		Content struct {
+			Parts []struct {
+				Text string `json:"text"`
+			} `json:"parts"`
+		} `json:"content"`
+		GroundingMetadata *struct {
+			WebSearchQueries []string `json:"webSearchQueries"`
+		} `json:"groundingMetadata,omitempty"`
+	} `json:"candidates"`
+	UsageMetadata *usageMetadata `json:"usageMetadata,omitempty"`
+	Error         *apiError      `json:"error,omitempty"`
+}
+
+type usageMetadata struct {
+	PromptTokenCount        int `json:"promptTokenCount"`
+	CandidatesTokenCount    int `json:"candidatesTokenCount"`
+	ThoughtsTokenCount      int `json:"thoughtsTokenCount"`
+	ToolUsePromptTokenCount int `json:"toolUsePromptTokenCount"`
+	TotalTokenCount         int `json:"totalTokenCount"`
+	PromptTokensDetails     []struct {
+		Modality   string `json:"modality"`
+		TokenCount int    `json:"tokenCount"`
+	} `json:"promptTokensDetails"`
+}
+
+type apiError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Status  string `json:"status"`
+}
+
+// usage converts the raw usage metadata of the response into a Usage.
+// It returns nil when the response carries no usage information.
+func (r *generateContentResponse) usage(model string) *Usage {
+	if r.UsageMetadata == nil {
+		return nil
+	}
+
+	m := r.UsageMetadata
+	u := &Usage{
+		Model:        model,
+		OutputTokens: m.CandidatesTokenCount + m.ThoughtsTokenCount,
+		TotalTokens:  m.TotalTokenCount,
+	}
+	for _, d := range m.PromptTokensDetails {
+		switch d.Modality {
+		case "VIDEO":
+			u.VideoTokens += d.TokenCount
+		case "AUDIO":
+			u.AudioTokens += d.TokenCount
+		}
+	}
+	u.PromptTokens = m.PromptTokenCount - u.VideoTokens - u.AudioTokens + m.ToolUsePromptTokenCount
+
+	for _, c := range r.Candidates {
+		if c.GroundingMetadata != nil {
+			u.SearchQueries += len(c.GroundingMetadata.WebSearchQueries)
+		}
+	}
+
+	return u
+}
+
+type FileState string
+
+const (
+	FileStateProcessing FileState = "PROCESSING"
+	FileStateActive     FileState = "ACTIVE"
+	FileStateFailed     FileState = "FAILED"
+)
+
+// File is a media file uploaded to the Gemini Files API.
+type File struct {
+	Name        string    `json:"name"`
+	DisplayName string    `json:"displayName"`
+	MimeType    string    `json:"mimeType"`
+	SizeBytes   string    `json:"sizeBytes"`
+	Uri         string    `json:"uri"`
+	State       FileState `json:"state"`
+	Error       *apiError `json:"error,omitempty"`
+}
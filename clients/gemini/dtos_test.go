@@ -0,0 +1,48 @@
+package gemini
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestUsage(t *testing.T) {
+	var r generateContentResponse
+	if r.usage(AnalysisModel) != nil {
+		t.Errorf("expected no usage without usage metadata")
+	}
+
+	err := json.Unmarshal([]byte(`{
+		"candidates": [
+			{"groundingMetadata": {"webSearchQueries": ["cats", "dogs"]}},
+			{"groundingMetadata": {"webSearchQueries": ["birds"]}}
+		],
+		"usageMetadata": {
+			"promptTokenCount": 1000,
+			"candidatesTokenCount": 200,
+			"thoughtsTokenCount": 50,
+			"toolUsePromptTokenCount": 30,
+			"totalTokenCount": 1280,
+			"promptTokensDetails": [
+				{"modality": "TEXT", "tokenCount": 100},
+				{"modality": "VIDEO", "tokenCount": 700},
+				{"modality": "AUDIO", "tokenCount": 200}
+			]
+		}
+	}`), &r)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	want := Usage{
+		Model:         AnalysisModel,
+		PromptTokens:  130, // text and tool-use prompts
+		VideoTokens:   700,
+		AudioTokens:   200,
+		OutputTokens:  250, // candidates and thoughts
+		TotalTokens:   1280,
+		SearchQueries: 3,
+	}
+	if got := r.usage(AnalysisModel); got == nil || *got != want {
+		t.Errorf("unexpected usage: got %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,164 @@
+package gemini
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/amahdian/cliplab-be/global/errs"
+	"github.com/pkg/errors"
+)
+
+// UploadFile uploads a local file to the Gemini Files API using the resumable upload protocol.
+// The returned file is usually still being processed, see WaitForFileActive.
+func (c *client) UploadFile(filePath, mimeType string) (*File, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open file")
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to stat file")
+	}
+	size := strconv.FormatInt(info.Size(), 10)
+
+	// 1. Start a resumable upload session
+	startBody, _ := json.Marshal(map[string]interface{}{
+		"file": map[string]string{"display_name": filepath.Base(filePath)},
+	})
+	startResp, err := c.doPost("/upload/v1beta/files", startBody, map[string]string{
+		"X-Goog-Upload-Protocol":              "resumable",
+		"X-Goog-Upload-Command":               "start",
+		"X-Goog-Upload-Header-Content-Length": size,
+		"X-Goog-Upload-Header-Content-Type":   mimeType,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer startResp.Body.Close()
+
+	if startResp.StatusCode != http.StatusOK {
+		return nil, readApiError(startResp, "failed to start file upload")
+	}
+
+	uploadUrl := startResp.Header.Get("X-Goog-Upload-URL")
+	if uploadUrl == "" {
+		return nil, errors.New("google api did not return an upload url")
+	}
+
+	// 2. Upload the bytes and finalize the upload in a single request
+	req, err := http.NewRequest(http.MethodPost, uploadUrl, f)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create request")
+	}
+	req.ContentLength = info.Size()
+	req.Header.Set("X-Goog-Upload-Offset", "0")
+	req.Header.Set("X-Goog-Upload-Command", "upload, finalize")
+
+	uploadResp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to upload file")
+	}
+	defer uploadResp.Body.Close()
+
+	if uploadResp.StatusCode != http.StatusOK {
+		return nil, readApiError(uploadResp, "failed to upload file")
+	}
+
+	var uploaded struct {
+		File *File `json:"file"`
+	}
+	if err := json.NewDecoder(uploadResp.Body).Decode(&uploaded); err != nil {
+		return nil, errors.Wrap(err, "failed to decode upload response")
+	}
+	if uploaded.File == nil {
+		return nil, errors.New("google api returned no file for the upload")
+	}
+
+	return uploaded.File, nil
+}
+
+// GetFile returns the metadata of an uploaded file. The name has the form "files/{id}".
+func (c *client) GetFile(name string) (*File, error) {
+	url := fmt.Sprintf("%s/v1beta/%s", c.BaseUrl, name)
+	resp, err := c.doRequest(http.MethodGet, url, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, readApiError(resp, "failed to get file")
+	}
+
+	var file File
+	if err := json.NewDecoder(resp.Body).Decode(&file); err != nil {
+		return nil, errors.Wrap(err, "failed to decode file")
+	}
+
+	return &file, nil
+}
+
+// WaitForFileActive polls the file until it is ACTIVE and can be referenced in a prompt.
+func (c *client) WaitForFileActive(name string, timeout time.Duration) (*File, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		file, err := c.GetFile(name)
+		if err != nil {
+			return nil, err
+		}
+
+		switch file.State {
+		case FileStateActive:
+			return file, nil
+		case FileStateFailed:
+			reason := "unknown reason"
+			if file.Error != nil {
+				reason = file.Error.Message
+			}
+			return nil, errs.Newf(errs.Internal, nil, "processing of file %s failed: %s", name, reason)
+		}
+
+		if time.Now().After(deadline) {
+			return nil, errs.Newf(errs.DeadlineExceeded, nil, "file %s is not active after %s", name, timeout)
+		}
+		time.Sleep(c.FilePollInterval)
+	}
+}
+
+// DeleteFile removes an uploaded file. Files expire on their own after 48 hours,
+// but we delete them as soon as we are done with them.
+func (c *client) DeleteFile(name string) error {
+	url := fmt.Sprintf("%s/v1beta/%s", c.BaseUrl, name)
+	resp, err := c.doRequest(http.MethodDelete, url, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return readApiError(resp, "failed to delete file")
+	}
+
+	return nil
+}
+
+func readApiError(resp *http.Response, msg string) error {
+	body, _ := io.ReadAll(resp.Body)
+
+	var apiErr struct {
+		Error *apiError `json:"error"`
+	}
+	if err := json.Unmarshal(body, &apiErr); err == nil && apiErr.Error != nil {
+		return errs.Newf(errs.Internal, nil, "%s: %s", msg, apiErr.Error.Message)
+	}
+
+	return errs.Newf(errs.Internal, nil, "%s with status %d: %s", msg, resp.StatusCode, string(body))
+}
@@ -0,0 +1,221 @@
+package gemini
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/amahdian/cliplab-be/global/errs"
+	"github.com/pkg/errors"
+)
+
+// Top level sections of the analysis response, in the order the model generates them.
+const (
+	SectionSummary  = "summary"
+	SectionContent  = "content"
+	SectionAnalysis = "analysis"
+	SectionRemix    = "remix"
+	SectionPublish  = "publish"
+)
+
+// SectionHandler is called for every top level section of the analysis as soon as it is complete.
+type SectionHandler func(section string, raw json.RawMessage)
+
+// maxStreamEventSize bounds a single server-sent event of the stream.
+const maxStreamEventSize = 10 * 1024 * 1024
+
+// AnalyzeVideoStream works like AnalyzeVideo but uses streamGenerateContent,
+// calling onSection for each top level section of the response as soon as it is generated.
+func (c *client) AnalyzeVideoStream(
+	platform model.SocialPlatform,
+	videoURL string,
+	caption string,
+	coauthors []string,
+	comments []string,
+	stats map[string]float64,
+	averageStats map[string]float64,
+	publishedAt time.Time,
+	targetRegion string,
+	onSection SectionHandler,
+) (string, string, *AnalysisResponse, *Usage, error) {
+	body, err := analysisRequestBody(platform, videoURL, caption, coauthors, comments, stats, averageStats, publishedAt, targetRegion)
+	if err != nil {
+		return "", "", nil, nil, err
+	}
+
+	endpoint := fmt.Sprintf("/v1beta/models/%s:streamGenerateContent?alt=sse", AnalysisModel)
+	resp, err := c.doPost(endpoint, body, nil)
+	if err != nil {
+		return string(body), "", nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		err = readApiError(resp, "failed to stream analysis")
+		return string(body), err.Error(), nil, nil, err
+	}
+
+	// chunks only carry the newly generated text, usage and grounding come with the last ones
+	var text strings.Builder
+	var final generateContentResponse
+	parser := newSectionParser(onSection)
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStreamEventSize)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if !bytes.HasPrefix(line, []byte("data:")) {
+			continue
+		}
+
+		var chunk generateContentResponse
+		if err := json.Unmarshal(bytes.TrimSpace(line[len("data:"):]), &chunk); err != nil {
+			return string(body), text.String(), nil, final.usage(AnalysisModel), errors.Wrap(err, "failed to decode google stream chunk")
+		}
+		if chunk.Error != nil && chunk.Error.Code != 0 {
+			e, _ := json.Marshal(chunk.Error)
+			return string(body), string(e), nil, final.usage(AnalysisModel), errs.Newf(errs.Internal, nil, "%s", chunk.Error.Message)
+		}
+
+		if chunk.UsageMetadata != nil {
+			final.UsageMetadata = chunk.UsageMetadata
+		}
+		for _, candidate := range chunk.Candidates {
+			if candidate.GroundingMetadata != nil {
+				final.Candidates = append(final.Candidates, candidate)
+			}
+			for _, part := range candidate.Content.Parts {
+				text.WriteString(part.Text)
+				parser.Write(part.Text)
+			}
+		}
+	}
+
+	usage := final.usage(AnalysisModel)
+	if err := scanner.Err(); err != nil {
+		return string(body), text.String(), nil, usage, errors.Wrap(err, "failed to read google stream")
+	}
+	if text.Len() == 0 {
+		m := "google api returned no candidates and no error details"
+		return string(body), m, nil, usage, errors.New(m)
+	}
+
+	actualJson, result, err := parseAnalysis(text.String())
+	if err != nil {
+		return string(body), err.Error(), nil, usage, err
+	}
+
+	return string(body), actualJson, result, usage, nil
+}
+
+// sectionParser incrementally scans a streamed json object and reports
+// each top level member once its value is complete.
+// Anything before the opening brace (e.g. markdown fences) is ignored.
+type sectionParser struct {
+	onSection SectionHandler
+
+	buf      []byte
+	depth    int
+	inString bool
+	escaped  bool
+	done     bool
+
+	readingKey bool
+	keyStart   int
+	key        string
+	valueStart int
+}
+
+func newSectionParser(onSection SectionHandler) *sectionParser {
+	return &sectionParser{onSection: onSection, keyStart: -1, valueStart: -1}
+}
+
+func (p *sectionParser) Write(chunk string) {
+	for i := 0; i < len(chunk); i++ {
+		if p.done {
+			return
+		}
+		p.buf = append(p.buf, chunk[i])
+		p.scan(len(p.buf) - 1)
+	}
+}
+
+func (p *sectionParser) scan(i int) {
+	c := p.buf[i]
+
+	if p.depth == 0 {
+		if c == '{' {
+			p.depth = 1
+		}
+		return
+	}
+
+	if p.inString {
+		switch {
+		case p.escaped:
+			p.escaped = false
+		case c == '\\':
+			p.escaped = true
+		case c == '"':
+			p.inString = false
+			if p.readingKey {
+				p.readingKey = false
+				_ = json.Unmarshal(p.buf[p.keyStart:i+1], &p.key)
+			}
+		}
+		return
+	}
+
+	switch c {
+	case '"':
+		p.inString = true
+		if p.depth == 1 && p.key == "" && !p.readingKey {
+			p.readingKey = true
+			p.keyStart = i
+		} else if p.depth == 1 && p.valueStart < 0 {
+			p.valueStart = i
+		}
+	case '{', '[':
+		if p.depth == 1 && p.valueStart < 0 {
+			p.valueStart = i
+		}
+		p.depth++
+	case '}', ']':
+		p.depth--
+		switch p.depth {
+		case 1:
+			p.emit(i + 1)
+		case 0:
+			// a primitive last member is only terminated by the closing brace
+			p.emit(i)
+			p.done = true
+		}
+	case ',':
+		if p.depth == 1 {
+			p.emit(i)
+		}
+	case ':', ' ', '\t', '\r', '\n':
+	default:
+		if p.depth == 1 && p.key != "" && p.valueStart < 0 {
+			p.valueStart = i
+		}
+	}
+}
+
+// emit reports the current member if it has a value ending before end.
+func (p *sectionParser) emit(end int) {
+	if p.key != "" && p.valueStart >= 0 {
+		raw := bytes.TrimSpace(p.buf[p.valueStart:end])
+		if len(raw) > 0 && p.onSection != nil {
+			p.onSection(p.key, append(json.RawMessage(nil), raw...))
+		}
+	}
+	p.key = ""
+	p.keyStart = -1
+	p.valueStart = -1
+}
@@ -0,0 +1,118 @@
+package gemini
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/amahdian/cliplab-be/domain/model"
+)
+
+const streamedAnalysis = "```json\n" + `{
+  "summary": {"big_idea": "braces { inside } strings", "sentiment_score": 72, "verdict": {"status": "GREEN LIGHT", "reasoning": "quoted \"text\", with commas"}},
+  "content": {"hook": "cold open", "segments": [{"speaker": "Creator", "timestamp": "[00:01]", "content": "hi", "emotion": "happy"}]},
+  "analysis": {"scope": {"level": "Global", "confidence": 80}, "metrics": [], "strengths": ["a"], "weaknesses": []},
+  "remix": {"hook_ideas": ["h1"], "script_ideas": ["s1"]},
+  "publish": {"captions": {"casual": "c", "professional": "p", "viral": "v"}, "hashtags": ["#cats"]}
+}` + "\n```"
+
+func TestSectionParser(t *testing.T) {
+	for _, chunkSize := range []int{1, 7, 64, len(streamedAnalysis)} {
+		t.Run(fmt.Sprintf("chunk size %d", chunkSize), func(t *testing.T) {
+			var sections []string
+			raws := map[string]json.RawMessage{}
+			parser := newSectionParser(func(section string, raw json.RawMessage) {
+				sections = append(sections, section)
+				raws[section] = raw
+			})
+
+			for i := 0; i < len(streamedAnalysis); i += chunkSize {
+				parser.Write(streamedAnalysis[i:min(i+chunkSize, len(streamedAnalysis))])
+			}
+
+			want := []string{SectionSummary, SectionContent, SectionAnalysis, SectionRemix, SectionPublish}
+			if fmt.Sprint(sections) != fmt.Sprint(want) {
+				t.Fatalf("unexpected sections: got %v, want %v", sections, want)
+			}
+			for section, raw := range raws {
+				if !json.Valid(raw) {
+					t.Errorf("section %s is not valid json: %s", section, raw)
+				}
+			}
+
+			var summary struct {
+				BigIdea string `json:"big_idea"`
+			}
+			_ = json.Unmarshal(raws[SectionSummary], &summary)
+			if summary.BigIdea != "braces { inside } strings" {
+				t.Errorf("unexpected summary: %s", raws[SectionSummary])
+			}
+		})
+	}
+}
+
+func TestSectionParserPrimitiveMembers(t *testing.T) {
+	got := map[string]string{}
+	parser := newSectionParser(func(section string, raw json.RawMessage) {
+		got[section] = string(raw)
+	})
+	parser.Write(`{"a": 1, "b": "x,y", "c": true}`)
+
+	want := map[string]string{"a": "1", "b": `"x,y"`, "c": "true"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("unexpected members: got %v, want %v", got, want)
+	}
+}
+
+func TestAnalyzeVideoStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1beta/models/"+AnalysisModel+":streamGenerateContent" || r.URL.Query().Get("alt") != "sse" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+
+		text := streamedAnalysis
+		for i := 0; i < len(text); i += 100 {
+			chunk := map[string]interface{}{
+				"candidates": []map[string]interface{}{
+					{"content": map[string]interface{}{"parts": []map[string]string{{"text": text[i:min(i+100, len(text))]}}}},
+				},
+			}
+			if i+100 >= len(text) {
+				chunk["candidates"].([]map[string]interface{})[0]["groundingMetadata"] = map[string]interface{}{
+					"webSearchQueries": []string{"cat trends"},
+				}
+				chunk["usageMetadata"] = map[string]interface{}{"promptTokenCount": 100, "candidatesTokenCount": 40, "totalTokenCount": 140}
+			}
+			data, _ := json.Marshal(chunk)
+			_, _ = fmt.Fprintf(w, "data: %s\r\n\r\n", data)
+			w.(http.Flusher).Flush()
+		}
+	}))
+	defer server.Close()
+
+	c := newTestClient(server.URL)
+
+	var sections []string
+	_, _, result, usage, err := c.AnalyzeVideoStream(model.PlatformInstagram, "files/abc", "caption", nil, nil, nil, nil, time.Now(), "en",
+		func(section string, raw json.RawMessage) {
+			sections = append(sections, section)
+		})
+	if err != nil {
+		t.Fatalf("AnalyzeVideoStream returned error: %v", err)
+	}
+	if len(sections) != 5 {
+		t.Errorf("expected 5 sections, got %v", sections)
+	}
+	if result.Publish.Hashtags[0] != "#cats" {
+		t.Errorf("unexpected result: %+v", result.Publish)
+	}
+
+	want := Usage{Model: AnalysisModel, PromptTokens: 100, OutputTokens: 40, TotalTokens: 140, SearchQueries: 1}
+	if usage == nil || *usage != want {
+		t.Errorf("unexpected usage: got %+v, want %+v", usage, want)
+	}
+}
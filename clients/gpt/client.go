@@ -20,8 +20,12 @@ type Client interface {
 	EmbedText(text string) ([]float32, error)
 	TranscribeAudio(fileData []byte, fileName string) (*TranscriptionResult, error)
 	ClassifyPost(postText string, categories []string) ([]string, error)
+	ChatCompletion(request ChatRequest) (string, error)
 }
 
+// ChatModel is the model used for chat completions.
+const ChatModel = "gpt-5-mini"
+
 type client struct {
 	BaseUrl    string
 	Token      string
@@ -120,6 +124,7 @@ func (c *client) TranscribeAudio(fileData []byte, fileName string) (*Transcripti
 
 	// 5. Add response_format to get verbose JSON with language info
 	_ = writer.WriteField("response_format", "verbose_json")
+	_ = writer.WriteField("timestamp_granularities[]", "segment")
 
 	_ = writer.WriteField("prompt",
 		"The audio may contain speech, music, or singing. "+
@@ -159,6 +164,7 @@ func (c *client) TranscribeAudio(fileData []byte, fileName string) (*Transcripti
 	return &TranscriptionResult{
 		Text:     transcriptionResp.Text,
 		Language: transcriptionResp.Language,
+		Segments: transcriptionResp.Segments,
 	}, nil
 }
 
@@ -184,52 +190,63 @@ Return format:
 MainCategory/Subcategory
 MainCategory2/Subcategory/Subcategory`, postText, strings.Join(categories, "\n"))
 
-	body, err := json.Marshal(ChatRequest{
-		Model: "gpt-5-mini",
+	content, err := c.ChatCompletion(ChatRequest{
+		Model: ChatModel,
 		Messages: []ChatMessage{
 			{Role: "system", Content: systemPrompt},
 			{Role: "user", Content: userPrompt},
 		},
 	})
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to marshal request body")
+		return nil, err
+	}
+
+	// Split by newline and trim
+	lines := strings.Split(content, "\n")
+	var cleaned []string
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			cleaned = append(cleaned, line)
+		}
+	}
+
+	return cleaned, nil
+}
+
+// ChatCompletion returns the reply of the model to the conversation of the request.
+func (c *client) ChatCompletion(request ChatRequest) (string, error) {
+	request.Stream = false
+	body, err := json.Marshal(request)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal request body")
 	}
 
-	resp, err := c.doPost("/v1/chat/completions", body, nil)
+	resp, err := c.doPost("/chat/completions", body, nil)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 	defer resp.Body.Close()
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to read response")
+		return "", errors.Wrap(err, "failed to read response")
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(respBody))
+		return "", fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(respBody))
 	}
 
 	var result ChatResponse
 	if err := json.Unmarshal(respBody, &result); err != nil {
-		return nil, errors.Wrap(err, "failed to parse response")
+		return "", errors.Wrap(err, "failed to parse response")
 	}
 
 	if len(result.Choices) == 0 {
-		return nil, fmt.Errorf("no choices in GPT response")
-	}
-
-	// Split by newline and trim
-	lines := strings.Split(result.Choices[0].Message.Content, "\n")
-	var cleaned []string
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line != "" {
-			cleaned = append(cleaned, line)
-		}
+		return "", fmt.Errorf("no choices in GPT response")
 	}
 
-	return cleaned, nil
+	return result.Choices[0].Message.Content, nil
 }
 
 func (c *client) doPost(endpoint string, body []byte, headers map[string]string) (*http.Response, error) {
@@ -70,3 +70,70 @@ Technology/Automation`))
 		}
 	}
 }
+
+func TestTranscribeAudio(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/audio/transcriptions" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatalf("failed to parse form: %v", err)
+		}
+		if r.FormValue("response_format") != "verbose_json" || r.FormValue("timestamp_granularities[]") != "segment" {
+			t.Errorf("unexpected form: %v", r.MultipartForm.Value)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"text": "hello there. general kenobi", "language": "english", "duration": 4.2,
+			"segments": [{"id": 0, "start": 0.0, "end": 1.8, "text": " hello there."}, {"id": 1, "start": 65.4, "end": 68.0, "text": " general kenobi"}]}`)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "token")
+	result, err := client.TranscribeAudio([]byte("audio"), "reel.mp4")
+	if err != nil {
+		t.Fatalf("TranscribeAudio returned error: %v", err)
+	}
+
+	if len(result.Segments) != 2 {
+		t.Fatalf("expected 2 segments, got %d", len(result.Segments))
+	}
+	if result.Segments[1].Start != 65.4 || result.Segments[1].Text != " general kenobi" {
+		t.Errorf("unexpected segment: %+v", result.Segments[1])
+	}
+}
+
+func TestStreamChatCompletions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body ChatRequest
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if !body.Stream {
+			t.Errorf("expected a streamed request")
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, token := range []string{"The hook ", "lands in ", "2 seconds."} {
+			chunk, _ := json.Marshal(map[string]interface{}{
+				"choices": []map[string]interface{}{{"delta": map[string]string{"content": token}}},
+			})
+			io.WriteString(w, "data: "+string(chunk)+"\n\n")
+		}
+		io.WriteString(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "token")
+	body, _ := json.Marshal(ChatRequest{Model: ChatModel, Messages: []ChatMessage{{Role: "user", Content: "hook?"}}, Stream: true})
+	tokens, err := client.StreamChatCompletions(body)
+	if err != nil {
+		t.Fatalf("StreamChatCompletions returned error: %v", err)
+	}
+
+	var answer strings.Builder
+	for token := range tokens {
+		answer.WriteString(token)
+	}
+	if answer.String() != "The hook lands in 2 seconds." {
+		t.Errorf("unexpected answer: %q", answer.String())
+	}
+}
@@ -31,18 +31,36 @@ type EmbeddingResponse struct {
 }
 
 type TranscriptionResponse struct {
-	Text     string `json:"text"`
-	Language string `json:"language,omitempty"`
+	Text     string                 `json:"text"`
+	Language string                 `json:"language,omitempty"`
+	Duration float64                `json:"duration,omitempty"`
+	Segments []TranscriptionSegment `json:"segments,omitempty"`
+}
+
+// TranscriptionSegment is a part of the transcription, Start and End are in seconds.
+type TranscriptionSegment struct {
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+	Text  string  `json:"text"`
 }
 
 type TranscriptionResult struct {
 	Text     string
 	Language string
+	Segments []TranscriptionSegment
 }
 
 type ChatRequest struct {
 	Model    string        `json:"model"`
 	Messages []ChatMessage `json:"messages"`
+	Stream   bool          `json:"stream,omitempty"`
+
+	ResponseFormat *ResponseFormat `json:"response_format,omitempty"`
+}
+
+// ResponseFormat constrains the reply, e.g. {"type": "json_object"}.
+type ResponseFormat struct {
+	Type string `json:"type"`
 }
 
 type ChatMessage struct {
@@ -0,0 +1,46 @@
+package mailer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/amahdian/cliplab-be/pkg/logger"
+	"github.com/pkg/errors"
+)
+
+// unsafeFileNameChars matches the characters of a recipient not kept in the file name of its email.
+var unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9@._+-]`)
+
+type logMailer struct {
+	dir  string
+	from string
+}
+
+// NewLogMailer logs the emails instead of sending them, for local development and tests.
+// When dir is set every email is also written to it as an .eml file.
+func NewLogMailer(dir, from string) Mailer {
+	return &logMailer{
+		dir:  dir,
+		from: from,
+	}
+}
+
+func (m *logMailer) Send(msg Message) error {
+	logger.Infof("Email to %s: %s\n%s", msg.To, msg.Subject, msg.Body)
+	if m.dir == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return errors.Wrap(err, "failed to create the mail directory")
+	}
+	// the recipient comes from the requests, so it must not be able to leave the directory
+	name := filepath.Base(fmt.Sprintf("%d-%s.eml", time.Now().UnixNano(), unsafeFileNameChars.ReplaceAllString(msg.To, "_")))
+	if err := os.WriteFile(filepath.Join(m.dir, name), format(m.from, msg), 0o644); err != nil {
+		return errors.Wrap(err, "failed to write the email")
+	}
+	return nil
+}
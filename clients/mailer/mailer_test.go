@@ -0,0 +1,49 @@
+package mailer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/amahdian/cliplab-be/pkg/logger"
+	"github.com/amahdian/cliplab-be/pkg/logger/logging"
+)
+
+func TestLogMailerWritesEmail(t *testing.T) {
+	logger.Configure(logging.ErrorLevel, logging.TextFormat)
+	dir := t.TempDir()
+	m := NewLogMailer(dir, "no-reply@cliplab.test")
+
+	err := m.Send(Message{To: "user@cliplab.test", Subject: "Your code", Body: "Your code is 123456"})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if len(files) != 1 {
+		t.Fatalf("expected one email, got %d", len(files))
+	}
+	data, _ := os.ReadFile(files[0])
+	email := string(data)
+	for _, want := range []string{"To: user@cliplab.test\r\n", "Subject: Your code\r\n", "\r\n\r\nYour code is 123456"} {
+		if !strings.Contains(email, want) {
+			t.Errorf("email misses %q:\n%s", want, email)
+		}
+	}
+}
+
+func TestLogMailerKeepsEmailsInDir(t *testing.T) {
+	logger.Configure(logging.ErrorLevel, logging.TextFormat)
+	dir := t.TempDir()
+	m := NewLogMailer(filepath.Join(dir, "mail"), "no-reply@cliplab.test")
+
+	if err := m.Send(Message{To: "../../user@cliplab.test", Subject: "Your code", Body: "Your code is 123456"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "mail", "*.eml"))
+	if len(files) != 1 {
+		t.Fatalf("expected one email in the mail directory, got %d", len(files))
+	}
+}
@@ -0,0 +1,35 @@
+package mailer
+
+import (
+	"fmt"
+	"net"
+	"net/smtp"
+
+	"github.com/pkg/errors"
+)
+
+type smtpMailer struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+// NewSmtpMailer sends the emails through an SMTP server, authenticating with PLAIN auth when a username is set.
+func NewSmtpMailer(host string, port int, username, password, from string) Mailer {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return &smtpMailer{
+		addr: net.JoinHostPort(host, fmt.Sprint(port)),
+		auth: auth,
+		from: from,
+	}
+}
+
+func (m *smtpMailer) Send(msg Message) error {
+	if err := smtp.SendMail(m.addr, m.auth, m.from, []string{msg.To}, format(m.from, msg)); err != nil {
+		return errors.Wrapf(err, "failed to send email to %s", msg.To)
+	}
+	return nil
+}
@@ -0,0 +1,245 @@
+package oidc
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pkg/errors"
+)
+
+// jwksRefreshInterval limits how often the keys are fetched again when a token is signed with an unknown key.
+const jwksRefreshInterval = time.Minute
+
+type Client interface {
+	// AuthCodeUrl returns the url the user is sent to for signing in at the identity provider.
+	AuthCodeUrl(state, nonce, codeChallenge string) (string, error)
+	// Exchange trades the authorization code for tokens and returns the verified claims of the id token.
+	Exchange(code, codeVerifier, nonce string) (*IdTokenClaims, error)
+}
+
+type client struct {
+	config     Config
+	HTTPClient *http.Client
+
+	mu          sync.Mutex
+	discovery   *discovery
+	keys        map[string]*rsa.PublicKey
+	keysFetched time.Time
+}
+
+// NewClient returns a client of the authorization code flow with PKCE.
+// The provider metadata is discovered from the issuer on first use.
+func NewClient(config Config) Client {
+	config.Issuer = strings.TrimSuffix(config.Issuer, "/")
+	return &client{
+		config: config,
+		HTTPClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+func (c *client) AuthCodeUrl(state, nonce, codeChallenge string) (string, error) {
+	d, err := c.getDiscovery()
+	if err != nil {
+		return "", err
+	}
+
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {c.config.ClientID},
+		"redirect_uri":          {c.config.RedirectUrl},
+		"scope":                 {strings.Join(c.config.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {codeChallenge},
+		"code_challenge_method": {"S256"},
+	}
+	separator := "?"
+	if strings.Contains(d.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return d.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+func (c *client) Exchange(code, codeVerifier, nonce string) (*IdTokenClaims, error) {
+	d, err := c.getDiscovery()
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {c.config.RedirectUrl},
+		"client_id":     {c.config.ClientID},
+		"code_verifier": {codeVerifier},
+	}
+	req, err := http.NewRequest(http.MethodPost, d.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create the token request")
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if c.config.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(c.config.ClientID), url.QueryEscape(c.config.ClientSecret))
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform the token request")
+	}
+	defer resp.Body.Close()
+
+	tokens := &tokenResponse{}
+	if err = json.NewDecoder(resp.Body).Decode(tokens); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode the token response with status %d", resp.StatusCode)
+	}
+	if tokens.Error != "" {
+		return nil, fmt.Errorf("token request failed: %s %s", tokens.Error, tokens.ErrorDescription)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token request failed with status %d", resp.StatusCode)
+	}
+	if tokens.IdToken == "" {
+		return nil, errors.New("the token response has no id token")
+	}
+
+	return c.verifyIdToken(d, tokens.IdToken, nonce)
+}
+
+func (c *client) verifyIdToken(d *discovery, rawToken, nonce string) (*IdTokenClaims, error) {
+	claims := &IdTokenClaims{}
+	_, err := jwt.ParseWithClaims(rawToken, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return c.getKey(d, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256"}),
+		jwt.WithIssuer(d.Issuer),
+		jwt.WithAudience(c.config.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute))
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid id token")
+	}
+	if claims.Nonce != nonce {
+		return nil, errors.New("invalid id token: nonce does not match")
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("invalid id token: subject is missing")
+	}
+	return claims, nil
+}
+
+func (c *client) getDiscovery() (*discovery, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.discovery != nil {
+		return c.discovery, nil
+	}
+
+	d := &discovery{}
+	if err := c.getJson(c.config.Issuer+"/.well-known/openid-configuration", d); err != nil {
+		return nil, errors.Wrap(err, "failed to discover the provider")
+	}
+	if strings.TrimSuffix(d.Issuer, "/") != c.config.Issuer {
+		return nil, fmt.Errorf("discovered issuer %q does not match %q", d.Issuer, c.config.Issuer)
+	}
+	if d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" || d.JwksUri == "" {
+		return nil, errors.New("the provider metadata misses an endpoint")
+	}
+
+	c.discovery = d
+	return d, nil
+}
+
+// getKey returns the signing key of the provider, fetching the keys again when the provider rotated them.
+func (c *client) getKey(d *discovery, kid string) (*rsa.PublicKey, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key, ok := c.findKey(kid)
+	if ok || time.Since(c.keysFetched) < jwksRefreshInterval {
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+		return key, nil
+	}
+
+	set := &jwks{}
+	if err := c.getJson(d.JwksUri, set); err != nil {
+		return nil, errors.Wrap(err, "failed to fetch the signing keys")
+	}
+	keys := map[string]*rsa.PublicKey{}
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+		pub, err := parseRsaKey(k)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse the signing key %q", k.Kid)
+		}
+		keys[k.Kid] = pub
+	}
+	c.keys = keys
+	c.keysFetched = time.Now()
+
+	if key, ok = c.findKey(kid); !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return key, nil
+}
+
+// findKey looks up the key by id, a token without a key id can only be verified when the provider has one key.
+func (c *client) findKey(kid string) (*rsa.PublicKey, bool) {
+	if kid == "" && len(c.keys) == 1 {
+		for _, key := range c.keys {
+			return key, true
+		}
+	}
+	key, ok := c.keys[kid]
+	return key, ok
+}
+
+func parseRsaKey(k jwk) (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid modulus")
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid exponent")
+	}
+	exponent := new(big.Int).SetBytes(e)
+	if !exponent.IsInt64() || exponent.Int64() > 1<<31-1 {
+		return nil, errors.New("exponent is too large")
+	}
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(exponent.Int64()),
+	}, nil
+}
+
+func (c *client) getJson(endpoint string, target any) error {
+	resp, err := c.HTTPClient.Get(endpoint)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("GET %s failed with status %d: %s", endpoint, resp.StatusCode, string(body))
+	}
+	return json.NewDecoder(resp.Body).Decode(target)
+}
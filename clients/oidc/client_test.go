@@ -0,0 +1,158 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// testProvider is a minimal OpenID Connect provider that issues an id token for a single authorization code.
+type testProvider struct {
+	*httptest.Server
+	key       *rsa.PrivateKey
+	code      string
+	challenge string
+	nonce     string
+}
+
+func newTestProvider(t *testing.T) *testProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	p := &testProvider{key: key, code: "test-code"}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 p.URL,
+			"authorization_endpoint": p.URL + "/authorize",
+			"token_endpoint":         p.URL + "/token",
+			"jwks_uri":               p.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "key-1",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		id, secret, _ := r.BasicAuth()
+		if r.Form.Get("code") != p.code || id != "cliplab" || secret != "s3cret" ||
+			CodeChallenge(r.Form.Get("code_verifier")) != p.challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{
+			"access_token": "access",
+			"id_token":     p.sign(t, "key-1", p.nonce),
+		})
+	})
+	p.Server = httptest.NewServer(mux)
+	t.Cleanup(p.Close)
+	return p
+}
+
+func (p *testProvider) sign(t *testing.T, kid, nonce string) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, &IdTokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    p.URL,
+			Subject:   "user-1",
+			Audience:  jwt.ClaimStrings{"cliplab"},
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+		Nonce:         nonce,
+		Email:         "user@agency.test",
+		EmailVerified: true,
+	})
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(p.key)
+	if err != nil {
+		t.Fatalf("SignedString: %v", err)
+	}
+	return signed
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	p := newTestProvider(t)
+	c := NewClient(Config{
+		Issuer:       p.URL + "/",
+		ClientID:     "cliplab",
+		ClientSecret: "s3cret",
+		RedirectUrl:  "http://localhost:3000/sso/callback",
+		Scopes:       []string{"openid", "email"},
+	})
+
+	verifier, err := NewCodeVerifier()
+	if err != nil {
+		t.Fatalf("NewCodeVerifier: %v", err)
+	}
+	p.challenge, p.nonce = CodeChallenge(verifier), "nonce-1"
+
+	authUrl, err := c.AuthCodeUrl("state-1", p.nonce, p.challenge)
+	if err != nil {
+		t.Fatalf("AuthCodeUrl: %v", err)
+	}
+	parsed, _ := url.Parse(authUrl)
+	query := parsed.Query()
+	if parsed.Path != "/authorize" || query.Get("state") != "state-1" || query.Get("code_challenge") != p.challenge ||
+		query.Get("code_challenge_method") != "S256" || query.Get("scope") != "openid email" {
+		t.Errorf("unexpected authorization url %s", authUrl)
+	}
+
+	claims, err := c.Exchange(p.code, verifier, p.nonce)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	if claims.Subject != "user-1" || claims.Email != "user@agency.test" || !claims.EmailVerified {
+		t.Errorf("unexpected claims %+v", claims)
+	}
+
+	if _, err = c.Exchange(p.code, "wrong-verifier", p.nonce); err == nil {
+		t.Error("expected an error for a wrong code verifier")
+	}
+	if _, err = c.Exchange(p.code, verifier, "other-nonce"); err == nil || !strings.Contains(err.Error(), "nonce") {
+		t.Errorf("expected a nonce error, got %v", err)
+	}
+}
+
+func TestVerifyIdTokenRejectsUnknownKey(t *testing.T) {
+	p := newTestProvider(t)
+	c := NewClient(Config{Issuer: p.URL, ClientID: "cliplab"}).(*client)
+	d, err := c.getDiscovery()
+	if err != nil {
+		t.Fatalf("getDiscovery: %v", err)
+	}
+
+	if _, err = c.verifyIdToken(d, p.sign(t, "key-1", "n"), "n"); err != nil {
+		t.Fatalf("verifyIdToken: %v", err)
+	}
+
+	other, _ := rsa.GenerateKey(rand.Reader, 2048)
+	p.key = other
+	if _, err = c.verifyIdToken(d, p.sign(t, "key-1", "n"), "n"); err == nil {
+		t.Error("expected an error for a token signed with another key")
+	}
+	if _, err = c.verifyIdToken(d, p.sign(t, "key-2", "n"), "n"); err == nil {
+		t.Error("expected an error for an unknown key id")
+	}
+}
@@ -208,6 +208,7 @@ type Media struct {
 	BoostUnavailableReason     interface{}    `json:"boost_unavailable_reason"`
 	User                       UserReference  `json:"user"`
 	ProductType                string         `json:"product_type"`
+	TakenAt                    int64          `json:"taken_at"` // Unix timestamp
 	PlayCount                  int64          `json:"play_count"`
 	ViewCount                  int64          `json:"view_count"`
 	LikeAndViewCountsDisabled  bool           `json:"like_and_view_counts_disabled"`
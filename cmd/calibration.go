@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/amahdian/cliplab-be/global/env"
+	"github.com/amahdian/cliplab-be/pkg/db"
+	"github.com/amahdian/cliplab-be/pkg/logger"
+	"github.com/amahdian/cliplab-be/storage/pg"
+	"github.com/amahdian/cliplab-be/svc"
+
+	"github.com/spf13/cobra"
+)
+
+var calibrationCmd = &cobra.Command{
+	Use:   "calibration",
+	Short: "Print how the viral score of analyzed posts compares with their later growth",
+	Run:   runCalibration,
+}
+
+func runCalibration(cmd *cobra.Command, args []string) {
+	envs, err := env.Load("")
+	if err != nil {
+		log.Fatalf("failed to load env variables: %v", err)
+	}
+
+	pgDb, err := db.OpenGormDb(envs.Db.Dsn, db.LogLevel(strings.ToLower(envs.Db.LogLevel)))
+	if err != nil {
+		logger.Fatalf("failed to open database: %v", err)
+	}
+
+	services := svc.NewSvc(pg.NewStg(pgDb), envs, nil, nil, nil, nil, nil, nil, svc.StorageConfig{})
+	report, err := services.NewReportSvc(context.Background()).GetCalibrationReport()
+	if err != nil {
+		logger.Fatalf("calibration report failed: %v", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "DAY\tPOSTS\tCORR(GROWTH)\tCORR(OUTLIER)")
+	for _, c := range report.Correlations {
+		_, _ = fmt.Fprintf(w, "%d\t%d\t%s\t%s\n", c.DayOffset, c.Posts, formatStat(c.ViewGrowth), formatStat(c.OutlierMultiple))
+	}
+	_, _ = fmt.Fprintln(w)
+	_, _ = fmt.Fprintln(w, "DAY\tSCORE\tPOSTS\tAVG GROWTH\tMEDIAN GROWTH\tAVG OUTLIER\tMEDIAN OUTLIER")
+	for _, b := range report.Buckets {
+		_, _ = fmt.Fprintf(w, "%d\t%d-%d\t%d\t%s\t%s\t%s\t%s\n", b.DayOffset, b.MinScore, b.MaxScore, b.Posts,
+			formatStat(b.AvgViewGrowth), formatStat(b.MedianViewGrowth),
+			formatStat(b.AvgOutlierMultiple), formatStat(b.MedianOutlierMultiple))
+	}
+	_ = w.Flush()
+}
+
+func formatStat(value *float64) string {
+	if value == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f", *value)
+}
+
+func init() {
+	rootCmd.AddCommand(calibrationCmd)
+}
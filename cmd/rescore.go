@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"github.com/amahdian/cliplab-be/global/env"
+	"github.com/amahdian/cliplab-be/pkg/db"
+	"github.com/amahdian/cliplab-be/pkg/logger"
+	"github.com/amahdian/cliplab-be/storage/pg"
+	"github.com/amahdian/cliplab-be/svc"
+
+	"github.com/spf13/cobra"
+)
+
+var rescoreCmd = &cobra.Command{
+	Use:   "rescore",
+	Short: "Recompute the viral score of stored analyses with the configured formula",
+	Run:   runRescore,
+}
+
+func runRescore(cmd *cobra.Command, args []string) {
+	envs, err := env.Load("")
+	if err != nil {
+		log.Fatalf("failed to load env variables: %v", err)
+	}
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	pgDb, err := db.OpenGormDb(envs.Db.Dsn, db.LogLevel(strings.ToLower(envs.Db.LogLevel)))
+	if err != nil {
+		logger.Fatalf("failed to open database: %v", err)
+	}
+
+	services := svc.NewSvc(pg.NewStg(pgDb), envs, nil, nil, nil, nil, nil, nil, svc.StorageConfig{})
+	result, err := services.NewScoringSvc(context.Background()).Rescore(dryRun)
+	if err != nil {
+		logger.Fatalf("rescore failed: %v", err)
+	}
+
+	logger.Infof("rescored %d analyses with formula %s: %d changed, %d failed (dry run: %v)",
+		result.Scanned, envs.Scoring.Version, result.Changed, result.Failed, dryRun)
+}
+
+func init() {
+	rescoreCmd.Flags().Bool("dry-run", false, "compute the new scores without saving them")
+	rootCmd.AddCommand(rescoreCmd)
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/amahdian/cliplab-be/global/env"
+	"github.com/amahdian/cliplab-be/pkg/db"
+	"github.com/amahdian/cliplab-be/pkg/logger"
+	"github.com/amahdian/cliplab-be/storage/pg"
+
+	"github.com/spf13/cobra"
+)
+
+var userRoleCmd = &cobra.Command{
+	Use:   "user-role <email> <user|admin>",
+	Short: "Set the role of a user, admins can use the operational endpoints",
+	Args:  cobra.ExactArgs(2),
+	Run:   runUserRole,
+}
+
+func runUserRole(cmd *cobra.Command, args []string) {
+	envs, err := env.Load("")
+	if err != nil {
+		log.Fatalf("failed to load env variables: %v", err)
+	}
+	email, role := strings.ToLower(strings.TrimSpace(args[0])), model.UserRole(args[1])
+	if role != model.UserRoleUser && role != model.UserRoleAdmin {
+		logger.Fatalf("unknown role %s", role)
+	}
+
+	pgDb, err := db.OpenGormDb(envs.Db.Dsn, db.LogLevel(strings.ToLower(envs.Db.LogLevel)))
+	if err != nil {
+		logger.Fatalf("failed to open database: %v", err)
+	}
+
+	users := pg.NewStg(pgDb).User(context.Background())
+	user, err := users.FindByEmail(email)
+	if err != nil {
+		logger.Fatalf("failed to find the user: %v", err)
+	}
+	if user == nil {
+		logger.Fatalf("user %s not found", email)
+	}
+	user.Role = role
+	if err = users.UpdateOne(user, false); err != nil {
+		logger.Fatalf("failed to update the user: %v", err)
+	}
+
+	logger.Infof("user %s is now %s", email, role)
+}
+
+func init() {
+	rootCmd.AddCommand(userRoleCmd)
+}
@@ -0,0 +1,11 @@
+package req
+
+import "github.com/amahdian/cliplab-be/domain/model"
+
+type CreateApiKey struct {
+	Name   string              `json:"name" binding:"required,max=100"`
+	Scopes []model.ApiKeyScope `json:"scopes" binding:"required,min=1,dive,oneof=analyze:write library:read channels:read"`
+	// RateLimit lowers the requests per minute of the key below the default.
+	RateLimit     *int `json:"rateLimit" binding:"omitempty,min=1"`
+	ExpiresInDays *int `json:"expiresInDays" binding:"omitempty,min=1,max=365"`
+}
@@ -0,0 +1,16 @@
+package req
+
+import "github.com/amahdian/cliplab-be/pkg/paged"
+
+type SaveCategory struct {
+	Path string `json:"path" binding:"required"`
+}
+
+type Library struct {
+	paged.PaginatedRequest
+	Category string `form:"category"`
+}
+
+type Benchmarks struct {
+	Category string `form:"category"`
+}
@@ -0,0 +1,13 @@
+package req
+
+type ChatTransport string
+
+const (
+	ChatTransportSse       ChatTransport = "sse"
+	ChatTransportWebSocket ChatTransport = "ws"
+)
+
+type PostChat struct {
+	Message   string        `json:"message" binding:"required"`
+	Transport ChatTransport `json:"transport" binding:"omitempty,oneof=sse ws"`
+}
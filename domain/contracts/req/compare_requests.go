@@ -0,0 +1,5 @@
+package req
+
+type ComparePosts struct {
+	PostIds []string `json:"postIds" binding:"required,min=2,max=5,unique,dive,required"`
+}
@@ -1,5 +1,17 @@
 package req
 
+import "github.com/amahdian/cliplab-be/domain/model"
+
 type AddPost struct {
 	Url string `json:"url" binding:"required"`
 }
+
+type SearchPosts struct {
+	Query string `form:"q" binding:"required"`
+	Limit int    `form:"limit" binding:"omitempty,min=1,max=50"`
+}
+
+type SimilarPosts struct {
+	Scope model.SimilarPostsScope `form:"scope" binding:"omitempty,oneof=all library other_channels"`
+	Limit int                     `form:"limit" binding:"omitempty,min=1,max=50"`
+}
@@ -0,0 +1,20 @@
+package req
+
+import "github.com/amahdian/cliplab-be/domain/model"
+
+type RemixPost struct {
+	Section      model.RemixSection `json:"section" binding:"required,oneof=hooks scripts captions hashtags"`
+	Tone         string             `json:"tone" binding:"max=50"`
+	TargetLength string             `json:"targetLength" binding:"omitempty,oneof=short medium long"`
+	Language     string             `json:"language" binding:"max=10"`
+	Count        int                `json:"count" binding:"min=0,max=10"`
+}
+
+type RemixVariants struct {
+	Section   model.RemixSection `form:"section" binding:"omitempty,oneof=hooks scripts captions hashtags"`
+	Favorites bool               `form:"favorites"`
+}
+
+type FavoriteRemixVariant struct {
+	Favorite bool `json:"favorite"`
+}
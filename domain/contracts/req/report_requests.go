@@ -0,0 +1,8 @@
+package req
+
+import "time"
+
+type LlmCostReport struct {
+	From *time.Time `form:"from" time_format:"2006-01-02"`
+	To   *time.Time `form:"to" time_format:"2006-01-02"`
+}
@@ -4,14 +4,24 @@ import (
 	"context"
 	"net"
 
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/amahdian/cliplab-be/global/errs"
 	"github.com/amahdian/cliplab-be/svc/auth"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
+// WorkspaceHeader selects the workspace whose library a request reads or analyzes into,
+// the personal library of the user is used without it.
+const WorkspaceHeader = "X-Workspace-Id"
+
 type RequestContext struct {
-	Ctx      context.Context
-	UserInfo *auth.UserInfo
-	Ip       net.IP
+	Ctx       context.Context
+	UserInfo  *auth.UserInfo
+	Ip        net.IP
+	UserAgent string
+
+	workspaceId string
 }
 
 func GetRequestContext(c *gin.Context) RequestContext {
@@ -19,8 +29,32 @@ func GetRequestContext(c *gin.Context) RequestContext {
 	userInfo := auth.UserInfoFromCtx(ctx)
 
 	return RequestContext{
-		Ctx:      ctx,
-		UserInfo: &userInfo,
-		Ip:       net.ParseIP(c.ClientIP()),
+		Ctx:       ctx,
+		UserInfo:  &userInfo,
+		Ip:        net.ParseIP(c.ClientIP()),
+		UserAgent: c.Request.UserAgent(),
+
+		workspaceId: c.GetHeader(WorkspaceHeader),
+	}
+}
+
+// WorkspaceId returns the workspace selected by the request, or nil for the personal library.
+func (r RequestContext) WorkspaceId() (*uuid.UUID, error) {
+	if r.workspaceId == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(r.workspaceId)
+	if err != nil {
+		return nil, errs.Newf(errs.InvalidArgument, err, "invalid workspace id")
+	}
+	return &id, nil
+}
+
+// Device describes the client of the request for the session it signs in to.
+func (r RequestContext) Device() model.SessionDevice {
+	device := model.SessionDevice{UserAgent: r.UserAgent}
+	if r.Ip != nil {
+		device.Ip = r.Ip.String()
 	}
+	return device
 }
@@ -18,12 +18,64 @@ type OauthLogin struct {
 	Token    string         `json:"token" binding:"required"`
 }
 
+type ProviderUri struct {
+	Provider model.Provider `uri:"provider" binding:"required,oneof=google facebook oidc"`
+}
+
+// OidcCallback is sent back by the frontend after the identity provider redirected the user to it.
+// Flow is the token returned with the authorization url.
+type OidcCallback struct {
+	Code  string `json:"code" binding:"required"`
+	State string `json:"state" binding:"required"`
+	Flow  string `json:"flow" binding:"required"`
+}
+
+type TwoFactorLogin struct {
+	Challenge string `json:"challenge" binding:"required"`
+	Code      string `json:"code" binding:"required"`
+}
+
+// TwoFactorCode is a code of the authenticator app, or a recovery code where one is accepted.
+type TwoFactorCode struct {
+	Code string `json:"code" binding:"required"`
+}
+
 type Verify struct {
 	Email string `json:"email" binding:"required,email"`
 	Otp   string `json:"otp" binding:"required"`
 }
 
+type ResendVerification struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type RefreshToken struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
+}
+
+type ForgotPassword struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPassword struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+type ChangePassword struct {
+	CurrentPassword string `json:"currentPassword" binding:"required"`
+	NewPassword     string `json:"newPassword" binding:"required,min=6"`
+}
+
 type UserUpdate struct {
 	Name            *string         `json:"name" binding:"omitempty,min=2,max=100"`
 	DefaultLanguage *model.Language `json:"defaultLanguage" binding:"omitempty,oneof=en fa"`
 }
+
+// DeleteAccount confirms the deletion with the password of the account when it has one,
+// and a TOTP or recovery code when two-factor authentication is enabled.
+// The accounts with neither confirm it with the code emailed by the deletion code endpoint.
+type DeleteAccount struct {
+	Password string `json:"password"`
+	Code     string `json:"code"`
+}
@@ -0,0 +1,36 @@
+package req
+
+import "github.com/amahdian/cliplab-be/domain/model"
+
+type CreateWorkspace struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+type UpdateWorkspace struct {
+	Name             *string `json:"name" binding:"omitempty,min=1,max=100"`
+	RequireTwoFactor *bool   `json:"requireTwoFactor"`
+}
+
+type WorkspaceMemberUri struct {
+	Id     string `uri:"id" binding:"required"`
+	UserId string `uri:"userId" binding:"required"`
+}
+
+type WorkspaceInvitationUri struct {
+	Id           string `uri:"id" binding:"required"`
+	InvitationId string `uri:"invitationId" binding:"required"`
+}
+
+type UpdateWorkspaceMember struct {
+	Role model.WorkspaceRole `json:"role" binding:"required,oneof=owner editor viewer"`
+}
+
+type InviteWorkspaceMember struct {
+	Email string              `json:"email" binding:"required,email,max=255"`
+	Role  model.WorkspaceRole `json:"role" binding:"required,oneof=owner editor viewer"`
+}
+
+// AcceptWorkspaceInvitation carries the token of the link emailed with the invitation.
+type AcceptWorkspaceInvitation struct {
+	Token string `json:"token" binding:"required"`
+}
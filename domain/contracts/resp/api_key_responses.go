@@ -0,0 +1,9 @@
+package resp
+
+import "github.com/amahdian/cliplab-be/domain/model"
+
+// CreatedApiKey is the only response that carries the key itself.
+type CreatedApiKey struct {
+	*model.ApiKey
+	Key string `json:"key"`
+}
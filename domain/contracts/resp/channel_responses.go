@@ -0,0 +1,45 @@
+package resp
+
+import "github.com/google/uuid"
+
+// PostingTimes is the engagement of the reels of a channel by the weekday and hour they were posted,
+// in the timezone inferred for the channel. Cells holds all 7x24 slots, Sunday first, for a heatmap.
+type PostingTimes struct {
+	ChannelId  uuid.UUID `json:"channelId"`
+	SampleSize int       `json:"sampleSize"`
+	// UtcOffset is the timezone of the channel in hours east of UTC, 0 when it could not be inferred.
+	UtcOffset        int     `json:"utcOffset"`
+	TimezoneInferred bool    `json:"timezoneInferred"`
+	MedianPlays      float64 `json:"medianPlays"`
+
+	Cells     []*PostingTimeCell    `json:"cells"`
+	Weekdays  []*PostingTimeWeekday `json:"weekdays"`
+	Hours     []*PostingTimeHour    `json:"hours"`
+	BestSlots []*PostingTimeCell    `json:"bestSlots"`
+}
+
+// PostingTimeStat is the engagement of the reels posted in a slot.
+type PostingTimeStat struct {
+	Posts       int     `json:"posts"`
+	MedianPlays float64 `json:"medianPlays"`
+	// EngagementRate is the average of likes and comments per play, in percent.
+	EngagementRate float64 `json:"engagementRate"`
+	// Lift is the median plays of the slot divided by the median plays of the channel.
+	Lift float64 `json:"lift"`
+}
+
+type PostingTimeCell struct {
+	Weekday int `json:"weekday"` // 0 is Sunday
+	Hour    int `json:"hour"`
+	PostingTimeStat
+}
+
+type PostingTimeWeekday struct {
+	Weekday int `json:"weekday"`
+	PostingTimeStat
+}
+
+type PostingTimeHour struct {
+	Hour int `json:"hour"`
+	PostingTimeStat
+}
@@ -18,9 +18,10 @@ type PostContentResponse struct {
 
 type PostContentSegmentResponse struct {
 	*PostContentResponse `json:",inline"`
-	Timestamp            string `json:"timestamp"`
-	Emotion              string `json:"emotion"`
-	Speaker              string `json:"speaker"`
+	Timestamp            string                 `json:"timestamp"`
+	Emotion              string                 `json:"emotion"`
+	Speaker              string                 `json:"speaker"`
+	Source               model.TranscriptSource `json:"source"`
 }
 
 type AnalyzeResult struct {
@@ -46,5 +47,75 @@ type AnalyzeResult struct {
 	AverageViewCount      int64   `json:"averageViewCount,omitempty"`
 	AverageEngagementRate float64 `json:"averageEngagementRate,omitempty"`
 
+	// OutlierSummary reads like "4.2x the channel's median views".
+	OutlierSummary string              `json:"outlierSummary,omitempty"`
+	OutlierStats   *model.OutlierStats `json:"outlierStats,omitempty"`
+
 	Analysis *model.PostAnalysis `json:"analysis,omitempty"`
 }
+
+// AnalysisSectionEvent is pushed over the websocket whenever a section of a streamed analysis is persisted.
+type AnalysisSectionEvent struct {
+	RequestId string                        `json:"requestId"`
+	PostId    string                        `json:"postId"`
+	Section   string                        `json:"section"`
+	Analysis  *model.PostAnalysis           `json:"analysis"`
+	Segments  []*PostContentSegmentResponse `json:"segments,omitempty"`
+}
+
+type SearchResult struct {
+	PostId      string       `json:"postId"`
+	Link        string       `json:"link"`
+	ImageUrl    *string      `json:"imageUrl,omitempty"`
+	UserHandler string       `json:"userHandler"`
+	PostDate    time.Time    `json:"postDate"`
+	Similarity  float64      `json:"similarity"`
+	Match       *SearchMatch `json:"match"`
+}
+
+// SearchMatch is the part of the post that matched the search query best.
+type SearchMatch struct {
+	Type      model.PostContentType `json:"type"`
+	Content   string                `json:"content"`
+	Language  string                `json:"language"`
+	Timestamp string                `json:"timestamp,omitempty"`
+}
+
+type SimilarPost struct {
+	PostId       string    `json:"postId"`
+	Link         string    `json:"link"`
+	ImageUrl     *string   `json:"imageUrl,omitempty"`
+	UserHandler  string    `json:"userHandler"`
+	PostDate     time.Time `json:"postDate"`
+	BigIdea      string    `json:"bigIdea"`
+	LikeCount    int64     `json:"likeCount"`
+	CommentCount int64     `json:"commentCount"`
+	ViewCount    int64     `json:"viewCount"`
+
+	Similarity float64 `json:"similarity"`
+	// EngagementLift is the engagement of the post relative to the average of its channel.
+	EngagementLift float64 `json:"engagementLift"`
+	Score          float64 `json:"score"`
+}
+
+type LibraryPost struct {
+	RequestId    string    `json:"requestId"`
+	PostId       string    `json:"postId"`
+	Link         string    `json:"link"`
+	ImageUrl     *string   `json:"imageUrl,omitempty"`
+	UserHandler  string    `json:"userHandler"`
+	PostDate     time.Time `json:"postDate"`
+	LikeCount    int64     `json:"likeCount"`
+	CommentCount int64     `json:"commentCount"`
+	ViewCount    int64     `json:"viewCount"`
+	ViralScore   int       `json:"viralScore"`
+	BigIdea      string    `json:"bigIdea"`
+	Categories   []string  `json:"categories"`
+	AnalyzedAt   time.Time `json:"analyzedAt"`
+}
+
+type ChatStreamEvent struct {
+	PostId  string                `json:"postId"`
+	Type    model.StreamEventType `json:"type"`
+	Content string                `json:"content"`
+}
@@ -0,0 +1,22 @@
+package resp
+
+import (
+	"time"
+
+	"github.com/amahdian/cliplab-be/domain/model"
+)
+
+type LlmCostReport struct {
+	From      time.Time               `json:"from"`
+	To        time.Time               `json:"to"`
+	Requests  int64                   `json:"requests"`
+	TotalCost float64                 `json:"totalCost"`
+	Rows      []*model.LlmCostSummary `json:"rows"`
+}
+
+// CalibrationReport compares the predicted viral score of analyzed posts with their growth after the analysis.
+type CalibrationReport struct {
+	BucketSize   int                             `json:"bucketSize"`
+	Correlations []*model.CalibrationCorrelation `json:"correlations"`
+	Buckets      []*model.CalibrationBucket      `json:"buckets"`
+}
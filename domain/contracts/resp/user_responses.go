@@ -1,12 +1,43 @@
 package resp
 
 import (
+	"time"
+
 	"github.com/amahdian/cliplab-be/domain/model"
 )
 
+// AuthResponse carries the tokens of a new session, or only a Challenge when the user
+// still has to enter a code of their authenticator app.
 type AuthResponse struct {
-	Token string      `json:"token"`
-	User  *model.User `json:"user"`
+	Token        string              `json:"token,omitempty"`
+	ExpiresAt    time.Time           `json:"expiresAt,omitzero"`
+	RefreshToken string              `json:"refreshToken,omitempty"`
+	User         *model.User         `json:"user,omitempty"`
+	Challenge    *TwoFactorChallenge `json:"challenge,omitempty"`
+}
+
+// TwoFactorChallenge is exchanged for the tokens together with a TOTP or recovery code.
+type TwoFactorChallenge struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// TotpSetup is the secret to add to an authenticator app, Uri is meant to be shown as a QR code.
+type TotpSetup struct {
+	Secret string `json:"secret"`
+	Uri    string `json:"uri"`
+}
+
+// RecoveryCodes are shown to the user once, only their hashes are stored.
+type RecoveryCodes struct {
+	Codes []string `json:"codes"`
+}
+
+// OidcAuthorization starts a single sign-on. The frontend redirects the user to Url
+// and keeps Flow to send it back with the code and state of the callback.
+type OidcAuthorization struct {
+	Url  string `json:"url"`
+	Flow string `json:"flow"`
 }
 
 type VerifyResponse struct {
@@ -14,4 +45,22 @@ type VerifyResponse struct {
 	Name            *string        `json:"name"`
 	DefaultLanguage model.Language `json:"defaultLanguage"`
 	Token           string         `json:"token"`
+	ExpiresAt       time.Time      `json:"expiresAt"`
+	RefreshToken    string         `json:"refreshToken"`
+}
+
+type UserSession struct {
+	*model.UserSession
+	Current bool `json:"current"`
+}
+
+// SignInMethods are the ways a user can sign in: a password and the linked provider accounts.
+type SignInMethods struct {
+	HasPassword bool                  `json:"hasPassword"`
+	Identities  []*model.UserIdentity `json:"identities"`
+}
+
+// AccountDeletion tells when the account will be purged, signing in before then cancels the deletion.
+type AccountDeletion struct {
+	ScheduledAt time.Time `json:"scheduledAt"`
 }
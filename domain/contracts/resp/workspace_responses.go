@@ -0,0 +1,9 @@
+package resp
+
+import "github.com/amahdian/cliplab-be/domain/model"
+
+// Workspace is a workspace along with the role of the user in it.
+type Workspace struct {
+	*model.Workspace
+	Role model.WorkspaceRole `json:"role"`
+}
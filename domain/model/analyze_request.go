@@ -16,13 +16,16 @@ const (
 )
 
 type AnalyzeRequest struct {
-	ID        uuid.UUID  `json:"id" gorm:"type:uuid;default:uuid_generate_v4()"`
-	UserId    *uuid.UUID `json:"userId" gorm:"type:uuid"`
-	UserIP    string     `json:"userIp" gorm:"type:inet"`
-	Link      string     `json:"link"`
-	PostId    *string    `json:"postId"`
-	CreatedAt time.Time  `json:"createdAt"`
-	UpdatedAt time.Time  `json:"updatedAt"`
+	ID     uuid.UUID  `json:"id" gorm:"type:uuid;default:uuid_generate_v4()"`
+	UserId *uuid.UUID `json:"userId" gorm:"type:uuid"`
+	// WorkspaceId is set when the post was analyzed into the library of a workspace.
+	WorkspaceId *uuid.UUID     `json:"workspaceId" gorm:"type:uuid"`
+	UserIP      string         `json:"userIp" gorm:"type:inet"`
+	Link        string         `json:"link"`
+	Platform    SocialPlatform `json:"platform"`
+	PostId      *string        `json:"postId"`
+	CreatedAt   time.Time      `json:"createdAt"`
+	UpdatedAt   time.Time      `json:"updatedAt"`
 
 	Status     RequestStatus `json:"status"`
 	FailReason *string       `json:"failReason"`
@@ -31,6 +34,8 @@ type AnalyzeRequest struct {
 	LlmRequest  string `json:"llmRequest"`
 	LlmResponse string `json:"llmResponse"`
 
+	Usage LlmUsage `json:"usage" gorm:"embedded"`
+
 	Post *Post `json:"post,omitempty" gorm:"foreignKey:PostId;references:ID"`
 	User *User `json:"user,omitempty" gorm:"foreignKey:UserId;references:ID"`
 }
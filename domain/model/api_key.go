@@ -0,0 +1,46 @@
+package model
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ApiKeyScope is an area of the api an api key can be used for.
+type ApiKeyScope string
+
+const (
+	ApiKeyScopeAnalyzeWrite ApiKeyScope = "analyze:write"
+	ApiKeyScopeLibraryRead  ApiKeyScope = "library:read"
+	ApiKeyScopeChannelsRead ApiKeyScope = "channels:read"
+)
+
+// ApiKey lets the scripts of a user call the api. The key is identified by its prefix and only its HMAC is stored.
+type ApiKey struct {
+	ID      uuid.UUID     `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserId  uuid.UUID     `json:"-" gorm:"type:uuid"`
+	Name    string        `json:"name"`
+	Prefix  string        `json:"prefix"`
+	KeyHash string        `json:"-"`
+	Scopes  []ApiKeyScope `json:"scopes" gorm:"serializer:json"`
+	// RateLimit is the number of requests allowed per minute.
+	RateLimit  int        `json:"rateLimit"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	ExpiresAt  *time.Time `json:"expiresAt"`
+	RevokedAt  *time.Time `json:"-"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+func (*ApiKey) TableName() string {
+	return "api_keys"
+}
+
+// Active reports whether the key can still be used.
+func (k *ApiKey) Active() bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || time.Now().Before(*k.ExpiresAt))
+}
+
+func (k *ApiKey) HasScope(scope ApiKeyScope) bool {
+	return slices.Contains(k.Scopes, scope)
+}
@@ -0,0 +1,54 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CategoryPathSeparator separates the levels of a category path, e.g. "Technology/AI".
+const CategoryPathSeparator = "/"
+
+type Category struct {
+	ID   uuid.UUID `json:"id" gorm:"type:uuid;default:uuid_generate_v4()"`
+	Name string    `json:"name"`
+	Path string    `json:"path"`
+	// Suggested is set on subcategories proposed by the classifier, until an admin reviews them.
+	Suggested bool `json:"suggested"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (*Category) TableName() string {
+	return "categories"
+}
+
+// Root returns the main category of the path.
+func (c *Category) Root() string {
+	return strings.SplitN(c.Path, CategoryPathSeparator, 2)[0]
+}
+
+type PostCategory struct {
+	PostId     string    `json:"postId" gorm:"primaryKey"`
+	CategoryId uuid.UUID `json:"categoryId" gorm:"type:uuid;primaryKey"`
+	CreatedAt  time.Time `json:"createdAt"`
+
+	Category *Category `json:"category,omitempty" gorm:"foreignKey:CategoryId;references:ID"`
+}
+
+func (*PostCategory) TableName() string {
+	return "post_categories"
+}
+
+// NicheBenchmark summarizes the performance of the analyzed posts of a category.
+type NicheBenchmark struct {
+	Category      string  `json:"category"`
+	Posts         int64   `json:"posts"`
+	AvgLikes      float64 `json:"avgLikes"`
+	AvgComments   float64 `json:"avgComments"`
+	AvgPlays      float64 `json:"avgPlays"`
+	MedianPlays   float64 `json:"medianPlays"`
+	AvgViralScore float64 `json:"avgViralScore"`
+}
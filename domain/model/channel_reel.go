@@ -0,0 +1,26 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ChannelReel keeps the latest counts of a reel seen while scraping the reels of a channel,
+// so the history of a channel outlives the handful of recent reels returned by a single scrape.
+type ChannelReel struct {
+	ChannelId uuid.UUID `json:"channelId" gorm:"type:uuid;primaryKey"`
+	Code      string    `json:"code" gorm:"primaryKey"`
+	TakenAt   time.Time `json:"takenAt"`
+
+	PlayCount    int64 `json:"playCount"`
+	LikeCount    int64 `json:"likeCount"`
+	CommentCount int64 `json:"commentCount"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (*ChannelReel) TableName() string {
+	return "channel_reels"
+}
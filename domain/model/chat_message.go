@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ChatRole string
+
+const (
+	ChatRoleUser      ChatRole = "user"
+	ChatRoleAssistant ChatRole = "assistant"
+)
+
+// ChatMessage is a message of the conversation of a user about a post.
+type ChatMessage struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserId    uuid.UUID `json:"userId" gorm:"type:uuid"`
+	PostId    string    `json:"postId"`
+	Role      ChatRole  `json:"role"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func (*ChatMessage) TableName() string {
+	return "chat_messages"
+}
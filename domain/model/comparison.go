@@ -0,0 +1,59 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Comparison is a saved side-by-side comparison of analyzed posts.
+// Posts and metrics are a snapshot taken when the comparison was made.
+type Comparison struct {
+	ID        uuid.UUID         `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserId    uuid.UUID         `json:"-" gorm:"type:uuid"`
+	PostIds   []string          `json:"postIds" gorm:"serializer:json"`
+	Posts     []*ComparedPost   `json:"posts" gorm:"serializer:json"`
+	Metrics   []*ComparedMetric `json:"metrics" gorm:"serializer:json"`
+	Narrative string            `json:"narrative"`
+	CreatedAt time.Time         `json:"createdAt"`
+}
+
+func (*Comparison) TableName() string {
+	return "comparisons"
+}
+
+// ComparedPost is a post of a comparison along with its performance against its channel.
+type ComparedPost struct {
+	PostId       string      `json:"postId"`
+	Link         string      `json:"link"`
+	ImageUrl     *string     `json:"imageUrl,omitempty"`
+	UserHandler  string      `json:"userHandler"`
+	PostDate     time.Time   `json:"postDate"`
+	LikeCount    int64       `json:"likeCount"`
+	CommentCount int64       `json:"commentCount"`
+	PlayCount    int64       `json:"playCount"`
+	BigIdea      string      `json:"bigIdea"`
+	ViralScore   int         `json:"viralScore"`
+	Verdict      PostVerdict `json:"verdict"`
+
+	// Baseline is the latest known average of the channel, nil when the channel is unknown.
+	Baseline *ChannelBaseline `json:"baseline,omitempty"`
+	// lifts are the ratios of the post counts to the channel averages
+	LikesLift    *float64 `json:"likesLift,omitempty"`
+	CommentsLift *float64 `json:"commentsLift,omitempty"`
+	PlaysLift    *float64 `json:"playsLift,omitempty"`
+}
+
+type ChannelBaseline struct {
+	Followers       int64 `json:"followers"`
+	AverageLikes    int64 `json:"averageLikes"`
+	AverageComments int64 `json:"averageComments"`
+	AveragePlays    int64 `json:"averagePlays"`
+}
+
+// ComparedMetric aligns the score of a metric across the compared posts, in the order of the posts.
+// A nil score means the post has no such metric.
+type ComparedMetric struct {
+	Key    MetricKey `json:"key"`
+	Scores []*int    `json:"scores"`
+}
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LlmUsage holds the tokens consumed by an LLM call and its computed cost in USD.
+type LlmUsage struct {
+	LlmModel      string  `json:"llmModel"`
+	PromptTokens  int     `json:"promptTokens"`
+	VideoTokens   int     `json:"videoTokens"`
+	AudioTokens   int     `json:"audioTokens"`
+	OutputTokens  int     `json:"outputTokens"`
+	TotalTokens   int     `json:"totalTokens"`
+	SearchQueries int     `json:"searchQueries"`
+	LlmCost       float64 `json:"llmCost"`
+}
+
+// LlmCostSummary is a row of the aggregated LLM spend, grouped by day, user and platform.
+type LlmCostSummary struct {
+	Day           time.Time      `json:"day"`
+	UserId        *uuid.UUID     `json:"userId"`
+	Platform      SocialPlatform `json:"platform"`
+	Requests      int64          `json:"requests"`
+	PromptTokens  int64          `json:"promptTokens"`
+	VideoTokens   int64          `json:"videoTokens"`
+	AudioTokens   int64          `json:"audioTokens"`
+	OutputTokens  int64          `json:"outputTokens"`
+	TotalTokens   int64          `json:"totalTokens"`
+	SearchQueries int64          `json:"searchQueries"`
+	Cost          float64        `json:"cost"`
+}
@@ -4,6 +4,8 @@ type StreamEventType string
 
 const (
 	StreamEventTypeResponse = "response"
+	StreamEventTypeError    = "error"
+	StreamEventTypeDone     = "done"
 )
 
 type StreamedMessage struct {
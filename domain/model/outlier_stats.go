@@ -0,0 +1,22 @@
+package model
+
+// OutlierStats measures a post against the recent reels of its channel at analysis time.
+type OutlierStats struct {
+	SampleSize int          `json:"sampleSize"`
+	Views      *OutlierStat `json:"views,omitempty"`
+	Likes      *OutlierStat `json:"likes,omitempty"`
+	Comments   *OutlierStat `json:"comments,omitempty"`
+}
+
+// OutlierStat places a count of the post within the distribution of the channel reels.
+type OutlierStat struct {
+	Value  int64   `json:"value"`
+	Median float64 `json:"median"`
+	Mean   float64 `json:"mean"`
+	StdDev float64 `json:"stdDev"`
+	// Percentile is the share of the channel reels the post did better than, from 0 to 100.
+	Percentile float64 `json:"percentile"`
+	ZScore     float64 `json:"zScore"`
+	// MedianMultiple is the count divided by the channel median, e.g. 4.2 for 4.2x the median.
+	MedianMultiple float64 `json:"medianMultiple"`
+}
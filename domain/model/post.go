@@ -16,6 +16,15 @@ const (
 	PostFormatSound PostFormat = "sound"
 )
 
+// SimilarPostsScope restricts the posts considered by a similarity lookup.
+type SimilarPostsScope string
+
+const (
+	SimilarScopeAll           SimilarPostsScope = "all"
+	SimilarScopeLibrary       SimilarPostsScope = "library"
+	SimilarScopeOtherChannels SimilarPostsScope = "other_channels"
+)
+
 type Post struct {
 	ID        string         `json:"id" gorm:"primaryKey"`
 	ChannelId *uuid.UUID     `json:"channelId" gorm:"type:uuid"`
@@ -45,3 +54,19 @@ type Post struct {
 func (*Post) TableName() string {
 	return "posts"
 }
+
+// LibraryPost is a post analyzed by a user, along with the highlights of its analysis.
+type LibraryPost struct {
+	RequestId      uuid.UUID
+	AnalyzedAt     time.Time
+	PostId         string
+	Link           string
+	ImageUrl       *string
+	UserAnchor     string
+	PostDate       time.Time
+	LikeCount      int64
+	CommentCount   int64
+	VideoPlayCount int64
+	ViralScore     int
+	BigIdea        string
+}
@@ -10,13 +10,15 @@ type PostAnalysis struct {
 	ID     uuid.UUID `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
 	PostId string    `json:"postId" `
 
-	ViralScore        int         `json:"viralScore"`
-	BigIdea           string      `json:"bigIdea"`
-	WhyViral          string      `json:"whyViral"`
-	AudienceSentiment string      `json:"audienceSentiment"`
-	SentimentScore    int         `json:"sentimentScore"`
-	Verdict           PostVerdict `json:"verdict" gorm:"serializer:json"`
+	ViralScore          int                  `json:"viralScore"`
+	ViralScoreBreakdown *ViralScoreBreakdown `json:"viralScoreBreakdown" gorm:"serializer:json"`
+	BigIdea             string               `json:"bigIdea"`
+	WhyViral            string               `json:"whyViral"`
+	AudienceSentiment   string               `json:"audienceSentiment"`
+	SentimentScore      int                  `json:"sentimentScore"`
+	Verdict             PostVerdict          `json:"verdict" gorm:"serializer:json"`
 
+	Scope      PostAnalysisScope    `json:"scope" gorm:"serializer:json"`
 	Metrics    []PostAnalysisMetric `json:"metrics" gorm:"serializer:json"`
 	Strengths  []string             `json:"strengths" gorm:"serializer:json"`
 	Weaknesses []string             `json:"weaknesses" gorm:"serializer:json"`
@@ -27,6 +29,12 @@ type PostAnalysis struct {
 	Captions PostAnalysisCaptions `json:"captions" gorm:"serializer:json"`
 	Hashtags []string             `json:"hashtags" gorm:"serializer:json"`
 
+	// OutlierStats compares the post to the recent reels of its channel, it is returned next to the analysis.
+	OutlierStats *OutlierStats `json:"-" gorm:"serializer:json"`
+
+	// Sections lists the analysis sections generated so far, the analysis is complete once all of them are present.
+	Sections []string `json:"sections" gorm:"serializer:json"`
+
 	CreatedAt time.Time `json:"-"`
 	UpdatedAt time.Time `json:"-"`
 
@@ -34,10 +42,17 @@ type PostAnalysis struct {
 }
 
 type PostAnalysisMetric struct {
-	Label       string `json:"label"`
-	Score       int    `json:"score"`
-	Explanation string `json:"explanation"`
-	Suggestion  string `json:"suggestion"`
+	Key         MetricKey `json:"key"`
+	Label       string    `json:"label"`
+	Score       int       `json:"score"`
+	Explanation string    `json:"explanation"`
+	Suggestion  string    `json:"suggestion"`
+}
+
+// PostAnalysisScope is the reach of the topic of the post as estimated by the model.
+type PostAnalysisScope struct {
+	Level      string `json:"level"`
+	Confidence int    `json:"confidence"`
 }
 
 type PostAnalysisCaptions struct {
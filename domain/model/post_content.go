@@ -14,6 +14,7 @@ type PostContentType string
 const (
 	ContentCaption    PostContentType = "caption"
 	ContentTranscript PostContentType = "transcript"
+	ContentBigIdea    PostContentType = "big_idea"
 )
 
 type PostContent struct {
@@ -34,6 +35,13 @@ type PostContent struct {
 	Post *Post `json:"post" gorm:"foreignKey:PostID;references:ID"`
 }
 
+// PostContentMatch is a post content matched by a semantic search.
+type PostContentMatch struct {
+	ContentId  uuid.UUID
+	PostId     string
+	Similarity float64
+}
+
 func (*PostContent) TableName() string {
 	return "post_contents"
 }
@@ -4,10 +4,19 @@ type PostContentMetadata interface {
 	MetadataType() PostContentType
 }
 
+// TranscriptSource is the engine that produced a transcript.
+type TranscriptSource string
+
+const (
+	TranscriptSourceGemini  TranscriptSource = "gemini"
+	TranscriptSourceWhisper TranscriptSource = "whisper"
+)
+
 type SegmentPostContentMetadata struct {
-	Timestamp string `json:"timestamp"`
-	Speaker   string `json:"speaker"`
-	Emotion   string `json:"emotion"`
+	Timestamp string           `json:"timestamp"`
+	Speaker   string           `json:"speaker"`
+	Emotion   string           `json:"emotion"`
+	Source    TranscriptSource `json:"source,omitempty"`
 }
 
 func (s *SegmentPostContentMetadata) MetadataType() PostContentType {
@@ -0,0 +1,67 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SnapshotStatus string
+
+const (
+	SnapshotStatusPending SnapshotStatus = "pending"
+	SnapshotStatusQueued  SnapshotStatus = "queued"
+	SnapshotStatusDone    SnapshotStatus = "done"
+	SnapshotStatusFailed  SnapshotStatus = "failed"
+)
+
+// SnapshotDayOffsets are the days after the analysis at which the stats of a post are taken again.
+// The snapshot of day 0 is the baseline taken at analysis time.
+var SnapshotDayOffsets = []int{1, 7, 30}
+
+// PostStatSnapshot holds the counts of a post a number of days after it was analyzed,
+// so the predicted viral score can be compared against how the post actually performed.
+type PostStatSnapshot struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	PostId    string    `json:"postId"`
+	DayOffset int       `json:"dayOffset"`
+	// ViralScore is the score predicted when the snapshots were scheduled.
+	ViralScore int            `json:"viralScore"`
+	Status     SnapshotStatus `json:"status"`
+	Attempts   int            `json:"attempts"`
+	DueAt      time.Time      `json:"dueAt"`
+	TakenAt    *time.Time     `json:"takenAt"`
+
+	LikeCount    int64 `json:"likeCount"`
+	CommentCount int64 `json:"commentCount"`
+	PlayCount    int64 `json:"playCount"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (*PostStatSnapshot) TableName() string {
+	return "post_stat_snapshots"
+}
+
+// CalibrationBucket aggregates the growth of posts whose predicted viral score falls in a range.
+type CalibrationBucket struct {
+	DayOffset int `json:"dayOffset"`
+	MinScore  int `json:"minScore"`
+	MaxScore  int `json:"maxScore"`
+	Posts     int `json:"posts"`
+	// ViewGrowth is the plays at DayOffset divided by the plays at analysis time.
+	AvgViewGrowth    *float64 `json:"avgViewGrowth"`
+	MedianViewGrowth *float64 `json:"medianViewGrowth"`
+	// OutlierMultiple is the plays at DayOffset divided by the channel median at analysis time.
+	AvgOutlierMultiple    *float64 `json:"avgOutlierMultiple"`
+	MedianOutlierMultiple *float64 `json:"medianOutlierMultiple"`
+}
+
+// CalibrationCorrelation is the correlation of the predicted score with the growth of the posts at a day offset.
+type CalibrationCorrelation struct {
+	DayOffset       int      `json:"dayOffset"`
+	Posts           int      `json:"posts"`
+	ViewGrowth      *float64 `json:"viewGrowth"`
+	OutlierMultiple *float64 `json:"outlierMultiple"`
+}
@@ -8,3 +8,8 @@ type PostQueueData struct {
 	Url      string         `json:"url"`
 	Platform SocialPlatform `json:"platform"`
 }
+
+// SnapshotQueueData is the payload of a due snapshot pushed to the snapshot queue.
+type SnapshotQueueData struct {
+	Id uuid.UUID `json:"id"`
+}
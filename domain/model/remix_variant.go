@@ -0,0 +1,38 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RemixSection is the part of the remix kit a variant was generated for.
+type RemixSection string
+
+const (
+	RemixSectionHooks    RemixSection = "hooks"
+	RemixSectionScripts  RemixSection = "scripts"
+	RemixSectionCaptions RemixSection = "captions"
+	RemixSectionHashtags RemixSection = "hashtags"
+)
+
+// RemixVariant is a hook, script, caption or hashtag set generated on demand for a post.
+// Variants generated by the same request share a BatchId.
+type RemixVariant struct {
+	ID           uuid.UUID    `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserId       uuid.UUID    `json:"-" gorm:"type:uuid"`
+	PostId       string       `json:"postId"`
+	BatchId      uuid.UUID    `json:"batchId" gorm:"type:uuid"`
+	Section      RemixSection `json:"section"`
+	Tone         string       `json:"tone"`
+	TargetLength string       `json:"targetLength"`
+	Language     string       `json:"language"`
+	Content      string       `json:"content"`
+	Favorite     bool         `json:"favorite"`
+	CreatedAt    time.Time    `json:"createdAt"`
+	UpdatedAt    time.Time    `json:"updatedAt"`
+}
+
+func (*RemixVariant) TableName() string {
+	return "remix_variants"
+}
@@ -12,6 +12,15 @@ const (
 	ProviderLocal    Provider = "local"
 	ProviderGoogle   Provider = "google"
 	ProviderFacebook Provider = "facebook"
+	ProviderOidc     Provider = "oidc"
+)
+
+// UserRole grants the permissions of the operational endpoints, most users have UserRoleUser.
+type UserRole string
+
+const (
+	UserRoleUser  UserRole = "user"
+	UserRoleAdmin UserRole = "admin"
 )
 
 type User struct {
@@ -19,14 +28,23 @@ type User struct {
 	Email           string    `json:"email"`
 	Password        *string   `json:"-"`
 	Name            *string   `json:"name"`
-	Provider        Provider  `json:"-"`
-	ProviderID      *string   `json:"-"` // User's ID from the provider
+	Provider        Provider  `json:"-"` // the provider the account was created with, see UserIdentity for the linked ones
 	ProfileImage    *string   `json:"profileImage"`
 	DefaultLanguage Language  `json:"defaultLanguage"`
+	Role            UserRole  `json:"role" gorm:"default:user"`
 
 	VerifiedAt *time.Time `json:"verifiedAt,omitempty"`
-	CreatedAt  time.Time  `json:"-"`
-	UpdatedAt  time.Time  `json:"-"`
+	// SessionsRevokedAt invalidates the tokens issued before it, e.g. after a password change.
+	SessionsRevokedAt *time.Time `json:"-"`
+	// TotpSecret is set when two-factor authentication is being set up, it is only enforced once TotpEnabledAt is set.
+	TotpSecret    *string    `json:"-"`
+	TotpEnabledAt *time.Time `json:"totpEnabledAt,omitempty"`
+	// TotpLastStep is the time step of the last accepted code, so a code cannot be used twice.
+	TotpLastStep int64 `json:"-"`
+	// DeletionScheduledAt is when the account is purged, signing in before it cancels the deletion.
+	DeletionScheduledAt *time.Time `json:"deletionScheduledAt,omitempty"`
+	CreatedAt           time.Time  `json:"-"`
+	UpdatedAt           time.Time  `json:"-"`
 }
 
 func (*User) TableName() string {
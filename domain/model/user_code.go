@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CodePurpose string
+
+const (
+	CodePurposeEmailVerification CodePurpose = "email_verification"
+	CodePurposePasswordReset     CodePurpose = "password_reset"
+	// CodePurposeTwoFactorChallenge is the token of a sign-in waiting for the second factor.
+	CodePurposeTwoFactorChallenge CodePurpose = "two_factor_challenge"
+	// CodePurposeTwoFactorAttempts counts the second factor codes a signed-in user enters, it holds no code.
+	CodePurposeTwoFactorAttempts CodePurpose = "two_factor_attempts"
+	// CodePurposeAccountDeletion confirms the deletion of an account without a password or two-factor authentication.
+	CodePurposeAccountDeletion CodePurpose = "account_deletion"
+)
+
+// UserCode is a one-time code sent to a user. Only the HMAC of the code is stored.
+type UserCode struct {
+	ID         uuid.UUID   `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserId     uuid.UUID   `json:"userId" gorm:"type:uuid"`
+	Purpose    CodePurpose `json:"purpose"`
+	CodeHash   string      `json:"-"`
+	Attempts   int         `json:"attempts"`
+	ExpiresAt  time.Time   `json:"expiresAt"`
+	ConsumedAt *time.Time  `json:"consumedAt"`
+	CreatedAt  time.Time   `json:"createdAt"`
+}
+
+func (*UserCode) TableName() string {
+	return "user_codes"
+}
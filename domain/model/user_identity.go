@@ -0,0 +1,22 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserIdentity links a user to an account of an OAuth provider, so one user can sign in with several of them.
+type UserIdentity struct {
+	ID         uuid.UUID `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserId     uuid.UUID `json:"-" gorm:"type:uuid"`
+	Provider   Provider  `json:"provider"`
+	ProviderId string    `json:"-"`
+	// Email is the address of the provider account, which can differ from the email of the user.
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func (*UserIdentity) TableName() string {
+	return "user_identities"
+}
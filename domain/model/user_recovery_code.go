@@ -0,0 +1,20 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserRecoveryCode is a single-use code that replaces the authenticator app of a user. Only the HMAC of the code is stored.
+type UserRecoveryCode struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserId    uuid.UUID  `json:"-" gorm:"type:uuid"`
+	CodeHash  string     `json:"-"`
+	UsedAt    *time.Time `json:"usedAt"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+func (*UserRecoveryCode) TableName() string {
+	return "user_recovery_codes"
+}
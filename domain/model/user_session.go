@@ -0,0 +1,48 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserSession is a signed in device of a user. It is kept alive by rotating its refresh token.
+type UserSession struct {
+	ID         uuid.UUID  `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserId     uuid.UUID  `json:"-" gorm:"type:uuid"`
+	UserAgent  string     `json:"userAgent"`
+	Ip         *string    `json:"ip" gorm:"type:inet"`
+	ExpiresAt  time.Time  `json:"expiresAt"`
+	RevokedAt  *time.Time `json:"-"`
+	LastUsedAt time.Time  `json:"lastUsedAt"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+func (*UserSession) TableName() string {
+	return "user_sessions"
+}
+
+// Active reports whether the session can still be used.
+func (s *UserSession) Active() bool {
+	return s.RevokedAt == nil && time.Now().Before(s.ExpiresAt)
+}
+
+// RefreshToken is a single-use token of a session. Only its hash is stored, and a used token
+// is kept so presenting it again can be detected as a reuse.
+type RefreshToken struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	SessionId uuid.UUID  `json:"sessionId" gorm:"type:uuid"`
+	TokenHash string     `json:"-"`
+	UsedAt    *time.Time `json:"usedAt"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+func (*RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
+
+// SessionDevice describes the client a session is created for.
+type SessionDevice struct {
+	UserAgent string
+	Ip        string
+}
@@ -0,0 +1,88 @@
+package model
+
+import "strings"
+
+// MetricKey identifies a metric of the analysis that takes part in the viral score.
+type MetricKey string
+
+const (
+	MetricTopic        MetricKey = "topic"
+	MetricHook         MetricKey = "hook"
+	MetricPacing       MetricKey = "pacing"
+	MetricValue        MetricKey = "value"
+	MetricShareability MetricKey = "shareability"
+	MetricCta          MetricKey = "cta"
+)
+
+// Adjustments applied on top of the weighted metrics.
+const (
+	AdjustmentGate       = "gate"
+	AdjustmentScope      = "scope"
+	AdjustmentCtaPenalty = "cta_penalty"
+)
+
+// ViralScoreBreakdown explains how the viral score of an analysis was computed.
+type ViralScoreBreakdown struct {
+	Version     string                 `json:"version"`
+	Components  []ViralScoreComponent  `json:"components"`
+	BaseScore   float64                `json:"baseScore"`
+	Adjustments []ViralScoreAdjustment `json:"adjustments"`
+	Score       float64                `json:"score"`
+}
+
+// ViralScoreComponent is the weighted contribution of a metric to the base score.
+type ViralScoreComponent struct {
+	Key          MetricKey `json:"key"`
+	Score        int       `json:"score"`
+	Weight       float64   `json:"weight"`
+	Contribution float64   `json:"contribution"`
+}
+
+// ViralScoreAdjustment is a gate or penalty that fired and multiplied the score.
+type ViralScoreAdjustment struct {
+	Name       string  `json:"name"`
+	Multiplier float64 `json:"multiplier"`
+	Reason     string  `json:"reason"`
+}
+
+// MetricKeys lists the metrics every analysis scores, in the order they are presented.
+var MetricKeys = []MetricKey{MetricHook, MetricTopic, MetricPacing, MetricValue, MetricShareability, MetricCta}
+
+// MetricKeyValues returns the metric keys as strings, e.g. for a response schema enum.
+func MetricKeyValues() []string {
+	values := make([]string, len(MetricKeys))
+	for i, key := range MetricKeys {
+		values[i] = string(key)
+	}
+	return values
+}
+
+// metricLabelKeys maps the labels metrics were known by before they carried a key.
+var metricLabelKeys = map[string]MetricKey{
+	"hook":                 MetricHook,
+	"hook strength":        MetricHook,
+	"topic":                MetricTopic,
+	"topic potential":      MetricTopic,
+	"pacing":               MetricPacing,
+	"value":                MetricValue,
+	"value delivery":       MetricValue,
+	"share":                MetricShareability,
+	"shareability":         MetricShareability,
+	"cta":                  MetricCta,
+	"call to action":       MetricCta,
+	"call to action (cta)": MetricCta,
+}
+
+// ResolveMetricKey returns the key of a metric. A missing or unknown key is repaired from
+// the label when it is one of the known english labels, anything else is rejected.
+func ResolveMetricKey(key, label string) (MetricKey, bool) {
+	normalized := MetricKey(strings.ToLower(strings.TrimSpace(key)))
+	for _, k := range MetricKeys {
+		if normalized == k {
+			return k, true
+		}
+	}
+
+	k, ok := metricLabelKeys[strings.ToLower(strings.TrimSpace(label))]
+	return k, ok
+}
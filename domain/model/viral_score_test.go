@@ -0,0 +1,26 @@
+package model
+
+import "testing"
+
+func TestResolveMetricKey(t *testing.T) {
+	tests := []struct {
+		key, label string
+		want       MetricKey
+		ok         bool
+	}{
+		{"hook", "قلاب", MetricHook, true},
+		{" Shareability ", "", MetricShareability, true},
+		{"", "Topic Potential", MetricTopic, true},
+		{"", "Call to Action", MetricCta, true},
+		// labels are no longer matched by substring
+		{"", "Topic Hook", "", false},
+		{"virality", "Virality", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := ResolveMetricKey(tt.key, tt.label)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ResolveMetricKey(%q, %q) = %q, %v; want %q, %v", tt.key, tt.label, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WorkspaceRole is what a member can do in a workspace, each role includes the ones below it.
+type WorkspaceRole string
+
+const (
+	WorkspaceRoleOwner  WorkspaceRole = "owner"
+	WorkspaceRoleEditor WorkspaceRole = "editor"
+	WorkspaceRoleViewer WorkspaceRole = "viewer"
+)
+
+var workspaceRoleRanks = map[WorkspaceRole]int{
+	WorkspaceRoleViewer: 1,
+	WorkspaceRoleEditor: 2,
+	WorkspaceRoleOwner:  3,
+}
+
+// Allows reports whether the role includes the required one.
+func (r WorkspaceRole) Allows(required WorkspaceRole) bool {
+	return workspaceRoleRanks[r] >= workspaceRoleRanks[required]
+}
+
+// Workspace is a team sharing a library of analyzed posts.
+type Workspace struct {
+	ID   uuid.UUID `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	Name string    `json:"name"`
+	// RequireTwoFactor keeps the members without two-factor authentication out of the workspace.
+	RequireTwoFactor bool       `json:"requireTwoFactor"`
+	CreatedBy        *uuid.UUID `json:"-" gorm:"type:uuid"`
+	CreatedAt        time.Time  `json:"createdAt"`
+	UpdatedAt        time.Time  `json:"updatedAt"`
+}
+
+func (*Workspace) TableName() string {
+	return "workspaces"
+}
+
+type WorkspaceMember struct {
+	WorkspaceId uuid.UUID     `json:"workspaceId" gorm:"type:uuid;primaryKey"`
+	UserId      uuid.UUID     `json:"userId" gorm:"type:uuid;primaryKey"`
+	Role        WorkspaceRole `json:"role"`
+	CreatedAt   time.Time     `json:"createdAt"`
+
+	Workspace *Workspace `json:"workspace,omitempty" gorm:"foreignKey:WorkspaceId;references:ID"`
+	User      *User      `json:"user,omitempty" gorm:"foreignKey:UserId;references:ID"`
+}
+
+func (*WorkspaceMember) TableName() string {
+	return "workspace_members"
+}
+
+// WorkspaceInvitation is sent by email, only the hash of its token is stored.
+type WorkspaceInvitation struct {
+	ID          uuid.UUID     `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	WorkspaceId uuid.UUID     `json:"workspaceId" gorm:"type:uuid"`
+	Email       string        `json:"email"`
+	Role        WorkspaceRole `json:"role"`
+	TokenHash   string        `json:"-"`
+	InvitedBy   *uuid.UUID    `json:"-" gorm:"type:uuid"`
+	ExpiresAt   time.Time     `json:"expiresAt"`
+	AcceptedAt  *time.Time    `json:"acceptedAt"`
+	CreatedAt   time.Time     `json:"createdAt"`
+}
+
+func (*WorkspaceInvitation) TableName() string {
+	return "workspace_invitations"
+}
+
+// LibraryOwner selects the analyzed posts of a workspace, or the personal ones of a user when WorkspaceId is nil.
+type LibraryOwner struct {
+	UserId      uuid.UUID
+	WorkspaceId *uuid.UUID
+}
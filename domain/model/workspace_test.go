@@ -0,0 +1,24 @@
+package model
+
+import "testing"
+
+func TestWorkspaceRoleAllows(t *testing.T) {
+	tests := []struct {
+		role, required WorkspaceRole
+		want           bool
+	}{
+		{WorkspaceRoleOwner, WorkspaceRoleViewer, true},
+		{WorkspaceRoleOwner, WorkspaceRoleOwner, true},
+		{WorkspaceRoleEditor, WorkspaceRoleEditor, true},
+		{WorkspaceRoleEditor, WorkspaceRoleOwner, false},
+		{WorkspaceRoleViewer, WorkspaceRoleEditor, false},
+		// unknown roles allow nothing
+		{"admin", WorkspaceRoleViewer, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.role.Allows(tt.required); got != tt.want {
+			t.Errorf("%q.Allows(%q) = %v; want %v", tt.role, tt.required, got, tt.want)
+		}
+	}
+}
@@ -16,4 +16,7 @@ const (
 	RedisKeyPrefix      = "cliplab:"
 	RedisPostQueue      = RedisKeyPrefix + "post_queue"
 	RedisPostRenewQueue = RedisKeyPrefix + "post_renew_queue"
+	RedisSnapshotQueue  = RedisKeyPrefix + "snapshot_queue"
+	// RedisApiKeyRate is followed by the key id and the minute of the request.
+	RedisApiKeyRate = RedisKeyPrefix + "api_key_rate:"
 )
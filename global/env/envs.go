@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/pkg/errors"
@@ -29,6 +30,31 @@ type Envs struct {
 		ApiHostAddr     string `env:"API_HOST, required"`
 	}
 
+	// Auth sets the lifetime of the access tokens and of the sessions kept alive by their refresh tokens.
+	Auth struct {
+		AccessTokenTtl  time.Duration `env:"AUTH_ACCESS_TOKEN_TTL, default=15m"`
+		RefreshTokenTtl time.Duration `env:"AUTH_REFRESH_TOKEN_TTL, default=720h"`
+	}
+
+	// ApiKey limits the api keys of a user, the rate limit is the default number of requests per minute of a key.
+	ApiKey struct {
+		RateLimit  int `env:"API_KEY_RATE_LIMIT, default=60"`
+		MaxPerUser int `env:"API_KEY_MAX_PER_USER, default=10"`
+	}
+
+	// Account configures the deletion of the accounts, they are purged once the grace period is over.
+	Account struct {
+		DeletionGracePeriod time.Duration `env:"ACCOUNT_DELETION_GRACE_PERIOD, default=720h"`
+		PurgeInterval       time.Duration `env:"ACCOUNT_PURGE_INTERVAL, default=1h"`
+	}
+
+	// Workspace configures the invitations to workspaces.
+	// The invitation token is appended to WORKSPACE_INVITE_URL as the "token" query parameter.
+	Workspace struct {
+		InviteUrl string        `env:"WORKSPACE_INVITE_URL, default=http://localhost:3000/workspaces/join"`
+		InviteTtl time.Duration `env:"WORKSPACE_INVITE_TTL, default=168h"`
+	}
+
 	Db struct {
 		LogLevel string `env:"LOG_LEVEL, default=error"`
 		Dsn      string `env:"DSN, required"`
@@ -37,6 +63,51 @@ type Envs struct {
 	Gemini struct {
 		ClientHost string `env:"GEMINI_HOST, required"`
 		Token      string `env:"GEMINI_TOKEN, required"`
+		// Streaming enables streamGenerateContent, so analysis sections are persisted and pushed as soon as they are generated.
+		Streaming bool `env:"GEMINI_STREAMING, default=false"`
+	}
+
+	// Gpt is the OpenAI compatible api used for embeddings, leave the token empty to disable them.
+	Gpt struct {
+		ClientHost string `env:"GPT_HOST, default=https://api.openai.com/v1"`
+		Token      string `env:"GPT_TOKEN"`
+	}
+
+	// LlmPricing is the price table used to compute the cost of LLM calls, in USD.
+	LlmPricing struct {
+		PromptPerMTok  float64 `env:"LLM_PRICE_PROMPT_PER_MTOK, default=0.30"`
+		VideoPerMTok   float64 `env:"LLM_PRICE_VIDEO_PER_MTOK, default=0.30"`
+		AudioPerMTok   float64 `env:"LLM_PRICE_AUDIO_PER_MTOK, default=1.00"`
+		OutputPerMTok  float64 `env:"LLM_PRICE_OUTPUT_PER_MTOK, default=2.50"`
+		GroundingPer1K float64 `env:"LLM_PRICE_GROUNDING_PER_1K, default=35"`
+	}
+
+	// Scoring holds the weights, gates and penalties of the viral score formula.
+	// Run the rescore command after changing them to update the stored analyses.
+	Scoring struct {
+		Version              string  `env:"SCORING_VERSION, default=v1"`
+		TopicWeight          float64 `env:"SCORING_TOPIC_WEIGHT, default=0.2"`
+		HookWeight           float64 `env:"SCORING_HOOK_WEIGHT, default=0.25"`
+		PacingWeight         float64 `env:"SCORING_PACING_WEIGHT, default=0.15"`
+		ValueWeight          float64 `env:"SCORING_VALUE_WEIGHT, default=0.15"`
+		ShareabilityWeight   float64 `env:"SCORING_SHAREABILITY_WEIGHT, default=0.15"`
+		CtaWeight            float64 `env:"SCORING_CTA_WEIGHT, default=0.1"`
+		GateThreshold        int     `env:"SCORING_GATE_THRESHOLD, default=60"`
+		GateMultiplier       float64 `env:"SCORING_GATE_MULTIPLIER, default=0.6"`
+		ScopeMinConfidence   int     `env:"SCORING_SCOPE_MIN_CONFIDENCE, default=70"`
+		LocalMultiplier      float64 `env:"SCORING_LOCAL_MULTIPLIER, default=0.75"`
+		NationalMultiplier   float64 `env:"SCORING_NATIONAL_MULTIPLIER, default=0.9"`
+		GlobalMultiplier     float64 `env:"SCORING_GLOBAL_MULTIPLIER, default=1.0"`
+		CtaPenaltyCtaAbove   int     `env:"SCORING_CTA_PENALTY_CTA_ABOVE, default=90"`
+		CtaPenaltyShareBelow int     `env:"SCORING_CTA_PENALTY_SHARE_BELOW, default=70"`
+		CtaPenaltyMultiplier float64 `env:"SCORING_CTA_PENALTY_MULTIPLIER, default=0.85"`
+	}
+
+	// Calibration schedules the stat snapshots that compare the viral score with later performance.
+	Calibration struct {
+		SnapshotInterval  time.Duration `env:"CALIBRATION_SNAPSHOT_INTERVAL, default=10m"`
+		SnapshotBatchSize int           `env:"CALIBRATION_SNAPSHOT_BATCH_SIZE, default=100"`
+		BucketSize        int           `env:"CALIBRATION_BUCKET_SIZE, default=20"`
 	}
 
 	RapidApi struct {
@@ -51,6 +122,45 @@ type Envs struct {
 		ClientID string `env:"GOOGLE_CLIENT_ID"`
 	}
 
+	// Facebook is the app whose user access tokens are accepted for login, leave it empty to disable Facebook login.
+	Facebook struct {
+		AppID     string `env:"FACEBOOK_APP_ID"`
+		AppSecret string `env:"FACEBOOK_APP_SECRET"`
+	}
+
+	// Oidc is the OpenID Connect provider used for single sign-on, leave the issuer empty to disable it.
+	// The redirect url is the page of the frontend that posts the code and state back to the api.
+	Oidc struct {
+		Issuer       string `env:"OIDC_ISSUER"`
+		ClientID     string `env:"OIDC_CLIENT_ID"`
+		ClientSecret string `env:"OIDC_CLIENT_SECRET"`
+		RedirectUrl  string `env:"OIDC_REDIRECT_URL, default=http://localhost:3000/sso/callback"`
+		Scopes       string `env:"OIDC_SCOPES, default=openid email profile"`
+	}
+
+	// Mail selects how emails are sent: "smtp", or "log" to only log them and optionally write them to MAIL_LOG_DIR.
+	Mail struct {
+		Driver       string `env:"MAIL_DRIVER, default=log"`
+		From         string `env:"MAIL_FROM, default=ClipLab <no-reply@cliplab.app>"`
+		LogDir       string `env:"MAIL_LOG_DIR"`
+		SmtpHost     string `env:"SMTP_HOST"`
+		SmtpPort     int    `env:"SMTP_PORT, default=587"`
+		SmtpUsername string `env:"SMTP_USERNAME"`
+		SmtpPassword string `env:"SMTP_PASSWORD"`
+	}
+
+	// Otp configures the one-time codes and password reset links sent by email.
+	// The reset token is appended to PASSWORD_RESET_URL as the "token" query parameter.
+	Otp struct {
+		Length           int           `env:"OTP_LENGTH, default=6"`
+		Ttl              time.Duration `env:"OTP_TTL, default=15m"`
+		MaxAttempts      int           `env:"OTP_MAX_ATTEMPTS, default=5"`
+		ResendInterval   time.Duration `env:"OTP_RESEND_INTERVAL, default=1m"`
+		MaxPerHour       int           `env:"OTP_MAX_PER_HOUR, default=5"`
+		PasswordResetUrl string        `env:"PASSWORD_RESET_URL, default=http://localhost:3000/reset-password"`
+		PasswordResetTtl time.Duration `env:"PASSWORD_RESET_TTL, default=1h"`
+	}
+
 	Redis struct {
 		Address  string `env:"REDIS_ADDRESS, default=localhost:6379"`
 		Password string `env:"REDIS_PASSWORD"`
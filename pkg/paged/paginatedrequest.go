@@ -6,8 +6,8 @@ import (
 )
 
 type PaginatedRequest struct {
-	Page int    `form:"page,default=0"`
-	Size int    `form:"size,default=20"`
+	Page int    `form:"page,default=0" binding:"min=0"`
+	Size int    `form:"size,default=20" binding:"min=1,max=100"`
 	Sort string `form:"sort"`
 }
 
@@ -45,6 +45,21 @@ func TestPaginatedRequestBinding(t *testing.T) {
 			queryString:   "page=0&size=invalid",
 			expectedError: true,
 		},
+		{
+			name:          "Negative page",
+			queryString:   "page=-1&size=10",
+			expectedError: true,
+		},
+		{
+			name:          "Zero size",
+			queryString:   "page=0&size=0",
+			expectedError: true,
+		},
+		{
+			name:          "Size over the limit",
+			queryString:   "page=0&size=101",
+			expectedError: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/amahdian/cliplab-be/domain/contracts/resp"
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/amahdian/cliplab-be/svc/auth"
+	"github.com/gin-gonic/gin"
+)
+
+// RequireApiKeyScope rejects the requests authenticated with an api key that lacks the scope.
+// Routes without a scope are not available to api keys at all.
+func RequireApiKeyScope(scope model.ApiKeyScope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		apiKey := auth.UserInfoFromCtx(c).ApiKey
+		if apiKey == nil {
+			c.Next()
+			return
+		}
+		if scope == "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, resp.NewErrorResponse(fmt.Errorf("this endpoint is not available to api keys")))
+			return
+		}
+		if !apiKey.HasScope(scope) {
+			c.AbortWithStatusJSON(http.StatusForbidden, resp.NewErrorResponse(fmt.Errorf("api key is missing the %s scope", scope)))
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/amahdian/cliplab-be/svc/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestRequireApiKeyScope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	apiKey := &model.ApiKey{Scopes: []model.ApiKeyScope{model.ApiKeyScopeLibraryRead}}
+	cases := []struct {
+		name   string
+		apiKey *model.ApiKey
+		scope  model.ApiKeyScope
+		status int
+	}{
+		{"user token on a route without scope", nil, "", http.StatusOK},
+		{"user token on a scoped route", nil, model.ApiKeyScopeLibraryRead, http.StatusOK},
+		{"api key on a route without scope", apiKey, "", http.StatusForbidden},
+		{"api key missing the scope", apiKey, model.ApiKeyScopeAnalyzeWrite, http.StatusForbidden},
+		{"api key with the scope", apiKey, model.ApiKeyScopeLibraryRead, http.StatusOK},
+	}
+
+	for _, c := range cases {
+		router := gin.New()
+		authenticate := func(ctx *gin.Context) {
+			userInfo := auth.UserInfo{Id: uuid.New(), ApiKey: c.apiKey}
+			ctx.Request = ctx.Request.WithContext(auth.WithUserInfo(ctx.Request.Context(), userInfo))
+		}
+		router.GET("/", authenticate, RequireApiKeyScope(c.scope), func(ctx *gin.Context) {
+			ctx.Status(http.StatusOK)
+		})
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		if w.Code != c.status {
+			t.Errorf("%s: expected %d, got %d", c.name, c.status, w.Code)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/amahdian/cliplab-be/domain/contracts/resp"
+	"github.com/amahdian/cliplab-be/global/errs"
 	"github.com/amahdian/cliplab-be/svc/auth"
 
 	"github.com/gin-gonic/gin"
@@ -14,10 +15,37 @@ func VerifyAuth(authenticator auth.Authenticator) gin.HandlerFunc {
 		r := c.Request
 		ctx, err := authenticator.Verify(r)
 		if err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, resp.NewErrorResponse(err))
+			abortAuth(c, err)
 			return
 		}
 		c.Request = r.WithContext(ctx)
 		c.Next()
 	}
 }
+
+// VerifyOptionalAuth authenticates the request when credentials are provided and lets anonymous requests through.
+func VerifyOptionalAuth(authenticator auth.Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		r := c.Request
+		if r.Header.Get("Authorization") == "" && r.URL.Query().Get("authToken") == "" && r.Header.Get(auth.ApiKeyHeader) == "" {
+			c.Next()
+			return
+		}
+		ctx, err := authenticator.Verify(r)
+		if err != nil {
+			abortAuth(c, err)
+			return
+		}
+		c.Request = r.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// abortAuth responds 401 to a failed authentication, except when an api key ran out of requests.
+func abortAuth(c *gin.Context, err error) {
+	if errs.IsCode(err, errs.ResourceExhausted) {
+		resp.AbortWithError(c, err)
+		return
+	}
+	c.AbortWithStatusJSON(http.StatusUnauthorized, resp.NewErrorResponse(err))
+}
@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"github.com/amahdian/cliplab-be/domain/contracts/resp"
+	"github.com/amahdian/cliplab-be/svc/auth"
+	"github.com/gin-gonic/gin"
+)
+
+// WithUserRoles rejects the requests of the users whose role lacks any of the permissions of the route.
+func WithUserRoles(authorizer auth.Authorizer, permissions ...auth.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := authorizer.Authorize(auth.UserInfoFromCtx(c), permissions...); err != nil {
+			resp.AbortWithError(c, err)
+			return
+		}
+		c.Next()
+	}
+}
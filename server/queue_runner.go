@@ -3,6 +3,7 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"time"
 
 	"github.com/amahdian/cliplab-be/domain/model"
 	"github.com/amahdian/cliplab-be/global"
@@ -18,6 +19,7 @@ func runQueue(rdb *redis.Client, svc svc.Svc) {
 		res, err := rdb.BRPop(context.Background(), 0,
 			global.RedisPostQueue,
 			global.RedisPostRenewQueue,
+			global.RedisSnapshotQueue,
 		).Result()
 
 		if err != nil {
@@ -53,7 +55,59 @@ func handleMessage(channel, payload string, svc svc.Svc) {
 		if err != nil {
 			logger.Error("Failed to renew post:", err)
 		}
+	case global.RedisSnapshotQueue:
+		var data model.SnapshotQueueData
+		if err := json.Unmarshal([]byte(payload), &data); err != nil {
+			logger.Error("invalid queue payload:", err)
+			return
+		}
+		err := svc.NewSnapshotSvc(context.Background()).TakeSnapshot(data.Id)
+		if err != nil {
+			logger.Error("Failed to take snapshot:", err)
+		}
 	default:
 		logger.Error("Unhandled channel %s: %s", channel, payload)
 	}
 }
+
+// runAccountPurgeScheduler periodically deletes the accounts whose deletion grace period is over.
+func runAccountPurgeScheduler(interval time.Duration, svc svc.Svc) {
+	if interval <= 0 {
+		logger.Warnf("Account purge scheduler is disabled, its interval is %s", interval)
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		count, err := svc.NewUserSvc(context.Background()).PurgeDeletedAccounts()
+		if err != nil {
+			logger.Error("Failed to purge deleted accounts:", err)
+			continue
+		}
+		if count > 0 {
+			logger.Infof("Purged %d deleted accounts", count)
+		}
+	}
+}
+
+// runSnapshotScheduler periodically queues the stat snapshots that are due.
+func runSnapshotScheduler(interval time.Duration, svc svc.Svc) {
+	if interval <= 0 {
+		logger.Warnf("Snapshot scheduler is disabled, its interval is %s", interval)
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		count, err := svc.NewSnapshotSvc(context.Background()).EnqueueDue()
+		if err != nil {
+			logger.Error("Failed to queue due snapshots:", err)
+			continue
+		}
+		if count > 0 {
+			logger.Infof("Queued %d due snapshots", count)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package router
+
+import (
+	"github.com/amahdian/cliplab-be/domain/contracts/req"
+	"github.com/amahdian/cliplab-be/domain/contracts/resp"
+	"github.com/amahdian/cliplab-be/global/errs"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (r *Router) listApiKeys(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+	user := reqCtx.UserInfo
+
+	dSvc := r.svc.NewApiKeySvc(reqCtx.Ctx)
+	keys, err := dSvc.List(user.Id)
+	if err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Ok(ctx, keys)
+}
+
+func (r *Router) createApiKey(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+	user := reqCtx.UserInfo
+
+	request := &req.CreateApiKey{}
+	if err := ctx.ShouldBindJSON(request); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	dSvc := r.svc.NewApiKeySvc(reqCtx.Ctx)
+	key, err := dSvc.Create(user.Id, request)
+	if err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Created(ctx, key)
+}
+
+func (r *Router) revokeApiKey(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+	user := reqCtx.UserInfo
+
+	uri := &req.IdUri{}
+	if err := ctx.BindUri(uri); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+	id, err := uuid.Parse(uri.Id)
+	if err != nil {
+		resp.AbortWithError(ctx, errs.Newf(errs.InvalidArgument, err, "invalid api key id"))
+		return
+	}
+
+	dSvc := r.svc.NewApiKeySvc(reqCtx.Ctx)
+	if err = dSvc.Revoke(user.Id, id); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Ok(ctx, true)
+}
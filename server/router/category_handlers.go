@@ -0,0 +1,96 @@
+package router
+
+import (
+	"github.com/amahdian/cliplab-be/domain/contracts/req"
+	"github.com/amahdian/cliplab-be/domain/contracts/resp"
+	"github.com/amahdian/cliplab-be/global/errs"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (r *Router) listCategories(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+
+	dSvc := r.svc.NewCategorySvc(reqCtx.Ctx)
+	categories, err := dSvc.ListCategories()
+	if err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Ok(ctx, categories)
+}
+
+func (r *Router) createCategory(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+
+	request := &req.SaveCategory{}
+	if err := ctx.ShouldBindJSON(request); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	dSvc := r.svc.NewCategorySvc(reqCtx.Ctx)
+	category, err := dSvc.CreateCategory(request.Path)
+	if err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Ok(ctx, category)
+}
+
+func (r *Router) updateCategory(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+
+	id, ok := bindCategoryId(ctx)
+	if !ok {
+		return
+	}
+	request := &req.SaveCategory{}
+	if err := ctx.ShouldBindJSON(request); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	dSvc := r.svc.NewCategorySvc(reqCtx.Ctx)
+	category, err := dSvc.UpdateCategory(id, request.Path)
+	if err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Ok(ctx, category)
+}
+
+func (r *Router) deleteCategory(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+
+	id, ok := bindCategoryId(ctx)
+	if !ok {
+		return
+	}
+
+	dSvc := r.svc.NewCategorySvc(reqCtx.Ctx)
+	if err := dSvc.DeleteCategory(id); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Ok(ctx, true)
+}
+
+func bindCategoryId(ctx *gin.Context) (uuid.UUID, bool) {
+	uri := &req.IdUri{}
+	if err := ctx.BindUri(uri); err != nil {
+		resp.AbortWithError(ctx, err)
+		return uuid.Nil, false
+	}
+
+	id, err := uuid.Parse(uri.Id)
+	if err != nil {
+		resp.AbortWithError(ctx, errs.Newf(errs.InvalidArgument, err, "invalid category id"))
+		return uuid.Nil, false
+	}
+	return id, true
+}
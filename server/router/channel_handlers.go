@@ -0,0 +1,33 @@
+package router
+
+import (
+	"github.com/amahdian/cliplab-be/domain/contracts/req"
+	"github.com/amahdian/cliplab-be/domain/contracts/resp"
+	"github.com/amahdian/cliplab-be/global/errs"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (r *Router) getPostingTimes(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+
+	uri := &req.IdUri{}
+	if err := ctx.BindUri(uri); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+	id, err := uuid.Parse(uri.Id)
+	if err != nil {
+		resp.AbortWithError(ctx, errs.Newf(errs.InvalidArgument, err, "invalid channel id"))
+		return
+	}
+
+	dSvc := r.svc.NewChannelSvc(reqCtx.Ctx)
+	postingTimes, err := dSvc.GetPostingTimes(id)
+	if err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Ok(ctx, postingTimes)
+}
@@ -0,0 +1,84 @@
+package router
+
+import (
+	"context"
+
+	"github.com/amahdian/cliplab-be/domain/contracts/req"
+	"github.com/amahdian/cliplab-be/domain/contracts/resp"
+	"github.com/gin-gonic/gin"
+)
+
+func (r *Router) chatAboutPost(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+	user := reqCtx.UserInfo
+
+	uri := &req.IdUri{}
+	if err := ctx.BindUri(uri); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+	request := &req.PostChat{}
+	if err := ctx.ShouldBindJSON(request); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	svcCtx := reqCtx.Ctx
+	if request.Transport == req.ChatTransportWebSocket {
+		// the answer outlives the request when it is delivered over the web socket
+		svcCtx = context.WithoutCancel(svcCtx)
+	}
+
+	dSvc := r.svc.NewChatSvc(svcCtx)
+	stream, err := dSvc.Chat(user.Id, uri.Id, request.Message)
+	if err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	if request.Transport == req.ChatTransportWebSocket {
+		dSvc.ForwardToWebSocket(user.Id, uri.Id, stream)
+		resp.Ok(ctx, true)
+		return
+	}
+	resp.Stream(ctx, stream)
+}
+
+func (r *Router) getChatHistory(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+	user := reqCtx.UserInfo
+
+	uri := &req.IdUri{}
+	if err := ctx.BindUri(uri); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	dSvc := r.svc.NewChatSvc(reqCtx.Ctx)
+	messages, err := dSvc.GetHistory(user.Id, uri.Id)
+	if err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Ok(ctx, messages)
+}
+
+func (r *Router) clearChatHistory(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+	user := reqCtx.UserInfo
+
+	uri := &req.IdUri{}
+	if err := ctx.BindUri(uri); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	dSvc := r.svc.NewChatSvc(reqCtx.Ctx)
+	if err := dSvc.ClearHistory(user.Id, uri.Id); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Ok(ctx, true)
+}
@@ -0,0 +1,68 @@
+package router
+
+import (
+	"github.com/amahdian/cliplab-be/domain/contracts/req"
+	"github.com/amahdian/cliplab-be/domain/contracts/resp"
+	"github.com/amahdian/cliplab-be/global/errs"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (r *Router) comparePosts(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+	user := reqCtx.UserInfo
+
+	request := &req.ComparePosts{}
+	if err := ctx.ShouldBindJSON(request); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	dSvc := r.svc.NewCompareSvc(reqCtx.Ctx)
+	comparison, err := dSvc.ComparePosts(user.Id, request.PostIds)
+	if err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Created(ctx, comparison)
+}
+
+func (r *Router) listComparisons(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+	user := reqCtx.UserInfo
+
+	dSvc := r.svc.NewCompareSvc(reqCtx.Ctx)
+	comparisons, err := dSvc.ListComparisons(user.Id)
+	if err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Ok(ctx, comparisons)
+}
+
+func (r *Router) getComparison(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+	user := reqCtx.UserInfo
+
+	uri := &req.IdUri{}
+	if err := ctx.BindUri(uri); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+	id, err := uuid.Parse(uri.Id)
+	if err != nil {
+		resp.AbortWithError(ctx, errs.Newf(errs.InvalidArgument, err, "invalid comparison id"))
+		return
+	}
+
+	dSvc := r.svc.NewCompareSvc(reqCtx.Ctx)
+	comparison, err := dSvc.GetComparison(user.Id, id)
+	if err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Ok(ctx, comparison)
+}
@@ -0,0 +1,51 @@
+package router
+
+import (
+	"github.com/amahdian/cliplab-be/domain/contracts/req"
+	"github.com/amahdian/cliplab-be/domain/contracts/resp"
+	"github.com/gin-gonic/gin"
+)
+
+func (r *Router) getLibrary(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+	user := reqCtx.UserInfo
+
+	request := &req.Library{}
+	if err := ctx.BindQuery(request); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+	workspaceId, err := reqCtx.WorkspaceId()
+	if err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	dSvc := r.svc.NewLibrarySvc(reqCtx.Ctx)
+	library, err := dSvc.GetLibrary(user.Id, workspaceId, request.Category, request.Page, request.Size)
+	if err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Ok(ctx, library)
+}
+
+func (r *Router) getBenchmarks(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+
+	request := &req.Benchmarks{}
+	if err := ctx.BindQuery(request); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	dSvc := r.svc.NewLibrarySvc(reqCtx.Ctx)
+	benchmarks, err := dSvc.GetBenchmarks(request.Category)
+	if err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Ok(ctx, benchmarks)
+}
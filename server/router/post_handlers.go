@@ -25,9 +25,14 @@ func (r *Router) addRequestToAnalyzeQueue(ctx *gin.Context) {
 		resp.AbortWithError(ctx, errs.Newf(errs.InvalidArgument, nil, "invalid link: %v", err))
 		return
 	}
+	workspaceId, err := reqCtx.WorkspaceId()
+	if err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
 
 	dSvc := r.svc.NewPostSvc(reqCtx.Ctx)
-	id, err := dSvc.AddRequestToAnalyzeQueue(*link, user, reqCtx.Ip)
+	id, err := dSvc.AddRequestToAnalyzeQueue(*link, user, workspaceId, reqCtx.Ip)
 	if err != nil {
 		resp.AbortWithError(ctx, err)
 		return
@@ -48,10 +53,11 @@ func (r *Router) getAnalyzeResult(ctx *gin.Context) {
 	analyzeId, err := uuid.Parse(request.Id)
 	if err != nil {
 		resp.AbortWithError(ctx, err)
+		return
 	}
 
 	dSvc := r.svc.NewPostSvc(reqCtx.Ctx)
-	post, err := dSvc.GetAnalyzeResult(analyzeId)
+	post, err := dSvc.GetAnalyzeResult(analyzeId, reqCtx.UserInfo)
 	if err != nil {
 		resp.AbortWithError(ctx, err)
 		return
@@ -0,0 +1,89 @@
+package router
+
+import (
+	"github.com/amahdian/cliplab-be/domain/contracts/req"
+	"github.com/amahdian/cliplab-be/domain/contracts/resp"
+	"github.com/amahdian/cliplab-be/global/errs"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (r *Router) remixPost(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+	user := reqCtx.UserInfo
+
+	uri := &req.IdUri{}
+	if err := ctx.BindUri(uri); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+	request := &req.RemixPost{}
+	if err := ctx.ShouldBindJSON(request); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	dSvc := r.svc.NewRemixSvc(reqCtx.Ctx)
+	variants, err := dSvc.Remix(user.Id, uri.Id, request)
+	if err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Created(ctx, variants)
+}
+
+func (r *Router) listRemixVariants(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+	user := reqCtx.UserInfo
+
+	uri := &req.IdUri{}
+	if err := ctx.BindUri(uri); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+	request := &req.RemixVariants{}
+	if err := ctx.BindQuery(request); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	dSvc := r.svc.NewRemixSvc(reqCtx.Ctx)
+	variants, err := dSvc.ListVariants(user.Id, uri.Id, request.Section, request.Favorites)
+	if err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Ok(ctx, variants)
+}
+
+func (r *Router) favoriteRemixVariant(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+	user := reqCtx.UserInfo
+
+	uri := &req.IdUri{}
+	if err := ctx.BindUri(uri); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+	id, err := uuid.Parse(uri.Id)
+	if err != nil {
+		resp.AbortWithError(ctx, errs.Newf(errs.InvalidArgument, err, "invalid remix variant id"))
+		return
+	}
+	request := &req.FavoriteRemixVariant{}
+	if err := ctx.ShouldBindJSON(request); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	dSvc := r.svc.NewRemixSvc(reqCtx.Ctx)
+	variant, err := dSvc.SetFavorite(user.Id, id, request.Favorite)
+	if err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Ok(ctx, variant)
+}
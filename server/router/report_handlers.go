@@ -0,0 +1,51 @@
+package router
+
+import (
+	"time"
+
+	"github.com/amahdian/cliplab-be/domain/contracts/req"
+	"github.com/amahdian/cliplab-be/domain/contracts/resp"
+	"github.com/gin-gonic/gin"
+)
+
+func (r *Router) getLlmCostReport(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+
+	request := &req.LlmCostReport{}
+	if err := ctx.BindQuery(request); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	// default to the last 30 days, "to" is inclusive
+	to := time.Now().Truncate(24*time.Hour).AddDate(0, 0, 1)
+	if request.To != nil {
+		to = request.To.AddDate(0, 0, 1)
+	}
+	from := to.AddDate(0, 0, -30)
+	if request.From != nil {
+		from = *request.From
+	}
+
+	dSvc := r.svc.NewReportSvc(reqCtx.Ctx)
+	report, err := dSvc.GetLlmCostReport(from, to)
+	if err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Ok(ctx, report)
+}
+
+func (r *Router) getCalibrationReport(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+
+	dSvc := r.svc.NewReportSvc(reqCtx.Ctx)
+	report, err := dSvc.GetCalibrationReport()
+	if err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Ok(ctx, report)
+}
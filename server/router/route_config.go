@@ -1,25 +1,35 @@
 package router
 
 import (
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/amahdian/cliplab-be/svc/auth"
 	"github.com/gin-contrib/gzip"
 	"github.com/gin-gonic/gin"
 )
 
 type routeConfig struct {
-	RequireUserRoles bool
-	Middlewares      []gin.HandlerFunc
+	// Permissions are checked against the role of the user, see auth.Authorizer.
+	Permissions []auth.Permission
+	// ApiKeyScope makes the route available to the api keys with the scope.
+	ApiKeyScope model.ApiKeyScope
+	Middlewares []gin.HandlerFunc
 }
 
 func newRouteConfig() *routeConfig {
 	return &routeConfig{
-		RequireUserRoles: false,
-		Middlewares:      []gin.HandlerFunc{},
+		Middlewares: []gin.HandlerFunc{},
 	}
 }
 
-func (rc *routeConfig) withUserRoles(flag bool) *routeConfig {
+func (rc *routeConfig) withUserRoles(permissions ...auth.Permission) *routeConfig {
 	clone := rc.clone()
-	clone.RequireUserRoles = flag
+	clone.Permissions = append(clone.Permissions, permissions...)
+	return clone
+}
+
+func (rc *routeConfig) withApiKeyScope(scope model.ApiKeyScope) *routeConfig {
+	clone := rc.clone()
+	clone.ApiKeyScope = scope
 	return clone
 }
 
@@ -38,9 +48,12 @@ func (rc *routeConfig) withCompression() *routeConfig {
 func (rc *routeConfig) clone() *routeConfig {
 	middlewares := make([]gin.HandlerFunc, len(rc.Middlewares))
 	copy(middlewares, rc.Middlewares)
+	permissions := make([]auth.Permission, len(rc.Permissions))
+	copy(permissions, rc.Permissions)
 
 	return &routeConfig{
-		RequireUserRoles: rc.RequireUserRoles,
-		Middlewares:      middlewares,
+		Permissions: permissions,
+		ApiKeyScope: rc.ApiKeyScope,
+		Middlewares: middlewares,
 	}
 }
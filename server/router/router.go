@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/amahdian/cliplab-be/docs"
+	"github.com/amahdian/cliplab-be/domain/contracts/req"
 	"github.com/amahdian/cliplab-be/pkg/logger"
 	"github.com/amahdian/cliplab-be/storage"
 	"github.com/amahdian/cliplab-be/svc"
@@ -31,15 +32,19 @@ type Router struct {
 	configs *env.Envs
 
 	authenticator auth.Authenticator
+	authorizer    auth.Authorizer
 
 	publicGroup *gin.RouterGroup
 	authGroup   *gin.RouterGroup
+	// adminGroup holds the operational endpoints, each of its routes declares the permissions it requires.
+	adminGroup *gin.RouterGroup
 }
 
 func NewRouter(
 	svc svc.Svc,
 	configs *env.Envs,
-	authenticator auth.Authenticator) *Router {
+	authenticator auth.Authenticator,
+	authorizer auth.Authorizer) *Router {
 	gin.SetMode(configs.Server.GinMode)
 	router := &Router{
 		Engine:        gin.New(),
@@ -47,6 +52,7 @@ func NewRouter(
 		validator:     validator.Validator,
 		configs:       configs,
 		authenticator: authenticator,
+		authorizer:    authorizer,
 	}
 	router.Use(
 		middleware.WithLogger(),
@@ -74,7 +80,7 @@ func (r *Router) setupCors() {
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     origins,
 		AllowMethods:     []string{"GET", "PUT", "POST", "DELETE", "PATCH", "HEAD", "OPTIONS"},
-		AllowHeaders:     []string{"Content-Type", "Authorization", "X-Recaptcha-Token"},
+		AllowHeaders:     []string{"Content-Type", "Authorization", "X-Recaptcha-Token", req.WorkspaceHeader},
 		ExposeHeaders:    []string{"Content-Length", "Content-Disposition"},
 		AllowCredentials: true,
 		MaxAge:           24 * time.Hour,
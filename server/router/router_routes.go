@@ -1,9 +1,12 @@
 package router
 
 import (
+	"fmt"
 	"net/http"
 
+	"github.com/amahdian/cliplab-be/domain/model"
 	"github.com/amahdian/cliplab-be/server/middleware"
+	"github.com/amahdian/cliplab-be/svc/auth"
 	"github.com/gin-gonic/gin"
 )
 
@@ -13,11 +16,25 @@ func (r *Router) setupRoutes() {
 		"/api/v1",
 		middleware.VerifyAuth(r.authenticator),
 	)
+	r.adminGroup = r.Group(
+		"/api/v1/admin",
+		middleware.VerifyAuth(r.authenticator),
+	)
 
 	r.registerPublicRoutes()
 	r.registerUserRoutes()
 	r.registerAnalyzeRoutes()
 	r.registerWebSocketRoutes()
+	r.registerReportRoutes()
+	r.registerSearchRoutes()
+	r.registerCategoryRoutes()
+	r.registerLibraryRoutes()
+	r.registerChatRoutes()
+	r.registerRemixRoutes()
+	r.registerCompareRoutes()
+	r.registerChannelRoutes()
+	r.registerApiKeyRoutes()
+	r.registerWorkspaceRoutes()
 }
 
 func (r *Router) registerPublicRoutes() {
@@ -33,17 +50,44 @@ func (r *Router) registerUserRoutes() {
 
 	r.registerRoute(r.publicGroup, http.MethodPost, "/users/login", r.login, config.withMiddlewares(recaptchaMiddleware))
 	r.registerRoute(r.publicGroup, http.MethodPost, "/users/login/oauth", r.loginOauth, config.withMiddlewares(recaptchaMiddleware))
+	r.registerRoute(r.publicGroup, http.MethodGet, "/users/login/oidc", r.oidcAuthorize, config)
+	r.registerRoute(r.publicGroup, http.MethodPost, "/users/login/oidc", r.loginOidc, config)
+	r.registerRoute(r.publicGroup, http.MethodPost, "/users/login/2fa", r.loginTwoFactor, config)
 	r.registerRoute(r.publicGroup, http.MethodPost, "/users/register", r.register, config.withMiddlewares(recaptchaMiddleware))
 	r.registerRoute(r.publicGroup, http.MethodPost, "/users/verify", r.verify, config)
+	r.registerRoute(r.publicGroup, http.MethodPost, "/users/verify/resend", r.resendVerification, config.withMiddlewares(recaptchaMiddleware))
+	r.registerRoute(r.publicGroup, http.MethodPost, "/users/password/forgot", r.forgotPassword, config.withMiddlewares(recaptchaMiddleware))
+	r.registerRoute(r.publicGroup, http.MethodPost, "/users/password/reset", r.resetPassword, config)
+	r.registerRoute(r.authGroup, http.MethodPut, "/users/password", r.changePassword, config)
+	r.registerRoute(r.publicGroup, http.MethodPost, "/users/token/refresh", r.refreshToken, config)
+	r.registerRoute(r.authGroup, http.MethodPost, "/users/logout", r.logout, config)
+	r.registerRoute(r.authGroup, http.MethodPost, "/users/logout/all", r.logoutAll, config)
+	r.registerRoute(r.authGroup, http.MethodGet, "/users/sessions", r.listSessions, config)
+	r.registerRoute(r.authGroup, http.MethodDelete, "/users/sessions/:id", r.revokeSession, config)
+	r.registerRoute(r.authGroup, http.MethodGet, "/users/identities", r.listIdentities, config)
+	r.registerRoute(r.authGroup, http.MethodPost, "/users/identities", r.linkIdentity, config)
+	r.registerRoute(r.authGroup, http.MethodDelete, "/users/identities/:provider", r.unlinkIdentity, config)
+	r.registerRoute(r.authGroup, http.MethodPost, "/users/2fa/setup", r.setupTotp, config)
+	r.registerRoute(r.authGroup, http.MethodPost, "/users/2fa/enable", r.enableTotp, config)
+	r.registerRoute(r.authGroup, http.MethodPost, "/users/2fa/disable", r.disableTotp, config)
+	r.registerRoute(r.authGroup, http.MethodPost, "/users/2fa/recovery-codes", r.regenerateRecoveryCodes, config)
 	r.registerRoute(r.authGroup, http.MethodPut, "/users/update", r.updateUser, config)
 	r.registerRoute(r.authGroup, http.MethodGet, "/users/me", r.me, config)
+	r.registerRoute(r.authGroup, http.MethodGet, "/users/me/export", r.exportUserData, config)
+	r.registerRoute(r.authGroup, http.MethodPost, "/users/me/deletion-code", r.sendDeletionCode, config)
+	r.registerRoute(r.authGroup, http.MethodDelete, "/users/me", r.deleteAccount, config)
 }
 
 func (r *Router) registerAnalyzeRoutes() {
 	config := newRouteConfig()
 	//r.registerRoute(r.publicGroup, http.MethodPost, "/analyze", r.addRequestToAnalyzeQueue, config)
-	r.registerRoute(r.publicGroup, http.MethodPost, "/analyze", r.addRequestToAnalyzeQueue, config.withMiddlewares(middleware.VerifyRecaptcha(r.configs.Recaptcha.Secret)))
-	r.registerRoute(r.publicGroup, http.MethodGet, "/analyze/:id", r.getAnalyzeResult, config)
+	r.registerRoute(r.publicGroup, http.MethodPost, "/analyze", r.addRequestToAnalyzeQueue, config.withApiKeyScope(model.ApiKeyScopeAnalyzeWrite).withMiddlewares(
+		middleware.VerifyOptionalAuth(r.authenticator),
+		middleware.VerifyRecaptcha(r.configs.Recaptcha.Secret),
+	))
+	r.registerRoute(r.publicGroup, http.MethodGet, "/analyze/:id", r.getAnalyzeResult, config.withMiddlewares(
+		middleware.VerifyOptionalAuth(r.authenticator),
+	))
 }
 
 func (r *Router) registerWebSocketRoutes() {
@@ -51,21 +95,104 @@ func (r *Router) registerWebSocketRoutes() {
 	r.registerRoute(r.authGroup, http.MethodGet, "/ws", r.webSocketHandler, config)
 }
 
+func (r *Router) registerReportRoutes() {
+	config := newRouteConfig().withUserRoles(auth.PermissionReportsRead)
+	r.registerRoute(r.adminGroup, http.MethodGet, "/reports/llm-costs", r.getLlmCostReport, config)
+	r.registerRoute(r.adminGroup, http.MethodGet, "/reports/calibration", r.getCalibrationReport, config)
+}
+
+func (r *Router) registerSearchRoutes() {
+	config := newRouteConfig()
+	readConfig := config.withApiKeyScope(model.ApiKeyScopeLibraryRead)
+	r.registerRoute(r.authGroup, http.MethodGet, "/search", r.searchPosts, readConfig)
+	r.registerRoute(r.authGroup, http.MethodGet, "/posts/:id/similar", r.getSimilarPosts, readConfig)
+}
+
+func (r *Router) registerCategoryRoutes() {
+	config := newRouteConfig()
+	adminConfig := newRouteConfig().withUserRoles(auth.PermissionCategoriesWrite)
+	r.registerRoute(r.authGroup, http.MethodGet, "/categories", r.listCategories, config)
+	r.registerRoute(r.adminGroup, http.MethodPost, "/categories", r.createCategory, adminConfig)
+	r.registerRoute(r.adminGroup, http.MethodPut, "/categories/:id", r.updateCategory, adminConfig)
+	r.registerRoute(r.adminGroup, http.MethodDelete, "/categories/:id", r.deleteCategory, adminConfig)
+}
+
+func (r *Router) registerLibraryRoutes() {
+	config := newRouteConfig()
+	r.registerRoute(r.authGroup, http.MethodGet, "/library", r.getLibrary, config.withApiKeyScope(model.ApiKeyScopeLibraryRead))
+	r.registerRoute(r.publicGroup, http.MethodGet, "/benchmarks", r.getBenchmarks, config)
+}
+
+func (r *Router) registerChatRoutes() {
+	config := newRouteConfig()
+	r.registerRoute(r.authGroup, http.MethodPost, "/posts/:id/chat", r.chatAboutPost, config)
+	r.registerRoute(r.authGroup, http.MethodGet, "/posts/:id/chat", r.getChatHistory, config)
+	r.registerRoute(r.authGroup, http.MethodDelete, "/posts/:id/chat", r.clearChatHistory, config)
+}
+
+func (r *Router) registerRemixRoutes() {
+	config := newRouteConfig()
+	r.registerRoute(r.authGroup, http.MethodPost, "/posts/:id/remix", r.remixPost, config)
+	r.registerRoute(r.authGroup, http.MethodGet, "/posts/:id/remix", r.listRemixVariants, config)
+	r.registerRoute(r.authGroup, http.MethodPut, "/remix/:id/favorite", r.favoriteRemixVariant, config)
+}
+
+func (r *Router) registerCompareRoutes() {
+	config := newRouteConfig()
+	r.registerRoute(r.authGroup, http.MethodPost, "/compare", r.comparePosts, config)
+	readConfig := config.withApiKeyScope(model.ApiKeyScopeLibraryRead)
+	r.registerRoute(r.authGroup, http.MethodGet, "/compare", r.listComparisons, readConfig)
+	r.registerRoute(r.authGroup, http.MethodGet, "/compare/:id", r.getComparison, readConfig)
+}
+
+func (r *Router) registerChannelRoutes() {
+	config := newRouteConfig()
+	r.registerRoute(r.authGroup, http.MethodGet, "/channels/:id/posting-times", r.getPostingTimes, config.withApiKeyScope(model.ApiKeyScopeChannelsRead))
+}
+
+func (r *Router) registerApiKeyRoutes() {
+	config := newRouteConfig()
+	r.registerRoute(r.authGroup, http.MethodGet, "/api-keys", r.listApiKeys, config)
+	r.registerRoute(r.authGroup, http.MethodPost, "/api-keys", r.createApiKey, config)
+	r.registerRoute(r.authGroup, http.MethodDelete, "/api-keys/:id", r.revokeApiKey, config)
+}
+
+func (r *Router) registerWorkspaceRoutes() {
+	config := newRouteConfig()
+	r.registerRoute(r.authGroup, http.MethodGet, "/workspaces", r.listWorkspaces, config)
+	r.registerRoute(r.authGroup, http.MethodPost, "/workspaces", r.createWorkspace, config)
+	r.registerRoute(r.authGroup, http.MethodPost, "/workspaces/invitations/accept", r.acceptWorkspaceInvitation, config)
+	r.registerRoute(r.authGroup, http.MethodGet, "/workspaces/:id", r.getWorkspace, config)
+	r.registerRoute(r.authGroup, http.MethodPut, "/workspaces/:id", r.updateWorkspace, config)
+	r.registerRoute(r.authGroup, http.MethodDelete, "/workspaces/:id", r.deleteWorkspace, config)
+	r.registerRoute(r.authGroup, http.MethodGet, "/workspaces/:id/members", r.listWorkspaceMembers, config)
+	r.registerRoute(r.authGroup, http.MethodPut, "/workspaces/:id/members/:userId", r.updateWorkspaceMember, config)
+	r.registerRoute(r.authGroup, http.MethodDelete, "/workspaces/:id/members/:userId", r.removeWorkspaceMember, config)
+	r.registerRoute(r.authGroup, http.MethodGet, "/workspaces/:id/invitations", r.listWorkspaceInvitations, config)
+	r.registerRoute(r.authGroup, http.MethodPost, "/workspaces/:id/invitations", r.inviteWorkspaceMember, config)
+	r.registerRoute(r.authGroup, http.MethodDelete, "/workspaces/:id/invitations/:invitationId", r.revokeWorkspaceInvitation, config)
+}
+
 func (r *Router) registerRoute(routerGroup *gin.RouterGroup, method, path string, handler gin.HandlerFunc, configs ...*routeConfig) {
 	config := newRouteConfig()
 	if len(configs) > 0 {
 		config = configs[0]
 	}
 
+	if routerGroup == r.adminGroup && len(config.Permissions) == 0 {
+		panic(fmt.Sprintf("admin route %s %s declares no permissions", method, path))
+	}
+
 	handlers := make([]gin.HandlerFunc, 0)
 
-	//if config.RequireUserRoles {
-	//	handlers = append(handlers, middleware.WithUserRoles(r.authorizer))
-	//}
+	if len(config.Permissions) > 0 {
+		handlers = append(handlers, middleware.WithUserRoles(r.authorizer, config.Permissions...))
+	}
 
 	if len(config.Middlewares) > 0 {
 		handlers = append(handlers, config.Middlewares...)
 	}
+	handlers = append(handlers, middleware.RequireApiKeyScope(config.ApiKeyScope))
 
 	handlers = append(handlers, handler)
 	routerGroup.Handle(method, path, handlers...)
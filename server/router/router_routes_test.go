@@ -0,0 +1,66 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/amahdian/cliplab-be/global/env"
+	"github.com/amahdian/cliplab-be/pkg/logger"
+	"github.com/amahdian/cliplab-be/pkg/logger/logging"
+	"github.com/amahdian/cliplab-be/svc/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// stubAuthenticator authenticates every request as the user, or rejects it when there is none.
+type stubAuthenticator struct {
+	user *auth.UserInfo
+}
+
+func (a *stubAuthenticator) Verify(request *http.Request) (context.Context, error) {
+	if a.user == nil {
+		return request.Context(), errors.New("authorization header is empty")
+	}
+	return auth.WithUserInfo(request.Context(), *a.user), nil
+}
+
+func TestAdminRoutesRequirePermissions(t *testing.T) {
+	logger.Configure(logging.ErrorLevel, logging.TextFormat)
+	routes := []struct {
+		method string
+		path   string
+	}{
+		{http.MethodGet, "/api/v1/admin/reports/llm-costs"},
+		{http.MethodGet, "/api/v1/admin/reports/calibration"},
+		{http.MethodPost, "/api/v1/admin/categories"},
+		{http.MethodPut, "/api/v1/admin/categories/" + uuid.NewString()},
+		{http.MethodDelete, "/api/v1/admin/categories/" + uuid.NewString()},
+	}
+
+	user := &auth.UserInfo{Id: uuid.New(), Role: model.UserRoleUser}
+	cases := []struct {
+		name   string
+		user   *auth.UserInfo
+		status int
+	}{
+		{"anonymous", nil, http.StatusUnauthorized},
+		{"user", user, http.StatusForbidden},
+	}
+
+	envs := &env.Envs{}
+	envs.Server.GinMode = gin.TestMode
+	for _, c := range cases {
+		router := NewRouter(nil, envs, &stubAuthenticator{user: c.user}, auth.NewAuthorizer())
+		for _, route := range routes {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(route.method, route.path, nil))
+			if w.Code != c.status {
+				t.Errorf("%s %s %s: expected %d, got %d", c.name, route.method, route.path, c.status, w.Code)
+			}
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package router
+
+import (
+	"github.com/amahdian/cliplab-be/domain/contracts/req"
+	"github.com/amahdian/cliplab-be/domain/contracts/resp"
+	"github.com/gin-gonic/gin"
+)
+
+func (r *Router) searchPosts(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+	user := reqCtx.UserInfo
+
+	request := &req.SearchPosts{}
+	if err := ctx.BindQuery(request); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+	workspaceId, err := reqCtx.WorkspaceId()
+	if err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	dSvc := r.svc.NewSearchSvc(reqCtx.Ctx)
+	results, err := dSvc.SearchPosts(user.Id, workspaceId, request.Query, request.Limit)
+	if err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Ok(ctx, results)
+}
+
+func (r *Router) getSimilarPosts(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+	user := reqCtx.UserInfo
+
+	uri := &req.IdUri{}
+	if err := ctx.BindUri(uri); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+	request := &req.SimilarPosts{}
+	if err := ctx.BindQuery(request); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+	workspaceId, err := reqCtx.WorkspaceId()
+	if err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	dSvc := r.svc.NewSearchSvc(reqCtx.Ctx)
+	posts, err := dSvc.GetSimilarPosts(user.Id, workspaceId, uri.Id, request.Scope, request.Limit)
+	if err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Ok(ctx, posts)
+}
@@ -1,9 +1,13 @@
 package router
 
 import (
+	"net/http"
+
 	"github.com/amahdian/cliplab-be/domain/contracts/req"
 	"github.com/amahdian/cliplab-be/domain/contracts/resp"
+	"github.com/amahdian/cliplab-be/global/errs"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 func (r *Router) register(ctx *gin.Context) {
@@ -16,7 +20,7 @@ func (r *Router) register(ctx *gin.Context) {
 	}
 
 	dSvc := r.svc.NewUserSvc(reqCtx.Ctx)
-	authResp, err := dSvc.Register(request)
+	authResp, err := dSvc.Register(request, reqCtx.Device())
 	if err != nil {
 		resp.AbortWithError(ctx, err)
 		return
@@ -35,7 +39,7 @@ func (r *Router) login(ctx *gin.Context) {
 	}
 
 	dSvc := r.svc.NewUserSvc(reqCtx.Ctx)
-	authResp, err := dSvc.Login(request)
+	authResp, err := dSvc.Login(request, reqCtx.Device())
 	if err != nil {
 		resp.AbortWithError(ctx, err)
 		return
@@ -54,7 +58,58 @@ func (r *Router) loginOauth(ctx *gin.Context) {
 	}
 
 	dSvc := r.svc.NewUserSvc(reqCtx.Ctx)
-	authResp, err := dSvc.LoginOauth(request)
+	authResp, err := dSvc.LoginOauth(request, reqCtx.Device())
+	if err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Ok(ctx, authResp)
+}
+
+func (r *Router) oidcAuthorize(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+
+	dSvc := r.svc.NewUserSvc(reqCtx.Ctx)
+	authorization, err := dSvc.OidcAuthorize()
+	if err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Ok(ctx, authorization)
+}
+
+func (r *Router) loginOidc(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+
+	request := &req.OidcCallback{}
+	if err := ctx.ShouldBindJSON(request); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	dSvc := r.svc.NewUserSvc(reqCtx.Ctx)
+	authResp, err := dSvc.LoginOidc(request, reqCtx.Device())
+	if err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Ok(ctx, authResp)
+}
+
+func (r *Router) loginTwoFactor(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+
+	request := &req.TwoFactorLogin{}
+	if err := ctx.ShouldBindJSON(request); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	dSvc := r.svc.NewUserSvc(reqCtx.Ctx)
+	authResp, err := dSvc.LoginTwoFactor(request, reqCtx.Device())
 	if err != nil {
 		resp.AbortWithError(ctx, err)
 		return
@@ -74,22 +129,233 @@ func (r *Router) verify(ctx *gin.Context) {
 	}
 
 	dSvc := r.svc.NewUserSvc(reqCtx.Ctx)
-	token, user, err := dSvc.Verify(request.Email, request.Otp)
+	authResp, err := dSvc.Verify(request.Email, request.Otp, reqCtx.Device())
 	if err != nil {
 		resp.AbortWithError(ctx, err)
 		return
 	}
 
 	res := &resp.VerifyResponse{
-		Token:           token,
-		Name:            user.Name,
-		Email:           user.Email,
-		DefaultLanguage: user.DefaultLanguage,
+		Token:           authResp.Token,
+		ExpiresAt:       authResp.ExpiresAt,
+		RefreshToken:    authResp.RefreshToken,
+		Name:            authResp.User.Name,
+		Email:           authResp.User.Email,
+		DefaultLanguage: authResp.User.DefaultLanguage,
 	}
 
 	resp.Ok(ctx, res)
 }
 
+func (r *Router) resendVerification(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+
+	request := &req.ResendVerification{}
+	if err := ctx.ShouldBindJSON(request); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	dSvc := r.svc.NewUserSvc(reqCtx.Ctx)
+	if err := dSvc.ResendVerification(request.Email); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Ok(ctx, true)
+}
+
+func (r *Router) forgotPassword(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+
+	request := &req.ForgotPassword{}
+	if err := ctx.ShouldBindJSON(request); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	dSvc := r.svc.NewUserSvc(reqCtx.Ctx)
+	if err := dSvc.ForgotPassword(request.Email); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Ok(ctx, true)
+}
+
+func (r *Router) resetPassword(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+
+	request := &req.ResetPassword{}
+	if err := ctx.ShouldBindJSON(request); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	dSvc := r.svc.NewUserSvc(reqCtx.Ctx)
+	authResp, err := dSvc.ResetPassword(request, reqCtx.Device())
+	if err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Ok(ctx, authResp)
+}
+
+func (r *Router) changePassword(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+	user := reqCtx.UserInfo
+
+	request := &req.ChangePassword{}
+	if err := ctx.ShouldBindJSON(request); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	dSvc := r.svc.NewUserSvc(reqCtx.Ctx)
+	authResp, err := dSvc.ChangePassword(user.Id, request, reqCtx.Device())
+	if err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Ok(ctx, authResp)
+}
+
+func (r *Router) refreshToken(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+
+	request := &req.RefreshToken{}
+	if err := ctx.ShouldBindJSON(request); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	dSvc := r.svc.NewUserSvc(reqCtx.Ctx)
+	authResp, err := dSvc.Refresh(request.RefreshToken, reqCtx.Device())
+	if err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Ok(ctx, authResp)
+}
+
+func (r *Router) logout(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+
+	dSvc := r.svc.NewUserSvc(reqCtx.Ctx)
+	if err := dSvc.Logout(reqCtx.UserInfo); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Ok(ctx, true)
+}
+
+func (r *Router) logoutAll(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+	user := reqCtx.UserInfo
+
+	dSvc := r.svc.NewUserSvc(reqCtx.Ctx)
+	if err := dSvc.LogoutAll(user.Id); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Ok(ctx, true)
+}
+
+func (r *Router) listSessions(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+
+	dSvc := r.svc.NewUserSvc(reqCtx.Ctx)
+	sessions, err := dSvc.ListSessions(reqCtx.UserInfo)
+	if err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Ok(ctx, sessions)
+}
+
+func (r *Router) revokeSession(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+	user := reqCtx.UserInfo
+
+	uri := &req.IdUri{}
+	if err := ctx.BindUri(uri); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+	id, err := uuid.Parse(uri.Id)
+	if err != nil {
+		resp.AbortWithError(ctx, errs.Newf(errs.InvalidArgument, err, "invalid session id"))
+		return
+	}
+
+	dSvc := r.svc.NewUserSvc(reqCtx.Ctx)
+	if err = dSvc.RevokeSession(user.Id, id); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Ok(ctx, true)
+}
+
+func (r *Router) listIdentities(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+	user := reqCtx.UserInfo
+
+	dSvc := r.svc.NewUserSvc(reqCtx.Ctx)
+	methods, err := dSvc.ListIdentities(user.Id)
+	if err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Ok(ctx, methods)
+}
+
+func (r *Router) linkIdentity(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+	user := reqCtx.UserInfo
+
+	request := &req.OauthLogin{}
+	if err := ctx.ShouldBindJSON(request); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	dSvc := r.svc.NewUserSvc(reqCtx.Ctx)
+	identity, err := dSvc.LinkIdentity(user.Id, request)
+	if err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Created(ctx, identity)
+}
+
+func (r *Router) unlinkIdentity(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+	user := reqCtx.UserInfo
+
+	uri := &req.ProviderUri{}
+	if err := ctx.BindUri(uri); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	dSvc := r.svc.NewUserSvc(reqCtx.Ctx)
+	if err := dSvc.UnlinkIdentity(user.Id, uri.Provider); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Ok(ctx, true)
+}
+
 func (r *Router) updateUser(ctx *gin.Context) {
 	reqCtx := req.GetRequestContext(ctx)
 	user := reqCtx.UserInfo.User()
@@ -124,3 +390,124 @@ func (r *Router) me(ctx *gin.Context) {
 
 	resp.Ok(ctx, userData)
 }
+
+func (r *Router) exportUserData(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+	user := reqCtx.UserInfo
+
+	dSvc := r.svc.NewUserSvc(reqCtx.Ctx)
+	data, err := dSvc.ExportData(user.Id)
+	if err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	ctx.Header("Content-Disposition", `attachment; filename="cliplab-export.zip"`)
+	ctx.Data(http.StatusOK, "application/zip", data)
+}
+
+func (r *Router) sendDeletionCode(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+	user := reqCtx.UserInfo
+
+	dSvc := r.svc.NewUserSvc(reqCtx.Ctx)
+	if err := dSvc.SendDeletionCode(user.Id); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Ok(ctx, true)
+}
+
+func (r *Router) deleteAccount(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+	user := reqCtx.UserInfo
+
+	request := &req.DeleteAccount{}
+	if err := ctx.ShouldBindJSON(request); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	dSvc := r.svc.NewUserSvc(reqCtx.Ctx)
+	deletion, err := dSvc.DeleteAccount(user.Id, request)
+	if err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Ok(ctx, deletion)
+}
+
+func (r *Router) setupTotp(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+	user := reqCtx.UserInfo
+
+	dSvc := r.svc.NewUserSvc(reqCtx.Ctx)
+	setup, err := dSvc.SetupTotp(user.Id)
+	if err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Ok(ctx, setup)
+}
+
+func (r *Router) enableTotp(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+	user := reqCtx.UserInfo
+
+	request := &req.TwoFactorCode{}
+	if err := ctx.ShouldBindJSON(request); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	dSvc := r.svc.NewUserSvc(reqCtx.Ctx)
+	codes, err := dSvc.EnableTotp(user.Id, request.Code)
+	if err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Ok(ctx, codes)
+}
+
+func (r *Router) disableTotp(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+	user := reqCtx.UserInfo
+
+	request := &req.TwoFactorCode{}
+	if err := ctx.ShouldBindJSON(request); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	dSvc := r.svc.NewUserSvc(reqCtx.Ctx)
+	if err := dSvc.DisableTotp(user.Id, request.Code); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Ok(ctx, true)
+}
+
+func (r *Router) regenerateRecoveryCodes(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+	user := reqCtx.UserInfo
+
+	request := &req.TwoFactorCode{}
+	if err := ctx.ShouldBindJSON(request); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	dSvc := r.svc.NewUserSvc(reqCtx.Ctx)
+	codes, err := dSvc.RegenerateRecoveryCodes(user.Id, request.Code)
+	if err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Ok(ctx, codes)
+}
@@ -0,0 +1,292 @@
+package router
+
+import (
+	"github.com/amahdian/cliplab-be/domain/contracts/req"
+	"github.com/amahdian/cliplab-be/domain/contracts/resp"
+	"github.com/amahdian/cliplab-be/global/errs"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (r *Router) listWorkspaces(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+	user := reqCtx.UserInfo
+
+	dSvc := r.svc.NewWorkspaceSvc(reqCtx.Ctx)
+	workspaces, err := dSvc.List(user.Id)
+	if err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Ok(ctx, workspaces)
+}
+
+func (r *Router) createWorkspace(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+	user := reqCtx.UserInfo
+
+	request := &req.CreateWorkspace{}
+	if err := ctx.ShouldBindJSON(request); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	dSvc := r.svc.NewWorkspaceSvc(reqCtx.Ctx)
+	workspace, err := dSvc.Create(user.Id, request)
+	if err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Created(ctx, workspace)
+}
+
+func (r *Router) getWorkspace(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+	user := reqCtx.UserInfo
+
+	id, ok := bindWorkspaceId(ctx)
+	if !ok {
+		return
+	}
+
+	dSvc := r.svc.NewWorkspaceSvc(reqCtx.Ctx)
+	workspace, err := dSvc.Get(user.Id, id)
+	if err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Ok(ctx, workspace)
+}
+
+func (r *Router) updateWorkspace(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+	user := reqCtx.UserInfo
+
+	id, ok := bindWorkspaceId(ctx)
+	if !ok {
+		return
+	}
+	request := &req.UpdateWorkspace{}
+	if err := ctx.ShouldBindJSON(request); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	dSvc := r.svc.NewWorkspaceSvc(reqCtx.Ctx)
+	workspace, err := dSvc.Update(user.Id, id, request)
+	if err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Ok(ctx, workspace)
+}
+
+func (r *Router) deleteWorkspace(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+	user := reqCtx.UserInfo
+
+	id, ok := bindWorkspaceId(ctx)
+	if !ok {
+		return
+	}
+
+	dSvc := r.svc.NewWorkspaceSvc(reqCtx.Ctx)
+	if err := dSvc.Delete(user.Id, id); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Ok(ctx, true)
+}
+
+func (r *Router) listWorkspaceMembers(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+	user := reqCtx.UserInfo
+
+	id, ok := bindWorkspaceId(ctx)
+	if !ok {
+		return
+	}
+
+	dSvc := r.svc.NewWorkspaceSvc(reqCtx.Ctx)
+	members, err := dSvc.ListMembers(user.Id, id)
+	if err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Ok(ctx, members)
+}
+
+func (r *Router) updateWorkspaceMember(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+	user := reqCtx.UserInfo
+
+	id, memberId, ok := bindWorkspaceMemberIds(ctx)
+	if !ok {
+		return
+	}
+	request := &req.UpdateWorkspaceMember{}
+	if err := ctx.ShouldBindJSON(request); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	dSvc := r.svc.NewWorkspaceSvc(reqCtx.Ctx)
+	member, err := dSvc.UpdateMemberRole(user.Id, id, memberId, request.Role)
+	if err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Ok(ctx, member)
+}
+
+func (r *Router) removeWorkspaceMember(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+	user := reqCtx.UserInfo
+
+	id, memberId, ok := bindWorkspaceMemberIds(ctx)
+	if !ok {
+		return
+	}
+
+	dSvc := r.svc.NewWorkspaceSvc(reqCtx.Ctx)
+	if err := dSvc.RemoveMember(user.Id, id, memberId); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Ok(ctx, true)
+}
+
+func (r *Router) listWorkspaceInvitations(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+	user := reqCtx.UserInfo
+
+	id, ok := bindWorkspaceId(ctx)
+	if !ok {
+		return
+	}
+
+	dSvc := r.svc.NewWorkspaceSvc(reqCtx.Ctx)
+	invitations, err := dSvc.ListInvitations(user.Id, id)
+	if err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Ok(ctx, invitations)
+}
+
+func (r *Router) inviteWorkspaceMember(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+	user := reqCtx.UserInfo
+
+	id, ok := bindWorkspaceId(ctx)
+	if !ok {
+		return
+	}
+	request := &req.InviteWorkspaceMember{}
+	if err := ctx.ShouldBindJSON(request); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	dSvc := r.svc.NewWorkspaceSvc(reqCtx.Ctx)
+	invitation, err := dSvc.Invite(user.Id, id, request)
+	if err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Created(ctx, invitation)
+}
+
+func (r *Router) revokeWorkspaceInvitation(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+	user := reqCtx.UserInfo
+
+	uri := &req.WorkspaceInvitationUri{}
+	if err := ctx.BindUri(uri); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+	id, err := uuid.Parse(uri.Id)
+	if err != nil {
+		resp.AbortWithError(ctx, errs.Newf(errs.InvalidArgument, err, "invalid workspace id"))
+		return
+	}
+	invitationId, err := uuid.Parse(uri.InvitationId)
+	if err != nil {
+		resp.AbortWithError(ctx, errs.Newf(errs.InvalidArgument, err, "invalid invitation id"))
+		return
+	}
+
+	dSvc := r.svc.NewWorkspaceSvc(reqCtx.Ctx)
+	if err = dSvc.RevokeInvitation(user.Id, id, invitationId); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Ok(ctx, true)
+}
+
+func (r *Router) acceptWorkspaceInvitation(ctx *gin.Context) {
+	reqCtx := req.GetRequestContext(ctx)
+	user := reqCtx.UserInfo
+
+	request := &req.AcceptWorkspaceInvitation{}
+	if err := ctx.ShouldBindJSON(request); err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	dSvc := r.svc.NewWorkspaceSvc(reqCtx.Ctx)
+	workspace, err := dSvc.AcceptInvitation(user.Id, request.Token)
+	if err != nil {
+		resp.AbortWithError(ctx, err)
+		return
+	}
+
+	resp.Ok(ctx, workspace)
+}
+
+func bindWorkspaceId(ctx *gin.Context) (uuid.UUID, bool) {
+	uri := &req.IdUri{}
+	if err := ctx.BindUri(uri); err != nil {
+		resp.AbortWithError(ctx, err)
+		return uuid.Nil, false
+	}
+
+	id, err := uuid.Parse(uri.Id)
+	if err != nil {
+		resp.AbortWithError(ctx, errs.Newf(errs.InvalidArgument, err, "invalid workspace id"))
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func bindWorkspaceMemberIds(ctx *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	uri := &req.WorkspaceMemberUri{}
+	if err := ctx.BindUri(uri); err != nil {
+		resp.AbortWithError(ctx, err)
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	id, err := uuid.Parse(uri.Id)
+	if err != nil {
+		resp.AbortWithError(ctx, errs.Newf(errs.InvalidArgument, err, "invalid workspace id"))
+		return uuid.Nil, uuid.Nil, false
+	}
+	memberId, err := uuid.Parse(uri.UserId)
+	if err != nil {
+		resp.AbortWithError(ctx, errs.Newf(errs.InvalidArgument, err, "invalid member id"))
+		return uuid.Nil, uuid.Nil, false
+	}
+	return id, memberId, true
+}
@@ -6,6 +6,9 @@ import (
 	"strings"
 
 	"github.com/amahdian/cliplab-be/clients/gemini"
+	"github.com/amahdian/cliplab-be/clients/gpt"
+	"github.com/amahdian/cliplab-be/clients/mailer"
+	"github.com/amahdian/cliplab-be/clients/oidc"
 	"github.com/amahdian/cliplab-be/clients/rocksolid"
 	"github.com/amahdian/cliplab-be/global/env"
 	"github.com/amahdian/cliplab-be/pkg/db"
@@ -30,10 +33,14 @@ type Server struct {
 	Envs *env.Envs
 
 	GeminiClient  gemini.Client
+	GptClient     gpt.Client
 	ScraperClient rocksolid.Client
 	RedisClient   *redis.Client
+	Mailer        mailer.Mailer
+	OidcClient    oidc.Client
 
 	Authenticator auth.Authenticator
+	Authorizer    auth.Authorizer
 
 	StorageConfig svc.StorageConfig
 	PgStorage     storage.PgStorage
@@ -61,6 +68,8 @@ func NewServer(envs *env.Envs) (*Server, error) {
 	s.setupRouter()
 
 	go runQueue(s.RedisClient, s.Svc)
+	go runSnapshotScheduler(s.Envs.Calibration.SnapshotInterval, s.Svc)
+	go runAccountPurgeScheduler(s.Envs.Account.PurgeInterval, s.Svc)
 
 	return s, nil
 }
@@ -118,7 +127,7 @@ func (s *Server) migrateDb() error {
 }
 
 func (s *Server) setupInfrastructure() error {
-	if err := s.setupAuthenticator(); err != nil {
+	if err := s.setupGeminiClient(); err != nil {
 		return err
 	}
 	if err := s.setupGPTClient(); err != nil {
@@ -130,9 +139,16 @@ func (s *Server) setupInfrastructure() error {
 	if err := s.setupRedis(); err != nil {
 		return err
 	}
+	if err := s.setupAuthenticator(); err != nil {
+		return err
+	}
 	if err := s.setupFileStorage(); err != nil {
 		return err
 	}
+	if err := s.setupMailer(); err != nil {
+		return err
+	}
+	s.setupOidcClient()
 	return nil
 }
 
@@ -153,8 +169,11 @@ func (s *Server) setupServices() {
 		s.PgStorage,
 		s.Envs,
 		s.GeminiClient,
+		s.GptClient,
 		s.ScraperClient,
 		s.RedisClient,
+		s.Mailer,
+		s.OidcClient,
 		s.StorageConfig,
 	)
 }
@@ -163,20 +182,33 @@ func (s *Server) setupRouter() {
 	s.Router = router.NewRouter(
 		s.Svc,
 		s.Envs,
-		s.Authenticator)
+		s.Authenticator,
+		s.Authorizer)
 }
 
 func (s *Server) setupAuthenticator() error {
-	s.Authenticator = auth.NewAuthenticator(s.Envs, s.PgStorage)
+	s.Authenticator = auth.NewAuthenticator(s.Envs, s.PgStorage, s.RedisClient)
+	s.Authorizer = auth.NewAuthorizer()
 	return nil
 }
 
-func (s *Server) setupGPTClient() error {
+func (s *Server) setupGeminiClient() error {
 	client := gemini.NewClient(s.Envs.Gemini.ClientHost, s.Envs.Gemini.Token)
 	s.GeminiClient = client
 	return nil
 }
 
+func (s *Server) setupGPTClient() error {
+	if s.Envs.Gpt.Token == "" {
+		logger.Warn("gpt client is not configured, embeddings are disabled")
+		return nil
+	}
+
+	client := gpt.NewClient(s.Envs.Gpt.ClientHost, s.Envs.Gpt.Token)
+	s.GptClient = client
+	return nil
+}
+
 func (s *Server) setupScaperClient() error {
 	client := rocksolid.NewClient(s.Envs.RapidApi.Token)
 	s.ScraperClient = client
@@ -198,6 +230,42 @@ func (s *Server) setupRedis() error {
 	return nil
 }
 
+func (s *Server) setupMailer() error {
+	switch s.Envs.Mail.Driver {
+	case "smtp":
+		if s.Envs.Mail.SmtpHost == "" {
+			return errors.New("SMTP_HOST is required by the smtp mail driver")
+		}
+		s.Mailer = mailer.NewSmtpMailer(
+			s.Envs.Mail.SmtpHost,
+			s.Envs.Mail.SmtpPort,
+			s.Envs.Mail.SmtpUsername,
+			s.Envs.Mail.SmtpPassword,
+			s.Envs.Mail.From)
+	case "log":
+		logger.Warn("mail is not configured, emails are only logged")
+		s.Mailer = mailer.NewLogMailer(s.Envs.Mail.LogDir, s.Envs.Mail.From)
+	default:
+		return errors.Errorf("unknown mail driver %q", s.Envs.Mail.Driver)
+	}
+	return nil
+}
+
+func (s *Server) setupOidcClient() {
+	if s.Envs.Oidc.Issuer == "" {
+		logger.Warn("oidc is not configured, single sign-on is disabled")
+		return
+	}
+
+	s.OidcClient = oidc.NewClient(oidc.Config{
+		Issuer:       s.Envs.Oidc.Issuer,
+		ClientID:     s.Envs.Oidc.ClientID,
+		ClientSecret: s.Envs.Oidc.ClientSecret,
+		RedirectUrl:  s.Envs.Oidc.RedirectUrl,
+		Scopes:       strings.Fields(s.Envs.Oidc.Scopes),
+	})
+}
+
 func (s *Server) setupFileStorage() error {
 	if s.Envs.FileStorage.Bypass {
 		logger.Warn("file storage is not configured")
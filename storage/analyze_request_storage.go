@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/google/uuid"
 )
 
 type AnalyzeRequestStorage interface {
@@ -13,4 +14,8 @@ type AnalyzeRequestStorage interface {
 	FindByUrl(url string) (*model.AnalyzeRequest, error)
 	ListByPostId(id string) ([]*model.AnalyzeRequest, error)
 	CountByIpAndDate(ip net.IP, date time.Time) (int64, error)
+	SummarizeLlmCosts(from, to time.Time) ([]*model.LlmCostSummary, error)
+	ListByUserId(userId uuid.UUID) ([]*model.AnalyzeRequest, error)
+	// DetachUser removes the user and their ip from the requests of the user, the posts stay analyzed.
+	DetachUser(userId uuid.UUID) error
 }
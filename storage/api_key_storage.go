@@ -0,0 +1,18 @@
+package storage
+
+import (
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/google/uuid"
+)
+
+type ApiKeyStorage interface {
+	PgCrudStorage[*model.ApiKey]
+
+	// FindByPrefix returns the key with the prefix, or nil.
+	FindByPrefix(prefix string) (*model.ApiKey, error)
+	ListActiveByUserId(userId uuid.UUID) ([]*model.ApiKey, error)
+	// Revoke revokes the key of the user and reports false when there is no such active key.
+	Revoke(userId, id uuid.UUID) (bool, error)
+	// Touch records the use of the key, writing at most once a minute.
+	Touch(id uuid.UUID) error
+}
@@ -0,0 +1,14 @@
+package storage
+
+import (
+	"github.com/amahdian/cliplab-be/domain/model"
+)
+
+type CategoryStorage interface {
+	PgCrudStorage[*model.Category]
+
+	FindByPath(path string) (*model.Category, error)
+	ListOrdered() ([]*model.Category, error)
+	// Rename saves the category and moves the subcategories under its previous path to its new path.
+	Rename(category *model.Category, previousPath string) error
+}
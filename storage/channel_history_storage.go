@@ -2,8 +2,12 @@ package storage
 
 import (
 	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/google/uuid"
 )
 
 type ChannelHistoryStorage interface {
 	PgCrudStorage[*model.ChannelHistory]
+
+	// ListLatestByChannelIds returns the most recent history of each channel.
+	ListLatestByChannelIds(ids []uuid.UUID) ([]*model.ChannelHistory, error)
 }
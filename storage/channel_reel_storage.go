@@ -0,0 +1,12 @@
+package storage
+
+import (
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/google/uuid"
+)
+
+type ChannelReelStorage interface {
+	PgCrudStorage[*model.ChannelReel]
+
+	ListByChannelId(channelId uuid.UUID) ([]*model.ChannelReel, error)
+}
@@ -0,0 +1,16 @@
+package storage
+
+import (
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/google/uuid"
+)
+
+type ChatMessageStorage interface {
+	PgCrudStorage[*model.ChatMessage]
+
+	// ListRecent returns the latest messages of the conversation, oldest first.
+	ListRecent(userId uuid.UUID, postId string, limit int) ([]*model.ChatMessage, error)
+	DeleteConversation(userId uuid.UUID, postId string) error
+	// ListByUserId returns every message of the user, oldest first.
+	ListByUserId(userId uuid.UUID) ([]*model.ChatMessage, error)
+}
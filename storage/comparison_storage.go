@@ -0,0 +1,12 @@
+package storage
+
+import (
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/google/uuid"
+)
+
+type ComparisonStorage interface {
+	PgCrudStorage[*model.Comparison]
+
+	ListByUserId(userId uuid.UUID) ([]*model.Comparison, error)
+}
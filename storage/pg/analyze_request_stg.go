@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/google/uuid"
 )
 
 type AnalyzeRequestStg struct {
@@ -28,7 +29,7 @@ func (s *AnalyzeRequestStg) FindByUrl(url string) (*model.AnalyzeRequest, error)
 
 func (s *AnalyzeRequestStg) ListByPostId(id string) ([]*model.AnalyzeRequest, error) {
 	var res []*model.AnalyzeRequest
-	err := s.db.Find(&res, "post_id = ?", id).Order("updated_at desc").Error
+	err := s.db.Order("updated_at desc").Find(&res, "post_id = ?", id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -52,3 +53,44 @@ func (s *AnalyzeRequestStg) CountByIpAndDate(ip net.IP, date time.Time) (int64,
 
 	return count, nil
 }
+
+func (s *AnalyzeRequestStg) SummarizeLlmCosts(from, to time.Time) ([]*model.LlmCostSummary, error) {
+	var res []*model.LlmCostSummary
+	err := s.db.
+		Model(&model.AnalyzeRequest{}).
+		Select(`DATE_TRUNC('day', created_at) AS day,
+			user_id,
+			platform,
+			COUNT(*) AS requests,
+			SUM(prompt_tokens) AS prompt_tokens,
+			SUM(video_tokens) AS video_tokens,
+			SUM(audio_tokens) AS audio_tokens,
+			SUM(output_tokens) AS output_tokens,
+			SUM(total_tokens) AS total_tokens,
+			SUM(search_queries) AS search_queries,
+			SUM(llm_cost) AS cost`).
+		Where("created_at >= ? AND created_at < ? AND total_tokens > 0", from, to).
+		Group("day, user_id, platform").
+		Order("day DESC, cost DESC").
+		Scan(&res).Error
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *AnalyzeRequestStg) ListByUserId(userId uuid.UUID) ([]*model.AnalyzeRequest, error) {
+	var res []*model.AnalyzeRequest
+	err := s.db.Where("user_id = ?", userId).Order("created_at DESC").Find(&res).Error
+	return res, err
+}
+
+func (s *AnalyzeRequestStg) DetachUser(userId uuid.UUID) error {
+	return s.db.
+		Model(&model.AnalyzeRequest{}).
+		Where("user_id = ?", userId).
+		Updates(map[string]interface{}{
+			"user_id": nil,
+			"user_ip": nil,
+		}).Error
+}
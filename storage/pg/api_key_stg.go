@@ -0,0 +1,57 @@
+package pg
+
+import (
+	"errors"
+	"time"
+
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ApiKeyStg struct {
+	crudStg[*model.ApiKey]
+}
+
+func NewApiKeyStg(ses *ormSession) *ApiKeyStg {
+	return &ApiKeyStg{
+		crudStg: crudStg[*model.ApiKey]{db: ses.db},
+	}
+}
+
+func (s *ApiKeyStg) FindByPrefix(prefix string) (*model.ApiKey, error) {
+	var key *model.ApiKey
+	err := s.db.Where("prefix = ?", prefix).First(&key).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return key, nil
+}
+
+func (s *ApiKeyStg) ListActiveByUserId(userId uuid.UUID) ([]*model.ApiKey, error) {
+	var list []*model.ApiKey
+	err := s.db.
+		Where("user_id = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", userId, time.Now()).
+		Order("created_at DESC").
+		Find(&list).Error
+	return list, err
+}
+
+func (s *ApiKeyStg) Revoke(userId, id uuid.UUID) (bool, error) {
+	res := s.db.
+		Model(&model.ApiKey{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userId).
+		Update("revoked_at", time.Now())
+	return res.RowsAffected > 0, res.Error
+}
+
+func (s *ApiKeyStg) Touch(id uuid.UUID) error {
+	now := time.Now()
+	return s.db.
+		Model(&model.ApiKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, now.Add(-time.Minute)).
+		Update("last_used_at", now).Error
+}
@@ -0,0 +1,49 @@
+package pg
+
+import (
+	"github.com/amahdian/cliplab-be/domain/model"
+	"gorm.io/gorm"
+)
+
+type CategoryStg struct {
+	crudStg[*model.Category]
+}
+
+func NewCategoryStg(ses *ormSession) *CategoryStg {
+	return &CategoryStg{
+		crudStg: crudStg[*model.Category]{db: ses.db},
+	}
+}
+
+func (s *CategoryStg) FindByPath(path string) (*model.Category, error) {
+	res := &model.Category{}
+	err := s.db.First(res, "LOWER(path) = LOWER(?)", path).Error
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *CategoryStg) ListOrdered() ([]*model.Category, error) {
+	var list []*model.Category
+	err := s.db.Order("path ASC").Find(&list).Error
+	return list, err
+}
+
+func (s *CategoryStg) Rename(category *model.Category, previousPath string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := moveSubcategories(tx, category, previousPath); err != nil {
+			return err
+		}
+		return tx.Model(category).Select("*").Updates(category).Error
+	})
+}
+
+// moveSubcategories rewrites the paths under the previous path of the category to its new path.
+// The category itself is excluded, it would match when moved under its own previous path, e.g. Tech to Tech/AI.
+func moveSubcategories(tx *gorm.DB, category *model.Category, previousPath string) error {
+	return tx.
+		Model(&model.Category{}).
+		Where("LEFT(path, char_length(?) + 1) = ? AND id <> ?", previousPath, previousPath+model.CategoryPathSeparator, category.ID).
+		Update("path", gorm.Expr("? || SUBSTRING(path FROM char_length(?) + 1)", category.Path, previousPath)).Error
+}
@@ -0,0 +1,31 @@
+package pg
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/google/uuid"
+)
+
+func TestMoveSubcategoriesQuery(t *testing.T) {
+	ses, captured := newDryRunStg(t)
+	category := &model.Category{ID: uuid.New(), Name: "AI", Path: "Tech/AI"}
+	if err := moveSubcategories(ses.db, category, "Tech"); err != nil {
+		t.Fatalf("move: %v", err)
+	}
+
+	// renaming Tech to Tech/AI must not move Tech/AI itself to Tech/AI/AI
+	last := len(captured.vars) - 1
+	if last < 0 || captured.vars[last] != category.ID {
+		t.Errorf("expected the category id as the last var, got %v", captured.vars)
+	}
+	if !strings.HasSuffix(captured.sql, fmt.Sprintf("AND id <> $%d", last+1)) {
+		t.Errorf("query does not exclude the category:\n%s", captured.sql)
+	}
+	if want := []any{"Tech/AI", "Tech"}; last < 1 || !reflect.DeepEqual(captured.vars[:2], want) {
+		t.Errorf("expected the paths %v to be set, got %v", want, captured.vars)
+	}
+}
@@ -2,6 +2,7 @@ package pg
 
 import (
 	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/google/uuid"
 )
 
 type ChannelHistoryStg struct {
@@ -13,3 +14,17 @@ func NewChannelHistoryStg(ses *ormSession) *ChannelHistoryStg {
 		crudStg: crudStg[*model.ChannelHistory]{db: ses.db},
 	}
 }
+
+func (s *ChannelHistoryStg) ListLatestByChannelIds(ids []uuid.UUID) ([]*model.ChannelHistory, error) {
+	var list []*model.ChannelHistory
+	if len(ids) == 0 {
+		return list, nil
+	}
+
+	err := s.db.
+		Raw(`SELECT DISTINCT ON (channel_id) * FROM channel_histories
+			WHERE channel_id IN ?
+			ORDER BY channel_id, created_at DESC`, ids).
+		Scan(&list).Error
+	return list, err
+}
@@ -0,0 +1,22 @@
+package pg
+
+import (
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/google/uuid"
+)
+
+type ChannelReelStg struct {
+	crudStg[*model.ChannelReel]
+}
+
+func NewChannelReelStg(ses *ormSession) *ChannelReelStg {
+	return &ChannelReelStg{
+		crudStg: crudStg[*model.ChannelReel]{db: ses.db},
+	}
+}
+
+func (s *ChannelReelStg) ListByChannelId(channelId uuid.UUID) ([]*model.ChannelReel, error) {
+	var list []*model.ChannelReel
+	err := s.db.Where("channel_id = ?", channelId).Order("taken_at").Find(&list).Error
+	return list, err
+}
@@ -0,0 +1,42 @@
+package pg
+
+import (
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+)
+
+type ChatMessageStg struct {
+	crudStg[*model.ChatMessage]
+}
+
+func NewChatMessageStg(ses *ormSession) *ChatMessageStg {
+	return &ChatMessageStg{
+		crudStg: crudStg[*model.ChatMessage]{db: ses.db},
+	}
+}
+
+func (s *ChatMessageStg) ListRecent(userId uuid.UUID, postId string, limit int) ([]*model.ChatMessage, error) {
+	var list []*model.ChatMessage
+	err := s.db.
+		Where("user_id = ? AND post_id = ?", userId, postId).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	return lo.Reverse(list), nil
+}
+
+func (s *ChatMessageStg) DeleteConversation(userId uuid.UUID, postId string) error {
+	return s.db.
+		Where("user_id = ? AND post_id = ?", userId, postId).
+		Delete(&model.ChatMessage{}).Error
+}
+
+func (s *ChatMessageStg) ListByUserId(userId uuid.UUID) ([]*model.ChatMessage, error) {
+	var list []*model.ChatMessage
+	err := s.db.Where("user_id = ?", userId).Order("created_at").Find(&list).Error
+	return list, err
+}
@@ -0,0 +1,22 @@
+package pg
+
+import (
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/google/uuid"
+)
+
+type ComparisonStg struct {
+	crudStg[*model.Comparison]
+}
+
+func NewComparisonStg(ses *ormSession) *ComparisonStg {
+	return &ComparisonStg{
+		crudStg: crudStg[*model.Comparison]{db: ses.db},
+	}
+}
+
+func (s *ComparisonStg) ListByUserId(userId uuid.UUID) ([]*model.Comparison, error) {
+	var list []*model.Comparison
+	err := s.db.Where("user_id = ?", userId).Order("created_at DESC").Find(&list).Error
+	return list, err
+}
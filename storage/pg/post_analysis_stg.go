@@ -2,6 +2,7 @@ package pg
 
 import (
 	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/google/uuid"
 )
 
 type PostAnalysisStg struct {
@@ -22,3 +23,15 @@ func (s *PostAnalysisStg) FindByPostId(id string) (*model.PostAnalysis, error) {
 	}
 	return res, nil
 }
+
+func (s *PostAnalysisStg) ListAfterId(afterId uuid.UUID, limit int) ([]*model.PostAnalysis, error) {
+	var list []*model.PostAnalysis
+	err := s.db.Where("id > ?", afterId).Order("id ASC").Limit(limit).Find(&list).Error
+	return list, err
+}
+
+func (s *PostAnalysisStg) ListByPostIds(ids []string) ([]*model.PostAnalysis, error) {
+	var list []*model.PostAnalysis
+	err := s.db.Where("post_id IN ?", ids).Find(&list).Error
+	return list, err
+}
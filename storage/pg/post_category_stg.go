@@ -0,0 +1,47 @@
+package pg
+
+import (
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+)
+
+type PostCategoryStg struct {
+	crudStg[*model.PostCategory]
+}
+
+func NewPostCategoryStg(ses *ormSession) *PostCategoryStg {
+	return &PostCategoryStg{
+		crudStg: crudStg[*model.PostCategory]{db: ses.db},
+	}
+}
+
+func (s *PostCategoryStg) ReplaceForPost(postId string, categoryIds []uuid.UUID) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("post_id = ?", postId).Delete(&model.PostCategory{}).Error; err != nil {
+			return err
+		}
+		if len(categoryIds) == 0 {
+			return nil
+		}
+
+		rows := lo.Map(lo.Uniq(categoryIds), func(id uuid.UUID, _ int) *model.PostCategory {
+			return &model.PostCategory{PostId: postId, CategoryId: id}
+		})
+		return tx.Create(&rows).Error
+	})
+}
+
+func (s *PostCategoryStg) ListByPostIds(postIds []string) ([]*model.PostCategory, error) {
+	var list []*model.PostCategory
+	if len(postIds) == 0 {
+		return list, nil
+	}
+
+	err := s.db.
+		Preload("Category").
+		Where("post_id IN ?", postIds).
+		Find(&list).Error
+	return list, err
+}
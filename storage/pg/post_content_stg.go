@@ -2,6 +2,8 @@ package pg
 
 import (
 	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/google/uuid"
+	"github.com/pgvector/pgvector-go"
 )
 
 type PostContentStg struct {
@@ -22,3 +24,88 @@ func (s *PostContentStg) ListByPostId(postId string) ([]*model.PostContent, erro
 		Error
 	return list, err
 }
+
+func (s *PostContentStg) DeleteByPostId(postId string) error {
+	return s.db.
+		Where("post_id = ?", postId).
+		Delete(&model.PostContent{}).Error
+}
+
+func (s *PostContentStg) UpdateEmbedding(id uuid.UUID, embedding pgvector.Vector) error {
+	return s.db.
+		Model(&model.PostContent{}).
+		Where("id = ?", id).
+		UpdateColumn("embedding", embedding).Error
+}
+
+func (s *PostContentStg) SearchByEmbedding(owner model.LibraryOwner, embedding pgvector.Vector, limit int) ([]*model.PostContentMatch, error) {
+	args := libraryArgs(owner)
+	args["embedding"] = embedding
+	args["limit"] = limit
+
+	var res []*model.PostContentMatch
+	err := s.db.Raw(`
+		SELECT content_id, post_id, similarity FROM (
+			SELECT DISTINCT ON (pc.post_id)
+				pc.id AS content_id,
+				pc.post_id,
+				1 - (pc.embedding <=> @embedding) AS similarity
+			FROM post_contents pc
+			WHERE pc.embedding IS NOT NULL
+				AND pc.post_id IN (SELECT post_id FROM analyze_requests WHERE `+libraryCondition("", owner)+` AND post_id IS NOT NULL)
+			ORDER BY pc.post_id, pc.embedding <=> @embedding
+		) matches
+		ORDER BY similarity DESC
+		LIMIT @limit`,
+		args).
+		Scan(&res).Error
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *PostContentStg) SearchSimilarPosts(postId string, scope model.SimilarPostsScope, owner model.LibraryOwner, limit int) ([]*model.PostContentMatch, error) {
+	scopeFilter := ""
+	switch scope {
+	case model.SimilarScopeLibrary:
+		scopeFilter = "AND pc.post_id IN (SELECT post_id FROM analyze_requests WHERE " + libraryCondition("", owner) + " AND post_id IS NOT NULL)"
+	case model.SimilarScopeOtherChannels:
+		scopeFilter = "AND p.channel_id IS DISTINCT FROM source.channel_id"
+	}
+
+	args := libraryArgs(owner)
+	args["post_id"] = postId
+	args["type"] = model.ContentBigIdea
+	args["candidates"] = limit * 3
+
+	// the nearest neighbours are picked first so the hnsw index can be used,
+	// then re-analyzed posts with several big ideas are deduplicated
+	var res []*model.PostContentMatch
+	err := s.db.Raw(`
+		WITH source AS (
+			SELECT pc.embedding, p.channel_id
+			FROM post_contents pc
+			JOIN posts p ON p.id = pc.post_id
+			WHERE pc.post_id = @post_id AND pc.type = @type AND pc.embedding IS NOT NULL
+			ORDER BY pc.created_at DESC
+			LIMIT 1
+		), neighbours AS (
+			SELECT pc.id AS content_id, pc.post_id, 1 - (pc.embedding <=> source.embedding) AS similarity
+			FROM post_contents pc
+			JOIN posts p ON p.id = pc.post_id AND p.deleted_at IS NULL
+			CROSS JOIN source
+			WHERE pc.type = @type AND pc.embedding IS NOT NULL AND pc.post_id <> @post_id `+scopeFilter+`
+			ORDER BY pc.embedding <=> source.embedding
+			LIMIT @candidates
+		)
+		SELECT DISTINCT ON (post_id) content_id, post_id, similarity
+		FROM neighbours
+		ORDER BY post_id, similarity DESC`,
+		args).
+		Scan(&res).Error
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
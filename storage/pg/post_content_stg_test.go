@@ -0,0 +1,47 @@
+package pg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/google/uuid"
+	"github.com/pgvector/pgvector-go"
+	"github.com/samber/lo"
+)
+
+func TestSearchByEmbeddingQuery(t *testing.T) {
+	userId, workspaceId := uuid.New(), uuid.New()
+	cases := []struct {
+		name      string
+		owner     model.LibraryOwner
+		condition string
+		ownerVar  any
+	}{
+		{"personal library", model.LibraryOwner{UserId: userId}, "WHERE user_id = $", userId},
+		{"workspace library", model.LibraryOwner{UserId: userId, WorkspaceId: &workspaceId}, "WHERE workspace_id = $", &workspaceId},
+	}
+
+	for _, c := range cases {
+		ses, captured := newDryRunStg(t)
+		_, _ = NewPostContentStg(ses).SearchByEmbedding(c.owner, pgvector.NewVector([]float32{0.1, 0.2}), 5)
+
+		for _, part := range []string{c.condition, "SELECT DISTINCT ON (pc.post_id)", "ORDER BY pc.post_id, pc.embedding <=> $"} {
+			if !strings.Contains(captured.sql, part) {
+				t.Errorf("%s: query misses %q:\n%s", c.name, part, captured.sql)
+			}
+		}
+		if c.owner.WorkspaceId == nil && !strings.Contains(captured.sql, "AND workspace_id IS NULL") {
+			t.Errorf("%s: query includes the workspace analyses:\n%s", c.name, captured.sql)
+		}
+		if c.owner.WorkspaceId != nil && strings.Contains(captured.sql, "user_id") {
+			t.Errorf("%s: query is limited to the analyses of the user:\n%s", c.name, captured.sql)
+		}
+		if !lo.Contains(captured.vars, c.ownerVar) || !lo.Contains(captured.vars, any(5)) {
+			t.Errorf("%s: unexpected vars %v", c.name, captured.vars)
+		}
+		if n := strings.Count(captured.sql, "$"); n != len(captured.vars) {
+			t.Errorf("%s: expected %d placeholders, got %d:\n%s", c.name, len(captured.vars), n, captured.sql)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package pg
+
+import (
+	"time"
+
+	"github.com/amahdian/cliplab-be/domain/model"
+	"gorm.io/gorm/clause"
+)
+
+type PostStatSnapshotStg struct {
+	crudStg[*model.PostStatSnapshot]
+}
+
+func NewPostStatSnapshotStg(ses *ormSession) *PostStatSnapshotStg {
+	return &PostStatSnapshotStg{
+		crudStg: crudStg[*model.PostStatSnapshot]{db: ses.db},
+	}
+}
+
+func (s *PostStatSnapshotStg) CreateSchedule(snapshots []*model.PostStatSnapshot) error {
+	if len(snapshots) == 0 {
+		return nil
+	}
+	return s.db.
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "post_id"}, {Name: "day_offset"}},
+			DoNothing: true,
+		}).
+		Create(&snapshots).Error
+}
+
+func (s *PostStatSnapshotStg) ClaimDue(now, staleBefore time.Time, limit int) ([]*model.PostStatSnapshot, error) {
+	var list []*model.PostStatSnapshot
+	err := s.db.Raw(`UPDATE post_stat_snapshots
+		SET status = ?, updated_at = ?
+		WHERE id IN (
+			SELECT id FROM post_stat_snapshots
+			WHERE (status = ? AND due_at <= ?) OR (status = ? AND updated_at < ?)
+			ORDER BY due_at
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		model.SnapshotStatusQueued, now,
+		model.SnapshotStatusPending, now, model.SnapshotStatusQueued, staleBefore,
+		limit,
+	).Scan(&list).Error
+	return list, err
+}
+
+// calibrationPairs joins every taken snapshot with the baseline of its post
+// and the channel median plays stored in the outlier stats of the analysis.
+const calibrationPairs = `SELECT s.day_offset,
+		b.viral_score,
+		s.play_count::float / NULLIF(b.play_count, 0) AS view_growth,
+		s.play_count / NULLIF((pa.outlier_stats -> 'views' ->> 'median')::float, 0) AS outlier_multiple
+	FROM post_stat_snapshots s
+	JOIN post_stat_snapshots b ON b.post_id = s.post_id AND b.day_offset = 0
+	LEFT JOIN post_analyses pa ON pa.post_id = s.post_id
+	WHERE s.day_offset > 0 AND s.status = ?`
+
+func (s *PostStatSnapshotStg) SummarizeCalibration(bucketSize int) ([]*model.CalibrationBucket, error) {
+	var res []*model.CalibrationBucket
+	err := s.db.Raw(`SELECT day_offset,
+			LEAST(viral_score / ?::int, (100 / ?::int) - 1) * ?::int AS min_score,
+			COUNT(*) AS posts,
+			AVG(view_growth) AS avg_view_growth,
+			PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY view_growth) AS median_view_growth,
+			AVG(outlier_multiple) AS avg_outlier_multiple,
+			PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY outlier_multiple) AS median_outlier_multiple
+		FROM (`+calibrationPairs+`) pairs
+		GROUP BY day_offset, min_score
+		ORDER BY day_offset, min_score`,
+		bucketSize, bucketSize, bucketSize, model.SnapshotStatusDone,
+	).Scan(&res).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, bucket := range res {
+		bucket.MaxScore = calibrationMaxScore(bucket.MinScore, bucketSize)
+	}
+	return res, nil
+}
+
+// calibrationMaxScore returns the highest score of the bucket starting at minScore.
+// The last bucket also holds the scores left over by the division.
+func calibrationMaxScore(minScore, bucketSize int) int {
+	if minScore+2*bucketSize > 100 {
+		return 100
+	}
+	return minScore + bucketSize - 1
+}
+
+func (s *PostStatSnapshotStg) CorrelateCalibration() ([]*model.CalibrationCorrelation, error) {
+	var res []*model.CalibrationCorrelation
+	err := s.db.Raw(`SELECT day_offset,
+			COUNT(*) AS posts,
+			CORR(viral_score, view_growth) AS view_growth,
+			CORR(viral_score, outlier_multiple) AS outlier_multiple
+		FROM (`+calibrationPairs+`) pairs
+		GROUP BY day_offset
+		ORDER BY day_offset`,
+		model.SnapshotStatusDone,
+	).Scan(&res).Error
+	return res, err
+}
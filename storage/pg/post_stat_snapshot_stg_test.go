@@ -0,0 +1,95 @@
+package pg
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/amahdian/cliplab-be/domain/model"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+// capturedQuery is the last query built by a dry run session.
+type capturedQuery struct {
+	sql  string
+	vars []any
+}
+
+// newDryRunStg returns a session building the queries without a database, along with the last query it built.
+func newDryRunStg(t *testing.T) (*ormSession, *capturedQuery) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:                 true,
+		SkipDefaultTransaction: true,
+		DisableAutomaticPing:   true,
+		Logger:                 gormLogger.Default.LogMode(gormLogger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	captured := &capturedQuery{}
+	capture := func(db *gorm.DB) {
+		captured.sql = db.Statement.SQL.String()
+		captured.vars = db.Statement.Vars
+	}
+	if err = db.Callback().Row().Before("gorm:row").Register("test:capture", capture); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if err = db.Callback().Update().After("gorm:update").Register("test:capture", capture); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	return &ormSession{db: db}, captured
+}
+
+func TestSummarizeCalibrationQuery(t *testing.T) {
+	ses, captured := newDryRunStg(t)
+	_, _ = NewPostStatSnapshotStg(ses).SummarizeCalibration(20)
+
+	want := []any{20, 20, 20, model.SnapshotStatusDone}
+	if !reflect.DeepEqual(captured.vars, want) {
+		t.Errorf("expected vars %v, got %v", want, captured.vars)
+	}
+	if n := strings.Count(captured.sql, "$"); n != len(want) {
+		t.Errorf("expected %d placeholders, got %d:\n%s", len(want), n, captured.sql)
+	}
+	for _, part := range []string{"GROUP BY day_offset, min_score", "b.day_offset = 0", "s.day_offset > 0"} {
+		if !strings.Contains(captured.sql, part) {
+			t.Errorf("query misses %q:\n%s", part, captured.sql)
+		}
+	}
+}
+
+func TestCorrelateCalibrationQuery(t *testing.T) {
+	ses, captured := newDryRunStg(t)
+	_, _ = NewPostStatSnapshotStg(ses).CorrelateCalibration()
+
+	want := []any{model.SnapshotStatusDone}
+	if !reflect.DeepEqual(captured.vars, want) {
+		t.Errorf("expected vars %v, got %v", want, captured.vars)
+	}
+	if n := strings.Count(captured.sql, "$"); n != len(want) {
+		t.Errorf("expected %d placeholders, got %d:\n%s", len(want), n, captured.sql)
+	}
+	if !strings.Contains(captured.sql, "GROUP BY day_offset") {
+		t.Errorf("query is not grouped by day offset:\n%s", captured.sql)
+	}
+}
+
+func TestCalibrationMaxScore(t *testing.T) {
+	cases := []struct {
+		minScore, bucketSize, want int
+	}{
+		{0, 20, 19},
+		{60, 20, 79},
+		{80, 20, 100},
+		{30, 30, 59},
+		{60, 30, 100},
+		{75, 25, 100},
+	}
+	for _, c := range cases {
+		if got := calibrationMaxScore(c.minScore, c.bucketSize); got != c.want {
+			t.Errorf("calibrationMaxScore(%d, %d) = %d, want %d", c.minScore, c.bucketSize, got, c.want)
+		}
+	}
+}
@@ -2,8 +2,17 @@ package pg
 
 import (
 	"github.com/amahdian/cliplab-be/domain/model"
+	"gorm.io/gorm"
 )
 
+// latestAnalysisJoin joins the most recent analysis of each post as "pa".
+const latestAnalysisJoin = `LEFT JOIN LATERAL (
+	SELECT viral_score, big_idea FROM post_analyses
+	WHERE post_analyses.post_id = p.id
+	ORDER BY updated_at DESC
+	LIMIT 1
+) pa ON TRUE`
+
 type PostStg struct {
 	crudStg[*model.Post]
 }
@@ -22,3 +31,77 @@ func (s *PostStg) FindByUrl(url string) (*model.Post, error) {
 	}
 	return res, nil
 }
+
+func (s *PostStg) ListByHashIds(ids []string) ([]*model.Post, error) {
+	var list []*model.Post
+	if len(ids) == 0 {
+		return list, nil
+	}
+
+	err := s.db.Where("id IN ?", ids).Find(&list).Error
+	return list, err
+}
+
+func (s *PostStg) ListLibrary(owner model.LibraryOwner, category string, page, size int) ([]*model.LibraryPost, int64, error) {
+	query := s.db.
+		Table("analyze_requests ar").
+		Joins("JOIN posts p ON p.id = ar.post_id AND p.deleted_at IS NULL").
+		Where(libraryCondition("ar.", owner), libraryArgs(owner)).
+		Where("ar.status = ?", model.RequestStatusCompleted).
+		Scopes(withCategory(category, "p.id"))
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var list []*model.LibraryPost
+	err := query.
+		Select(`ar.id AS request_id,
+			ar.updated_at AS analyzed_at,
+			p.id AS post_id,
+			p.link,
+			p.image_url,
+			p.user_anchor,
+			p.post_date,
+			p.like_count,
+			p.comment_count,
+			p.video_play_count,
+			COALESCE(pa.viral_score, 0) AS viral_score,
+			COALESCE(pa.big_idea, '') AS big_idea`).
+		Joins(latestAnalysisJoin).
+		Order("ar.updated_at DESC").
+		Limit(size).
+		Offset(page * size).
+		Scan(&list).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return list, total, nil
+}
+
+func (s *PostStg) SummarizeBenchmarks(category string) ([]*model.NicheBenchmark, error) {
+	query := s.db.
+		Table("categories c").
+		Select(`c.path AS category,
+			COUNT(*) AS posts,
+			AVG(p.like_count) AS avg_likes,
+			AVG(p.comment_count) AS avg_comments,
+			AVG(p.video_play_count) AS avg_plays,
+			PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY p.video_play_count) AS median_plays,
+			COALESCE(AVG(pa.viral_score), 0) AS avg_viral_score`).
+		Joins("JOIN post_categories pcat ON pcat.category_id = c.id").
+		Joins("JOIN posts p ON p.id = pcat.post_id AND p.deleted_at IS NULL").
+		Joins(latestAnalysisJoin).
+		Where("c.suggested = FALSE")
+	if category != "" {
+		query = query.Where("(LOWER(c.path) = LOWER(?) OR LOWER(c.path) LIKE LOWER(?))", category, likeEscaper.Replace(category)+"/%")
+	}
+
+	var list []*model.NicheBenchmark
+	err := query.
+		Group("c.path").
+		Order("posts DESC, c.path ASC").
+		Scan(&list).Error
+	return list, err
+}
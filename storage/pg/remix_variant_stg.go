@@ -0,0 +1,36 @@
+package pg
+
+import (
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/google/uuid"
+)
+
+type RemixVariantStg struct {
+	crudStg[*model.RemixVariant]
+}
+
+func NewRemixVariantStg(ses *ormSession) *RemixVariantStg {
+	return &RemixVariantStg{
+		crudStg: crudStg[*model.RemixVariant]{db: ses.db},
+	}
+}
+
+func (s *RemixVariantStg) ListByUserAndPost(userId uuid.UUID, postId string, section model.RemixSection, favoritesOnly bool) ([]*model.RemixVariant, error) {
+	query := s.db.Where("user_id = ? AND post_id = ?", userId, postId)
+	if section != "" {
+		query = query.Where("section = ?", section)
+	}
+	if favoritesOnly {
+		query = query.Where("favorite")
+	}
+
+	var list []*model.RemixVariant
+	err := query.Order("created_at DESC").Find(&list).Error
+	return list, err
+}
+
+func (s *RemixVariantStg) ListByUserId(userId uuid.UUID) ([]*model.RemixVariant, error) {
+	var list []*model.RemixVariant
+	err := s.db.Where("user_id = ?", userId).Order("created_at DESC").Find(&list).Error
+	return list, err
+}
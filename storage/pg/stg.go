@@ -132,3 +132,67 @@ func (stg *Stg) Channel(ctx context.Context) storage.ChannelStorage {
 func (stg *Stg) ChannelHistory(ctx context.Context) storage.ChannelHistoryStorage {
 	return NewChannelHistoryStg(stg.mustOrmSession(ctx))
 }
+
+func (stg *Stg) Category(ctx context.Context) storage.CategoryStorage {
+	return NewCategoryStg(stg.mustOrmSession(ctx))
+}
+
+func (stg *Stg) PostCategory(ctx context.Context) storage.PostCategoryStorage {
+	return NewPostCategoryStg(stg.mustOrmSession(ctx))
+}
+
+func (stg *Stg) ChatMessage(ctx context.Context) storage.ChatMessageStorage {
+	return NewChatMessageStg(stg.mustOrmSession(ctx))
+}
+
+func (stg *Stg) RemixVariant(ctx context.Context) storage.RemixVariantStorage {
+	return NewRemixVariantStg(stg.mustOrmSession(ctx))
+}
+
+func (stg *Stg) Comparison(ctx context.Context) storage.ComparisonStorage {
+	return NewComparisonStg(stg.mustOrmSession(ctx))
+}
+
+func (stg *Stg) PostStatSnapshot(ctx context.Context) storage.PostStatSnapshotStorage {
+	return NewPostStatSnapshotStg(stg.mustOrmSession(ctx))
+}
+
+func (stg *Stg) ChannelReel(ctx context.Context) storage.ChannelReelStorage {
+	return NewChannelReelStg(stg.mustOrmSession(ctx))
+}
+
+func (stg *Stg) UserCode(ctx context.Context) storage.UserCodeStorage {
+	return NewUserCodeStg(stg.mustOrmSession(ctx))
+}
+
+func (stg *Stg) UserSession(ctx context.Context) storage.UserSessionStorage {
+	return NewUserSessionStg(stg.mustOrmSession(ctx))
+}
+
+func (stg *Stg) RefreshToken(ctx context.Context) storage.RefreshTokenStorage {
+	return NewRefreshTokenStg(stg.mustOrmSession(ctx))
+}
+
+func (stg *Stg) UserIdentity(ctx context.Context) storage.UserIdentityStorage {
+	return NewUserIdentityStg(stg.mustOrmSession(ctx))
+}
+
+func (stg *Stg) UserRecoveryCode(ctx context.Context) storage.UserRecoveryCodeStorage {
+	return NewUserRecoveryCodeStg(stg.mustOrmSession(ctx))
+}
+
+func (stg *Stg) ApiKey(ctx context.Context) storage.ApiKeyStorage {
+	return NewApiKeyStg(stg.mustOrmSession(ctx))
+}
+
+func (stg *Stg) Workspace(ctx context.Context) storage.WorkspaceStorage {
+	return NewWorkspaceStg(stg.mustOrmSession(ctx))
+}
+
+func (stg *Stg) WorkspaceMember(ctx context.Context) storage.WorkspaceMemberStorage {
+	return NewWorkspaceMemberStg(stg.mustOrmSession(ctx))
+}
+
+func (stg *Stg) WorkspaceInvitation(ctx context.Context) storage.WorkspaceInvitationStorage {
+	return NewWorkspaceInvitationStg(stg.mustOrmSession(ctx))
+}
@@ -2,8 +2,10 @@ package pg
 
 import (
 	"fmt"
+	"strings"
 	"sync"
 
+	"github.com/amahdian/cliplab-be/domain/model"
 	"gorm.io/gorm"
 	"gorm.io/gorm/schema"
 )
@@ -31,3 +33,36 @@ func getGormSchema(model interface{}) *schema.Schema {
 func withAlias(table schema.Tabler, alias string) string {
 	return fmt.Sprintf("%s AS %s", table.TableName(), alias)
 }
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// libraryCondition selects the analyze requests of the library of the owner, the columns are prefixed by prefix.
+// The condition uses the named arguments of libraryArgs.
+func libraryCondition(prefix string, owner model.LibraryOwner) string {
+	if owner.WorkspaceId != nil {
+		return prefix + "workspace_id = @workspace_id"
+	}
+	return prefix + "user_id = @user_id AND " + prefix + "workspace_id IS NULL"
+}
+
+func libraryArgs(owner model.LibraryOwner) map[string]interface{} {
+	return map[string]interface{}{
+		"user_id":      owner.UserId,
+		"workspace_id": owner.WorkspaceId,
+	}
+}
+
+// withCategory keeps the posts of the given category or any of its subcategories,
+// the post id column is given by postIdColumn.
+func withCategory(category, postIdColumn string) gormScope {
+	return func(db *gorm.DB) *gorm.DB {
+		if category == "" {
+			return db
+		}
+		return db.Where(fmt.Sprintf(`EXISTS (
+			SELECT 1 FROM post_categories pcat
+			JOIN categories c ON c.id = pcat.category_id
+			WHERE pcat.post_id = %s AND (LOWER(c.path) = LOWER(?) OR LOWER(c.path) LIKE LOWER(?))
+		)`, postIdColumn), category, likeEscaper.Replace(category)+"/%")
+	}
+}
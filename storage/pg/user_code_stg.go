@@ -0,0 +1,89 @@
+package pg
+
+import (
+	"errors"
+	"time"
+
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type UserCodeStg struct {
+	crudStg[*model.UserCode]
+}
+
+func NewUserCodeStg(ses *ormSession) *UserCodeStg {
+	return &UserCodeStg{
+		crudStg: crudStg[*model.UserCode]{db: ses.db},
+	}
+}
+
+func (s *UserCodeStg) FindLatestActive(userId uuid.UUID, purpose model.CodePurpose) (*model.UserCode, error) {
+	return s.findLatest(s.db.Where("consumed_at IS NULL"), userId, purpose)
+}
+
+func (s *UserCodeStg) FindActiveByHash(purpose model.CodePurpose, codeHash string) (*model.UserCode, error) {
+	var code *model.UserCode
+	err := s.db.
+		Where("purpose = ? AND code_hash = ? AND consumed_at IS NULL", purpose, codeHash).
+		First(&code).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return code, nil
+}
+
+func (s *UserCodeStg) FindLatest(userId uuid.UUID, purpose model.CodePurpose) (*model.UserCode, error) {
+	return s.findLatest(s.db, userId, purpose)
+}
+
+func (s *UserCodeStg) findLatest(query *gorm.DB, userId uuid.UUID, purpose model.CodePurpose) (*model.UserCode, error) {
+	var code *model.UserCode
+	err := query.
+		Where("user_id = ? AND purpose = ?", userId, purpose).
+		Order("created_at DESC").
+		First(&code).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return code, nil
+}
+
+func (s *UserCodeStg) CountSince(userId uuid.UUID, purpose model.CodePurpose, since time.Time) (int64, error) {
+	var count int64
+	err := s.db.
+		Model(&model.UserCode{}).
+		Where("user_id = ? AND purpose = ? AND created_at >= ?", userId, purpose, since).
+		Count(&count).Error
+	return count, err
+}
+
+func (s *UserCodeStg) ClaimAttempt(id uuid.UUID, maxAttempts int) (bool, error) {
+	res := s.db.
+		Model(&model.UserCode{}).
+		Where("id = ? AND attempts < ?", id, maxAttempts).
+		Update("attempts", gorm.Expr("attempts + 1"))
+	return res.RowsAffected > 0, res.Error
+}
+
+func (s *UserCodeStg) Consume(id uuid.UUID) (bool, error) {
+	res := s.db.
+		Model(&model.UserCode{}).
+		Where("id = ? AND consumed_at IS NULL", id).
+		Update("consumed_at", time.Now())
+	return res.RowsAffected > 0, res.Error
+}
+
+func (s *UserCodeStg) ConsumeAll(userId uuid.UUID, purpose model.CodePurpose) error {
+	return s.db.
+		Model(&model.UserCode{}).
+		Where("user_id = ? AND purpose = ? AND consumed_at IS NULL", userId, purpose).
+		Update("consumed_at", time.Now()).Error
+}
@@ -0,0 +1,37 @@
+package pg
+
+import (
+	"errors"
+
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type UserIdentityStg struct {
+	crudStg[*model.UserIdentity]
+}
+
+func NewUserIdentityStg(ses *ormSession) *UserIdentityStg {
+	return &UserIdentityStg{
+		crudStg: crudStg[*model.UserIdentity]{db: ses.db},
+	}
+}
+
+func (s *UserIdentityStg) FindByProvider(provider model.Provider, providerId string) (*model.UserIdentity, error) {
+	var identity *model.UserIdentity
+	err := s.db.Where("provider = ? AND provider_id = ?", provider, providerId).First(&identity).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return identity, nil
+}
+
+func (s *UserIdentityStg) ListByUserId(userId uuid.UUID) ([]*model.UserIdentity, error) {
+	var list []*model.UserIdentity
+	err := s.db.Where("user_id = ?", userId).Order("created_at").Find(&list).Error
+	return list, err
+}
@@ -0,0 +1,32 @@
+package pg
+
+import (
+	"time"
+
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/google/uuid"
+)
+
+type UserRecoveryCodeStg struct {
+	crudStg[*model.UserRecoveryCode]
+}
+
+func NewUserRecoveryCodeStg(ses *ormSession) *UserRecoveryCodeStg {
+	return &UserRecoveryCodeStg{
+		crudStg: crudStg[*model.UserRecoveryCode]{db: ses.db},
+	}
+}
+
+func (s *UserRecoveryCodeStg) Claim(userId uuid.UUID, codeHash string) (bool, error) {
+	res := s.db.
+		Model(&model.UserRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userId, codeHash).
+		Update("used_at", time.Now())
+	return res.RowsAffected > 0, res.Error
+}
+
+func (s *UserRecoveryCodeStg) DeleteByUserId(userId uuid.UUID) error {
+	return s.db.
+		Where("user_id = ?", userId).
+		Delete(&model.UserRecoveryCode{}).Error
+}
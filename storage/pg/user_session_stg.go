@@ -0,0 +1,74 @@
+package pg
+
+import (
+	"errors"
+	"time"
+
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type UserSessionStg struct {
+	crudStg[*model.UserSession]
+}
+
+func NewUserSessionStg(ses *ormSession) *UserSessionStg {
+	return &UserSessionStg{
+		crudStg: crudStg[*model.UserSession]{db: ses.db},
+	}
+}
+
+func (s *UserSessionStg) ListActiveByUserId(userId uuid.UUID) ([]*model.UserSession, error) {
+	var list []*model.UserSession
+	err := s.db.
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userId, time.Now()).
+		Order("last_used_at DESC").
+		Find(&list).Error
+	return list, err
+}
+
+func (s *UserSessionStg) Revoke(userId, id uuid.UUID) (bool, error) {
+	res := s.db.
+		Model(&model.UserSession{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userId).
+		Update("revoked_at", time.Now())
+	return res.RowsAffected > 0, res.Error
+}
+
+func (s *UserSessionStg) RevokeAll(userId uuid.UUID) error {
+	return s.db.
+		Model(&model.UserSession{}).
+		Where("user_id = ? AND revoked_at IS NULL", userId).
+		Update("revoked_at", time.Now()).Error
+}
+
+type RefreshTokenStg struct {
+	crudStg[*model.RefreshToken]
+}
+
+func NewRefreshTokenStg(ses *ormSession) *RefreshTokenStg {
+	return &RefreshTokenStg{
+		crudStg: crudStg[*model.RefreshToken]{db: ses.db},
+	}
+}
+
+func (s *RefreshTokenStg) FindByHash(tokenHash string) (*model.RefreshToken, error) {
+	var token *model.RefreshToken
+	err := s.db.Where("token_hash = ?", tokenHash).First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return token, nil
+}
+
+func (s *RefreshTokenStg) MarkUsed(id uuid.UUID) (bool, error) {
+	res := s.db.
+		Model(&model.RefreshToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	return res.RowsAffected > 0, res.Error
+}
@@ -2,8 +2,10 @@ package pg
 
 import (
 	"errors"
+	"time"
 
 	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
@@ -33,14 +35,27 @@ func (stg *UserStg) FindByEmail(email string) (user *model.User, err error) {
 	return
 }
 
-func (stg *UserStg) FindByProvider(provider model.Provider, providerID string) (*model.User, error) {
-	var user model.User
-	result := stg.db.Where("provider = ? AND provider_id = ?", provider, providerID).First(&user)
-	if result.Error != nil {
-		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			return nil, nil
-		}
-		return nil, result.Error
-	}
-	return &user, result.Error
+func (stg *UserStg) ClaimTotpStep(id uuid.UUID, step int64) (bool, error) {
+	res := stg.db.
+		Model(&model.User{}).
+		Where("id = ? AND totp_last_step < ?", id, step).
+		Update("totp_last_step", step)
+	return res.RowsAffected > 0, res.Error
+}
+
+func (stg *UserStg) ListDueForDeletion(before time.Time, limit int) ([]*model.User, error) {
+	var list []*model.User
+	err := stg.db.
+		Where("deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= ?", before).
+		Order("deletion_scheduled_at").
+		Limit(limit).
+		Find(&list).Error
+	return list, err
+}
+
+func (stg *UserStg) CancelDeletion(id uuid.UUID) error {
+	return stg.db.
+		Model(&model.User{}).
+		Where("id = ?", id).
+		Update("deletion_scheduled_at", nil).Error
 }
@@ -0,0 +1,126 @@
+package pg
+
+import (
+	"errors"
+	"time"
+
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type WorkspaceStg struct {
+	crudStg[*model.Workspace]
+}
+
+func NewWorkspaceStg(ses *ormSession) *WorkspaceStg {
+	return &WorkspaceStg{
+		crudStg: crudStg[*model.Workspace]{db: ses.db},
+	}
+}
+
+type WorkspaceMemberStg struct {
+	crudStg[*model.WorkspaceMember]
+}
+
+func NewWorkspaceMemberStg(ses *ormSession) *WorkspaceMemberStg {
+	return &WorkspaceMemberStg{
+		crudStg: crudStg[*model.WorkspaceMember]{db: ses.db},
+	}
+}
+
+func (s *WorkspaceMemberStg) Find(workspaceId, userId uuid.UUID) (*model.WorkspaceMember, error) {
+	var member *model.WorkspaceMember
+	err := s.db.
+		Preload("Workspace").
+		Where("workspace_id = ? AND user_id = ?", workspaceId, userId).
+		First(&member).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return member, nil
+}
+
+func (s *WorkspaceMemberStg) ListByWorkspaceId(workspaceId uuid.UUID) ([]*model.WorkspaceMember, error) {
+	var list []*model.WorkspaceMember
+	err := s.db.
+		Preload("User").
+		Where("workspace_id = ?", workspaceId).
+		Order("created_at").
+		Find(&list).Error
+	return list, err
+}
+
+func (s *WorkspaceMemberStg) ListByUserId(userId uuid.UUID) ([]*model.WorkspaceMember, error) {
+	var list []*model.WorkspaceMember
+	err := s.db.
+		Preload("Workspace").
+		Where("user_id = ?", userId).
+		Order("created_at").
+		Find(&list).Error
+	return list, err
+}
+
+func (s *WorkspaceMemberStg) CountByRole(workspaceId uuid.UUID, role model.WorkspaceRole) (int64, error) {
+	var count int64
+	err := s.db.
+		Model(&model.WorkspaceMember{}).
+		Where("workspace_id = ? AND role = ?", workspaceId, role).
+		Count(&count).Error
+	return count, err
+}
+
+func (s *WorkspaceMemberStg) UpdateRole(workspaceId, userId uuid.UUID, role model.WorkspaceRole) error {
+	return s.db.
+		Model(&model.WorkspaceMember{}).
+		Where("workspace_id = ? AND user_id = ?", workspaceId, userId).
+		Update("role", role).Error
+}
+
+func (s *WorkspaceMemberStg) Delete(workspaceId, userId uuid.UUID) error {
+	return s.db.
+		Where("workspace_id = ? AND user_id = ?", workspaceId, userId).
+		Delete(&model.WorkspaceMember{}).Error
+}
+
+type WorkspaceInvitationStg struct {
+	crudStg[*model.WorkspaceInvitation]
+}
+
+func NewWorkspaceInvitationStg(ses *ormSession) *WorkspaceInvitationStg {
+	return &WorkspaceInvitationStg{
+		crudStg: crudStg[*model.WorkspaceInvitation]{db: ses.db},
+	}
+}
+
+func (s *WorkspaceInvitationStg) FindPendingByHash(tokenHash string) (*model.WorkspaceInvitation, error) {
+	var invitation *model.WorkspaceInvitation
+	err := s.db.
+		Where("token_hash = ? AND accepted_at IS NULL", tokenHash).
+		First(&invitation).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return invitation, nil
+}
+
+func (s *WorkspaceInvitationStg) ListPendingByWorkspaceId(workspaceId uuid.UUID) ([]*model.WorkspaceInvitation, error) {
+	var list []*model.WorkspaceInvitation
+	err := s.db.
+		Where("workspace_id = ? AND accepted_at IS NULL AND expires_at > ?", workspaceId, time.Now()).
+		Order("created_at DESC").
+		Find(&list).Error
+	return list, err
+}
+
+func (s *WorkspaceInvitationStg) DeletePending(workspaceId uuid.UUID, email string) error {
+	return s.db.
+		Where("workspace_id = ? AND LOWER(email) = LOWER(?) AND accepted_at IS NULL", workspaceId, email).
+		Delete(&model.WorkspaceInvitation{}).Error
+}
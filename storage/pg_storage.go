@@ -11,6 +11,22 @@ type PgStorage interface {
 	PostAnalysis(ctx context.Context) PostAnalysisStorage
 	Channel(ctx context.Context) ChannelStorage
 	ChannelHistory(ctx context.Context) ChannelHistoryStorage
+	Category(ctx context.Context) CategoryStorage
+	PostCategory(ctx context.Context) PostCategoryStorage
+	ChatMessage(ctx context.Context) ChatMessageStorage
+	RemixVariant(ctx context.Context) RemixVariantStorage
+	Comparison(ctx context.Context) ComparisonStorage
+	PostStatSnapshot(ctx context.Context) PostStatSnapshotStorage
+	ChannelReel(ctx context.Context) ChannelReelStorage
+	UserCode(ctx context.Context) UserCodeStorage
+	UserSession(ctx context.Context) UserSessionStorage
+	RefreshToken(ctx context.Context) RefreshTokenStorage
+	UserIdentity(ctx context.Context) UserIdentityStorage
+	UserRecoveryCode(ctx context.Context) UserRecoveryCodeStorage
+	ApiKey(ctx context.Context) ApiKeyStorage
+	Workspace(ctx context.Context) WorkspaceStorage
+	WorkspaceMember(ctx context.Context) WorkspaceMemberStorage
+	WorkspaceInvitation(ctx context.Context) WorkspaceInvitationStorage
 }
 
 type Session interface {
@@ -2,10 +2,14 @@ package storage
 
 import (
 	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/google/uuid"
 )
 
 type PostAnalysisStorage interface {
 	PgCrudStorage[*model.PostAnalysis]
 
 	FindByPostId(id string) (*model.PostAnalysis, error)
+	ListByPostIds(ids []string) ([]*model.PostAnalysis, error)
+	// ListAfterId returns the analyses ordered by id, starting after the given one, to walk the whole table in batches.
+	ListAfterId(afterId uuid.UUID, limit int) ([]*model.PostAnalysis, error)
 }
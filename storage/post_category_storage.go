@@ -0,0 +1,14 @@
+package storage
+
+import (
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/google/uuid"
+)
+
+type PostCategoryStorage interface {
+	PgCrudStorage[*model.PostCategory]
+
+	// ReplaceForPost sets the categories of the post, removing the previous ones.
+	ReplaceForPost(postId string, categoryIds []uuid.UUID) error
+	ListByPostIds(postIds []string) ([]*model.PostCategory, error)
+}
@@ -2,10 +2,18 @@ package storage
 
 import (
 	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/google/uuid"
+	"github.com/pgvector/pgvector-go"
 )
 
 type PostContentStorage interface {
 	PgCrudStorage[*model.PostContent]
 
 	ListByPostId(postId string) ([]*model.PostContent, error)
+	DeleteByPostId(postId string) error
+	UpdateEmbedding(id uuid.UUID, embedding pgvector.Vector) error
+	// SearchByEmbedding returns the best matching content of each post in the library of the owner, most similar first.
+	SearchByEmbedding(owner model.LibraryOwner, embedding pgvector.Vector, limit int) ([]*model.PostContentMatch, error)
+	// SearchSimilarPosts returns the posts whose big idea is the closest to the big idea of the given post.
+	SearchSimilarPosts(postId string, scope model.SimilarPostsScope, owner model.LibraryOwner, limit int) ([]*model.PostContentMatch, error)
 }
@@ -0,0 +1,19 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/amahdian/cliplab-be/domain/model"
+)
+
+type PostStatSnapshotStorage interface {
+	PgCrudStorage[*model.PostStatSnapshot]
+
+	// CreateSchedule inserts the snapshots, skipping the day offsets already scheduled for the post.
+	CreateSchedule(snapshots []*model.PostStatSnapshot) error
+	// ClaimDue marks up to limit pending snapshots due before now as queued and returns them.
+	// Snapshots queued before staleBefore are claimed again, as their queue message was lost.
+	ClaimDue(now, staleBefore time.Time, limit int) ([]*model.PostStatSnapshot, error)
+	SummarizeCalibration(bucketSize int) ([]*model.CalibrationBucket, error)
+	CorrelateCalibration() ([]*model.CalibrationCorrelation, error)
+}
@@ -9,4 +9,9 @@ type PostStorage interface {
 
 	FindByHashId(id string) (*model.Post, error)
 	FindByUrl(url string) (*model.Post, error)
+	ListByHashIds(ids []string) ([]*model.Post, error)
+	// ListLibrary returns a page of the posts analyzed into the library of the owner, most recent first.
+	ListLibrary(owner model.LibraryOwner, category string, page, size int) ([]*model.LibraryPost, int64, error)
+	// SummarizeBenchmarks returns the benchmark of each category matching the given one, all categories if empty.
+	SummarizeBenchmarks(category string) ([]*model.NicheBenchmark, error)
 }
@@ -0,0 +1,14 @@
+package storage
+
+import (
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/google/uuid"
+)
+
+type RemixVariantStorage interface {
+	PgCrudStorage[*model.RemixVariant]
+
+	// ListByUserAndPost returns the variants of the post, newest first. An empty section matches all sections.
+	ListByUserAndPost(userId uuid.UUID, postId string, section model.RemixSection, favoritesOnly bool) ([]*model.RemixVariant, error)
+	ListByUserId(userId uuid.UUID) ([]*model.RemixVariant, error)
+}
@@ -0,0 +1,24 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/google/uuid"
+)
+
+type UserCodeStorage interface {
+	PgCrudStorage[*model.UserCode]
+
+	// FindLatestActive returns the latest unconsumed code of the user for the purpose, or nil.
+	FindLatestActive(userId uuid.UUID, purpose model.CodePurpose) (*model.UserCode, error)
+	FindActiveByHash(purpose model.CodePurpose, codeHash string) (*model.UserCode, error)
+	FindLatest(userId uuid.UUID, purpose model.CodePurpose) (*model.UserCode, error)
+	CountSince(userId uuid.UUID, purpose model.CodePurpose, since time.Time) (int64, error)
+	// ClaimAttempt counts an attempt against the code and reports false once its attempts are used up.
+	ClaimAttempt(id uuid.UUID, maxAttempts int) (bool, error)
+	// Consume marks the code as consumed and reports false when it was already consumed.
+	Consume(id uuid.UUID) (bool, error)
+	// ConsumeAll marks every unconsumed code of the user for the purpose as consumed.
+	ConsumeAll(userId uuid.UUID, purpose model.CodePurpose) error
+}
@@ -0,0 +1,14 @@
+package storage
+
+import (
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/google/uuid"
+)
+
+type UserIdentityStorage interface {
+	PgCrudStorage[*model.UserIdentity]
+
+	// FindByProvider returns the identity of the provider account, or nil.
+	FindByProvider(provider model.Provider, providerId string) (*model.UserIdentity, error)
+	ListByUserId(userId uuid.UUID) ([]*model.UserIdentity, error)
+}
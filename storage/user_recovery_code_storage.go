@@ -0,0 +1,14 @@
+package storage
+
+import (
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/google/uuid"
+)
+
+type UserRecoveryCodeStorage interface {
+	PgCrudStorage[*model.UserRecoveryCode]
+
+	// Claim marks the unused code of the user with the hash as used and reports false when there is none.
+	Claim(userId uuid.UUID, codeHash string) (bool, error)
+	DeleteByUserId(userId uuid.UUID) error
+}
@@ -0,0 +1,24 @@
+package storage
+
+import (
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/google/uuid"
+)
+
+type UserSessionStorage interface {
+	PgCrudStorage[*model.UserSession]
+
+	ListActiveByUserId(userId uuid.UUID) ([]*model.UserSession, error)
+	// Revoke revokes the session of the user, reporting false when there was no active one.
+	Revoke(userId, id uuid.UUID) (bool, error)
+	RevokeAll(userId uuid.UUID) error
+}
+
+type RefreshTokenStorage interface {
+	PgCrudStorage[*model.RefreshToken]
+
+	// FindByHash returns the token with the hash, or nil.
+	FindByHash(tokenHash string) (*model.RefreshToken, error)
+	// MarkUsed marks the token as used, reporting false when it was already used.
+	MarkUsed(id uuid.UUID) (bool, error)
+}
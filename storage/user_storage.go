@@ -1,6 +1,8 @@
 package storage
 
 import (
+	"time"
+
 	"github.com/amahdian/cliplab-be/domain/model"
 	"github.com/google/uuid"
 )
@@ -9,5 +11,9 @@ type UserStorage interface {
 	PgCrudStorage[*model.User]
 	FindByEmail(email string) (*model.User, error)
 	FindById(id uuid.UUID) (*model.User, error)
-	FindByProvider(provider model.Provider, providerID string) (*model.User, error)
+	// ClaimTotpStep records the time step of an accepted code and reports false when it, or a later one, was already used.
+	ClaimTotpStep(id uuid.UUID, step int64) (bool, error)
+	// ListDueForDeletion returns the users whose deletion is scheduled before the given time.
+	ListDueForDeletion(before time.Time, limit int) ([]*model.User, error)
+	CancelDeletion(id uuid.UUID) error
 }
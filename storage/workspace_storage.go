@@ -0,0 +1,32 @@
+package storage
+
+import (
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/google/uuid"
+)
+
+type WorkspaceStorage interface {
+	PgCrudStorage[*model.Workspace]
+}
+
+type WorkspaceMemberStorage interface {
+	PgCrudStorage[*model.WorkspaceMember]
+
+	// Find returns the membership of the user in the workspace, or nil.
+	Find(workspaceId, userId uuid.UUID) (*model.WorkspaceMember, error)
+	ListByWorkspaceId(workspaceId uuid.UUID) ([]*model.WorkspaceMember, error)
+	ListByUserId(userId uuid.UUID) ([]*model.WorkspaceMember, error)
+	CountByRole(workspaceId uuid.UUID, role model.WorkspaceRole) (int64, error)
+	UpdateRole(workspaceId, userId uuid.UUID, role model.WorkspaceRole) error
+	Delete(workspaceId, userId uuid.UUID) error
+}
+
+type WorkspaceInvitationStorage interface {
+	PgCrudStorage[*model.WorkspaceInvitation]
+
+	// FindPendingByHash returns the unaccepted invitation with the token hash, or nil.
+	FindPendingByHash(tokenHash string) (*model.WorkspaceInvitation, error)
+	ListPendingByWorkspaceId(workspaceId uuid.UUID) ([]*model.WorkspaceInvitation, error)
+	// DeletePending deletes the unaccepted invitations of the email to the workspace.
+	DeletePending(workspaceId uuid.UUID, email string) error
+}
@@ -0,0 +1,98 @@
+package svc
+
+import (
+	"context"
+	"time"
+
+	"github.com/amahdian/cliplab-be/domain/contracts/req"
+	"github.com/amahdian/cliplab-be/domain/contracts/resp"
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/amahdian/cliplab-be/global/env"
+	"github.com/amahdian/cliplab-be/global/errs"
+	"github.com/amahdian/cliplab-be/storage"
+	"github.com/amahdian/cliplab-be/svc/utils"
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+)
+
+type ApiKeySvc interface {
+	// Create returns the new key, which cannot be read again afterwards.
+	Create(userID uuid.UUID, data *req.CreateApiKey) (*resp.CreatedApiKey, error)
+	List(userID uuid.UUID) ([]*model.ApiKey, error)
+	Revoke(userID, keyID uuid.UUID) error
+}
+
+type apiKeySvc struct {
+	ctx  context.Context
+	stg  storage.PgStorage
+	envs *env.Envs
+}
+
+func newApiKeySvc(ctx context.Context, stg storage.PgStorage, envs *env.Envs) ApiKeySvc {
+	return &apiKeySvc{
+		ctx:  ctx,
+		stg:  stg,
+		envs: envs,
+	}
+}
+
+func (s *apiKeySvc) Create(userID uuid.UUID, data *req.CreateApiKey) (*resp.CreatedApiKey, error) {
+	rateLimit := s.envs.ApiKey.RateLimit
+	if data.RateLimit != nil {
+		if *data.RateLimit > rateLimit {
+			return nil, errs.Newf(errs.InvalidArgument, nil, "the rate limit cannot be more than %d requests per minute", rateLimit)
+		}
+		rateLimit = *data.RateLimit
+	}
+
+	active, err := s.stg.ApiKey(s.ctx).ListActiveByUserId(userID)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to list the api keys")
+	}
+	if len(active) >= s.envs.ApiKey.MaxPerUser {
+		return nil, errs.Newf(errs.FailedPrecondition, nil, "you can have up to %d api keys, revoke one first", s.envs.ApiKey.MaxPerUser)
+	}
+
+	key, prefix, err := utils.GenerateApiKey()
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to generate the api key")
+	}
+	apiKey := &model.ApiKey{
+		UserId:    userID,
+		Name:      data.Name,
+		Prefix:    prefix,
+		KeyHash:   utils.HashOtp(s.envs.Server.JwtSecret, key),
+		Scopes:    lo.Uniq(data.Scopes),
+		RateLimit: rateLimit,
+	}
+	if data.ExpiresInDays != nil {
+		apiKey.ExpiresAt = lo.ToPtr(time.Now().AddDate(0, 0, *data.ExpiresInDays))
+	}
+	if err = s.stg.ApiKey(s.ctx).CreateOne(apiKey); err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to save the api key")
+	}
+
+	return &resp.CreatedApiKey{
+		ApiKey: apiKey,
+		Key:    key,
+	}, nil
+}
+
+func (s *apiKeySvc) List(userID uuid.UUID) ([]*model.ApiKey, error) {
+	keys, err := s.stg.ApiKey(s.ctx).ListActiveByUserId(userID)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to list the api keys")
+	}
+	return keys, nil
+}
+
+func (s *apiKeySvc) Revoke(userID, keyID uuid.UUID) error {
+	revoked, err := s.stg.ApiKey(s.ctx).Revoke(userID, keyID)
+	if err != nil {
+		return errs.Newf(errs.Internal, err, "failed to revoke the api key")
+	}
+	if !revoked {
+		return errs.Newf(errs.NotFound, nil, "api key not found")
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+package auth
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/amahdian/cliplab-be/global/env"
+	"github.com/amahdian/cliplab-be/global/errs"
+	"github.com/amahdian/cliplab-be/pkg/logger"
+	"github.com/amahdian/cliplab-be/pkg/logger/logging"
+	"github.com/amahdian/cliplab-be/storage"
+	"github.com/amahdian/cliplab-be/svc/utils"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/samber/lo"
+)
+
+// fakeStg holds the api keys and their users in memory.
+type fakeStg struct {
+	storage.PgStorage
+	keys  map[string]*model.ApiKey
+	users map[uuid.UUID]*model.User
+}
+
+func (s *fakeStg) ApiKey(context.Context) storage.ApiKeyStorage { return &fakeApiKeyStg{stg: s} }
+
+func (s *fakeStg) User(context.Context) storage.UserStorage { return &fakeUserStg{stg: s} }
+
+type fakeApiKeyStg struct {
+	storage.ApiKeyStorage
+	stg *fakeStg
+}
+
+func (s *fakeApiKeyStg) FindByPrefix(prefix string) (*model.ApiKey, error) {
+	return s.stg.keys[prefix], nil
+}
+
+func (s *fakeApiKeyStg) Touch(uuid.UUID) error { return nil }
+
+type fakeUserStg struct {
+	storage.UserStorage
+	stg *fakeStg
+}
+
+func (s *fakeUserStg) FindById(id uuid.UUID) (*model.User, error) {
+	user, ok := s.stg.users[id]
+	if !ok {
+		return nil, fmt.Errorf("user %s not found", id)
+	}
+	return user, nil
+}
+
+// newFakeRedis returns a client of an in-memory redis answering INCR and EXPIRE, other commands fail.
+func newFakeRedis(t *testing.T) *redis.Client {
+	var mu sync.Mutex
+	counters := map[string]int64{}
+	serve := func(conn net.Conn) {
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		for {
+			args, err := readRespCommand(r)
+			if err != nil {
+				return
+			}
+			reply := "-ERR unknown command\r\n"
+			switch strings.ToUpper(args[0]) {
+			case "INCR":
+				// the minute is dropped from the key, so a test crossing a minute keeps its count
+				key := args[1][:strings.LastIndex(args[1], ":")]
+				mu.Lock()
+				counters[key]++
+				reply = fmt.Sprintf(":%d\r\n", counters[key])
+				mu.Unlock()
+			case "EXPIRE":
+				reply = ":1\r\n"
+			}
+			if _, err = conn.Write([]byte(reply)); err != nil {
+				return
+			}
+		}
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Protocol:        2,
+		DisableIdentity: true,
+		Dialer: func(context.Context, string, string) (net.Conn, error) {
+			server, client := net.Pipe()
+			go serve(server)
+			return client, nil
+		},
+	})
+	t.Cleanup(func() { _ = client.Close() })
+	return client
+}
+
+// readRespCommand reads a command sent as an array of bulk strings.
+func readRespCommand(r *bufio.Reader) ([]string, error) {
+	readLine := func() (string, error) {
+		line, err := r.ReadString('\n')
+		return strings.TrimRight(line, "\r\n"), err
+	}
+	header, err := readLine()
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(header, "*"))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, 0, n)
+	for range n {
+		if _, err = readLine(); err != nil { // the length of the bulk string
+			return nil, err
+		}
+		arg, err := readLine()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	return args, nil
+}
+
+// newApiKeyAuthenticator returns an authenticator knowing a user and the api key it returns.
+func newApiKeyAuthenticator(t *testing.T, redisClient *redis.Client) (*authenticator, *model.ApiKey, string) {
+	logger.Configure(logging.ErrorLevel, logging.TextFormat)
+	envs := &env.Envs{}
+	envs.Server.JwtSecret = "secret"
+
+	apiKey, prefix, err := utils.GenerateApiKey()
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	user := &model.User{ID: uuid.New(), Email: "user@cliplab.test"}
+	key := &model.ApiKey{
+		ID:        uuid.New(),
+		UserId:    user.ID,
+		Prefix:    prefix,
+		KeyHash:   utils.HashOtp(envs.Server.JwtSecret, apiKey),
+		Scopes:    []model.ApiKeyScope{model.ApiKeyScopeLibraryRead},
+		RateLimit: 2,
+	}
+	stg := &fakeStg{
+		keys:  map[string]*model.ApiKey{prefix: key},
+		users: map[uuid.UUID]*model.User{user.ID: user},
+	}
+	return NewAuthenticator(envs, stg, redisClient).(*authenticator), key, apiKey
+}
+
+func verifyApiKey(a *authenticator, apiKey string) (UserInfo, error) {
+	request := httptest.NewRequest("GET", "/", nil)
+	request.Header.Set(ApiKeyHeader, apiKey)
+	ctx, err := a.Verify(request)
+	return UserInfoFromCtx(ctx), err
+}
+
+func TestVerifyApiKey(t *testing.T) {
+	a, key, apiKey := newApiKeyAuthenticator(t, nil)
+
+	userInfo, err := verifyApiKey(a, apiKey)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if userInfo.Id != key.UserId || userInfo.ApiKey != key || userInfo.SessionId != nil {
+		t.Errorf("unexpected user info %+v", userInfo)
+	}
+
+	// the prefix matches, the secret does not
+	forged := apiKey[:len(apiKey)-1] + lo.Ternary(strings.HasSuffix(apiKey, "A"), "B", "A")
+	if _, err = verifyApiKey(a, forged); !errs.IsCode(err, errs.Unauthenticated) {
+		t.Errorf("hash mismatch: expected unauthenticated, got %v", err)
+	}
+
+	key.ExpiresAt = lo.ToPtr(time.Now().Add(-time.Minute))
+	if _, err = verifyApiKey(a, apiKey); !errs.IsCode(err, errs.Unauthenticated) {
+		t.Errorf("expired key: expected unauthenticated, got %v", err)
+	}
+	key.ExpiresAt = nil
+	key.RevokedAt = lo.ToPtr(time.Now())
+	if _, err = verifyApiKey(a, apiKey); !errs.IsCode(err, errs.Unauthenticated) {
+		t.Errorf("revoked key: expected unauthenticated, got %v", err)
+	}
+}
+
+func TestVerifyApiKeyRateLimit(t *testing.T) {
+	a, key, apiKey := newApiKeyAuthenticator(t, newFakeRedis(t))
+
+	for i := range key.RateLimit {
+		if _, err := verifyApiKey(a, apiKey); err != nil {
+			t.Fatalf("request %d: %v", i+1, err)
+		}
+	}
+	if _, err := verifyApiKey(a, apiKey); !errs.IsCode(err, errs.ResourceExhausted) {
+		t.Errorf("over the limit: expected resource exhausted, got %v", err)
+	}
+}
@@ -2,27 +2,41 @@ package auth
 
 import (
 	"context"
+	"crypto/subtle"
 	"errors"
+	"fmt"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/amahdian/cliplab-be/global"
 	"github.com/amahdian/cliplab-be/global/env"
 	"github.com/amahdian/cliplab-be/global/errs"
+	"github.com/amahdian/cliplab-be/pkg/logger"
 	"github.com/amahdian/cliplab-be/storage"
+	"github.com/amahdian/cliplab-be/svc/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
 	"github.com/samber/lo"
 )
 
+// ApiKeyHeader is the header the api keys are sent in.
+const ApiKeyHeader = "X-Api-Key"
+
 type userInfoCtx struct{}
 
 type UserInfo struct {
-	Id    uuid.UUID `json:"id"`
-	Name  string    `json:"name"`
-	Email string    `json:"email"`
+	Id    uuid.UUID      `json:"id"`
+	Name  string         `json:"name"`
+	Email string         `json:"email"`
+	Role  model.UserRole `json:"role"`
+	// SessionId is the session the access token was issued for, nil for api keys.
+	SessionId *uuid.UUID `json:"sessionId"`
+	// ApiKey is the key the request was authenticated with, nil for user tokens.
+	ApiKey *model.ApiKey `json:"-"`
 }
 
 func (u *UserInfo) User() model.User {
@@ -30,6 +44,7 @@ func (u *UserInfo) User() model.User {
 		ID:    u.Id,
 		Email: u.Email,
 		Name:  lo.ToPtr(u.Name),
+		Role:  u.Role,
 	}
 }
 
@@ -38,19 +53,27 @@ type Authenticator interface {
 }
 
 type authenticator struct {
-	JwtSecret string
-	Stg       storage.PgStorage
+	JwtSecret   string
+	Stg         storage.PgStorage
+	RedisClient *redis.Client
 }
 
-func NewAuthenticator(envs *env.Envs, stg storage.PgStorage) Authenticator {
+// NewAuthenticator accepts the access tokens of the users and their api keys.
+// The api keys are only rate limited when a redis client is given.
+func NewAuthenticator(envs *env.Envs, stg storage.PgStorage, redisClient *redis.Client) Authenticator {
 	return &authenticator{
-		JwtSecret: envs.Server.JwtSecret,
-		Stg:       stg,
+		JwtSecret:   envs.Server.JwtSecret,
+		Stg:         stg,
+		RedisClient: redisClient,
 	}
 }
 
 func (a *authenticator) Verify(request *http.Request) (context.Context, error) {
 	ctx := request.Context()
+	if apiKey := request.Header.Get(ApiKeyHeader); apiKey != "" {
+		return a.verifyApiKey(ctx, apiKey)
+	}
+
 	tokenStr := request.Header.Get("Authorization")
 
 	if tokenStr == "" {
@@ -67,10 +90,18 @@ func (a *authenticator) Verify(request *http.Request) (context.Context, error) {
 	})
 
 	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		userID, err := uuid.Parse(claims["id"].(string))
+		// other tokens signed with the secret, e.g. the oidc flow tokens, have no user id
+		id, _ := claims["id"].(string)
+		userID, err := uuid.Parse(id)
 		if err != nil {
 			return ctx, errs.Newf(errs.Unauthenticated, err, "Invalid user ID.")
 		}
+		// the tokens issued before sessions could not be revoked by logging out, they are no longer accepted
+		sid, _ := claims["sid"].(string)
+		sessionID, err := uuid.Parse(sid)
+		if err != nil {
+			return ctx, errs.Newf(errs.Unauthenticated, err, "Invalid session ID.")
+		}
 
 		user, err := a.Stg.User(ctx).FindById(userID)
 		if err != nil {
@@ -80,23 +111,109 @@ func (a *authenticator) Verify(request *http.Request) (context.Context, error) {
 		if user.VerifiedAt == nil && time.Since(user.CreatedAt) > 14*24*time.Hour {
 			return ctx, errs.Newf(errs.Unauthenticated, nil, "Email not verified.")
 		}
+		if user.DeletionScheduledAt != nil {
+			return ctx, errs.Newf(errs.Unauthenticated, nil, "Account is scheduled for deletion.")
+		}
+
+		// iat has a precision of seconds, so tokens issued within the second of the revocation are kept
+		if user.SessionsRevokedAt != nil {
+			issuedAt, _ := claims.GetIssuedAt()
+			if issuedAt == nil || issuedAt.Before(user.SessionsRevokedAt.Truncate(time.Second)) {
+				return ctx, errs.Newf(errs.Unauthenticated, nil, "Session has been revoked.")
+			}
+		}
+
+		session, err := a.Stg.UserSession(ctx).FindById(sessionID)
+		if err != nil || !session.Active() || session.UserId != userID {
+			return ctx, errs.Newf(errs.Unauthenticated, err, "Session has been revoked.")
+		}
 
 		userInfo := UserInfo{
-			Id:    userID,
-			Email: claims["email"].(string),
+			Id:        userID,
+			Email:     claims["email"].(string),
+			Role:      user.Role,
+			SessionId: &sessionID,
 		}
 		if claims["name"] != nil {
 			userInfo.Name = claims["name"].(string)
 		}
 
-		ctx = context.WithValue(ctx, userInfoCtx{}, userInfo)
-
-		return ctx, nil
+		return WithUserInfo(ctx, userInfo), nil
 	} else {
 		return ctx, errs.Newf(errs.Unauthenticated, err, "Auth failed.")
 	}
 }
 
+func (a *authenticator) verifyApiKey(ctx context.Context, apiKey string) (context.Context, error) {
+	prefix, ok := utils.ParseApiKey(apiKey)
+	if !ok {
+		return ctx, errs.Newf(errs.Unauthenticated, nil, "Invalid API key.")
+	}
+	key, err := a.Stg.ApiKey(ctx).FindByPrefix(prefix)
+	if err != nil {
+		return ctx, errs.Newf(errs.Internal, err, "Failed to find the API key.")
+	}
+	hash := utils.HashOtp(a.JwtSecret, apiKey)
+	if key == nil || subtle.ConstantTimeCompare([]byte(hash), []byte(key.KeyHash)) != 1 {
+		return ctx, errs.Newf(errs.Unauthenticated, nil, "Invalid API key.")
+	}
+	if !key.Active() {
+		return ctx, errs.Newf(errs.Unauthenticated, nil, "API key has been revoked or has expired.")
+	}
+
+	if err = a.claimApiKeyRate(ctx, key); err != nil {
+		return ctx, err
+	}
+
+	user, err := a.Stg.User(ctx).FindById(key.UserId)
+	if err != nil {
+		return ctx, errs.Newf(errs.Unauthenticated, err, "User not found.")
+	}
+	if user.DeletionScheduledAt != nil {
+		return ctx, errs.Newf(errs.Unauthenticated, nil, "Account is scheduled for deletion.")
+	}
+	if err = a.Stg.ApiKey(ctx).Touch(key.ID); err != nil {
+		logger.Warnf("Failed to record the use of api key %s: %v", key.ID, err)
+	}
+
+	userInfo := UserInfo{
+		Id:     user.ID,
+		Name:   lo.FromPtr(user.Name),
+		Email:  user.Email,
+		Role:   user.Role,
+		ApiKey: key,
+	}
+	return WithUserInfo(ctx, userInfo), nil
+}
+
+// claimApiKeyRate counts the request against the requests per minute of the key.
+func (a *authenticator) claimApiKeyRate(ctx context.Context, key *model.ApiKey) error {
+	if a.RedisClient == nil {
+		return nil
+	}
+
+	window := time.Now().Unix() / 60
+	rateKey := fmt.Sprintf("%s%s:%d", global.RedisApiKeyRate, key.ID, window)
+	count, err := a.RedisClient.Incr(ctx, rateKey).Result()
+	if err != nil {
+		// do not lock the scripts out while redis is unavailable
+		logger.Errorf("Failed to count the requests of api key %s: %v", key.ID, err)
+		return nil
+	}
+	if count == 1 {
+		a.RedisClient.Expire(ctx, rateKey, 2*time.Minute)
+	}
+	if count > int64(key.RateLimit) {
+		return errs.Newf(errs.ResourceExhausted, nil, "Rate limit of %d requests per minute exceeded.", key.RateLimit)
+	}
+	return nil
+}
+
+// WithUserInfo returns a copy of the context carrying the authenticated user.
+func WithUserInfo(ctx context.Context, userInfo UserInfo) context.Context {
+	return context.WithValue(ctx, userInfoCtx{}, userInfo)
+}
+
 func UserInfoFromCtx(ctx context.Context) UserInfo {
 	if ginCtx, ok := ctx.(*gin.Context); ok {
 		ctx = ginCtx.Request.Context()
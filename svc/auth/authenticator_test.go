@@ -0,0 +1,34 @@
+package auth
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/amahdian/cliplab-be/global/env"
+	"github.com/amahdian/cliplab-be/global/errs"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+func TestVerifyRejectsTokensWithoutSession(t *testing.T) {
+	envs := &env.Envs{}
+	envs.Server.JwtSecret = "secret"
+	a := NewAuthenticator(envs, nil, nil)
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"id":    uuid.NewString(),
+		"email": "user@cliplab.test",
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(time.Minute).Unix(),
+	}).SignedString([]byte(envs.Server.JwtSecret))
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+
+	request := httptest.NewRequest("GET", "/", nil)
+	request.Header.Set("Authorization", "Bearer "+token)
+	if _, err = a.Verify(request); !errs.IsCode(err, errs.Unauthenticated) {
+		t.Errorf("expected unauthenticated, got %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/amahdian/cliplab-be/global/errs"
+	"github.com/google/uuid"
+)
+
+// Permission is an operation a route requires, the roles of the users grant them.
+type Permission string
+
+const (
+	PermissionReportsRead     Permission = "reports:read"
+	PermissionCategoriesWrite Permission = "categories:write"
+)
+
+// rolePermissions lists the permissions granted by each role, the users without a role have none.
+var rolePermissions = map[model.UserRole][]Permission{
+	model.UserRoleAdmin: {
+		PermissionReportsRead,
+		PermissionCategoriesWrite,
+	},
+}
+
+type Authorizer interface {
+	// Authorize checks the role of the user grants all the permissions.
+	Authorize(user UserInfo, permissions ...Permission) error
+}
+
+type authorizer struct {
+	permissions map[model.UserRole]map[Permission]bool
+}
+
+func NewAuthorizer() Authorizer {
+	permissions := map[model.UserRole]map[Permission]bool{}
+	for role, granted := range rolePermissions {
+		permissions[role] = map[Permission]bool{}
+		for _, p := range granted {
+			permissions[role][p] = true
+		}
+	}
+	return &authorizer{permissions: permissions}
+}
+
+func (a *authorizer) Authorize(user UserInfo, permissions ...Permission) error {
+	if user.Id == uuid.Nil {
+		return errs.Newf(errs.Unauthenticated, nil, "Authentication required.")
+	}
+	for _, p := range permissions {
+		if !a.permissions[user.Role][p] {
+			return errs.Newf(errs.PermissionDenied, nil, "Permission %s is required.", p)
+		}
+	}
+	return nil
+}
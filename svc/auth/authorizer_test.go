@@ -0,0 +1,28 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/amahdian/cliplab-be/global/errs"
+	"github.com/google/uuid"
+)
+
+func TestAuthorize(t *testing.T) {
+	a := NewAuthorizer()
+	admin := UserInfo{Id: uuid.New(), Role: model.UserRoleAdmin}
+	user := UserInfo{Id: uuid.New(), Role: model.UserRoleUser}
+
+	if err := a.Authorize(admin, PermissionReportsRead, PermissionCategoriesWrite); err != nil {
+		t.Errorf("admin: %v", err)
+	}
+	if err := a.Authorize(user); err != nil {
+		t.Errorf("no permissions required: %v", err)
+	}
+	if err := a.Authorize(user, PermissionReportsRead); !errs.IsCode(err, errs.PermissionDenied) {
+		t.Errorf("user: expected permission denied, got %v", err)
+	}
+	if err := a.Authorize(UserInfo{Role: model.UserRoleAdmin}, PermissionReportsRead); !errs.IsCode(err, errs.Unauthenticated) {
+		t.Errorf("anonymous: expected unauthenticated, got %v", err)
+	}
+}
@@ -0,0 +1,167 @@
+package svc
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/amahdian/cliplab-be/global/env"
+	"github.com/amahdian/cliplab-be/global/errs"
+	"github.com/amahdian/cliplab-be/storage"
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+)
+
+type CategorySvc interface {
+	ListCategories() ([]*model.Category, error)
+	CreateCategory(path string) (*model.Category, error)
+	UpdateCategory(id uuid.UUID, path string) (*model.Category, error)
+	DeleteCategory(id uuid.UUID) error
+}
+
+type categorySvc struct {
+	ctx  context.Context
+	stg  storage.PgStorage
+	envs *env.Envs
+}
+
+func newCategorySvc(ctx context.Context, stg storage.PgStorage, envs *env.Envs) CategorySvc {
+	return &categorySvc{
+		ctx:  ctx,
+		stg:  stg,
+		envs: envs,
+	}
+}
+
+func (s *categorySvc) ListCategories() ([]*model.Category, error) {
+	categories, err := s.stg.Category(s.ctx).ListOrdered()
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to list categories")
+	}
+	return categories, nil
+}
+
+func (s *categorySvc) CreateCategory(path string) (*model.Category, error) {
+	category := newCategory(path)
+	if category == nil {
+		return nil, errs.Newf(errs.InvalidArgument, nil, "category path is empty")
+	}
+	if err := s.ensurePathIsFree(category.Path, uuid.Nil); err != nil {
+		return nil, err
+	}
+
+	if err := s.stg.Category(s.ctx).CreateOne(category); err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to create category")
+	}
+	return category, nil
+}
+
+// UpdateCategory renames the category along with its subcategories, saving a suggested category also approves it.
+func (s *categorySvc) UpdateCategory(id uuid.UUID, path string) (*model.Category, error) {
+	category, err := s.stg.Category(s.ctx).FindById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := newCategory(path)
+	if updated == nil {
+		return nil, errs.Newf(errs.InvalidArgument, nil, "category path is empty")
+	}
+	if err = s.ensurePathIsFree(updated.Path, id); err != nil {
+		return nil, err
+	}
+	if err = s.ensureSubtreeCanMove(category.Path, updated.Path); err != nil {
+		return nil, err
+	}
+
+	previousPath := category.Path
+	category.Name = updated.Name
+	category.Path = updated.Path
+	category.Suggested = false
+	if err = s.stg.Category(s.ctx).Rename(category, previousPath); err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to update category")
+	}
+	return category, nil
+}
+
+// DeleteCategory deletes a category without subcategories, the posts classified in it lose the category.
+func (s *categorySvc) DeleteCategory(id uuid.UUID) error {
+	category, err := s.stg.Category(s.ctx).FindById(id)
+	if err != nil {
+		return errs.Newf(errs.NotFound, err, "category not found")
+	}
+	categories, err := s.stg.Category(s.ctx).ListOrdered()
+	if err != nil {
+		return errs.Newf(errs.Internal, err, "failed to list categories")
+	}
+	if len(subcategories(categories, category.Path)) > 0 {
+		return errs.Newf(errs.FailedPrecondition, nil, "category %s has subcategories, delete them first", category.Path)
+	}
+
+	if err = s.stg.Category(s.ctx).DeleteById(id); err != nil {
+		return errs.Newf(errs.Internal, err, "failed to delete category")
+	}
+	return nil
+}
+
+// ensureSubtreeCanMove checks no other category takes the path of a subcategory once moved from the previous path.
+func (s *categorySvc) ensureSubtreeCanMove(previousPath, path string) error {
+	categories, err := s.stg.Category(s.ctx).ListOrdered()
+	if err != nil {
+		return errs.Newf(errs.Internal, err, "failed to list categories")
+	}
+
+	moved := subcategories(categories, previousPath)
+	taken := map[string]bool{}
+	for _, c := range categories {
+		if c.Path != previousPath && !lo.Contains(moved, c) {
+			taken[strings.ToLower(c.Path)] = true
+		}
+	}
+	for _, c := range moved {
+		movedPath := path + strings.TrimPrefix(c.Path, previousPath)
+		if taken[strings.ToLower(movedPath)] {
+			return errs.Newf(errs.AlreadyExists, nil, "category %s already exists", movedPath)
+		}
+	}
+	return nil
+}
+
+func (s *categorySvc) ensurePathIsFree(path string, id uuid.UUID) error {
+	existing, err := s.stg.Category(s.ctx).FindByPath(path)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return errs.Newf(errs.Internal, err, "failed to find category")
+	}
+	if existing != nil && existing.ID != id {
+		return errs.Newf(errs.AlreadyExists, nil, "category %s already exists", path)
+	}
+	return nil
+}
+
+// subcategories returns the categories nested under the path, at any depth.
+func subcategories(categories []*model.Category, path string) []*model.Category {
+	return lo.Filter(categories, func(c *model.Category, _ int) bool {
+		return strings.HasPrefix(c.Path, path+model.CategoryPathSeparator)
+	})
+}
+
+// newCategory builds a category from a path such as "Technology / AI".
+// It returns nil when the path has no level.
+func newCategory(path string) *model.Category {
+	var levels []string
+	for _, level := range strings.Split(path, model.CategoryPathSeparator) {
+		if level = strings.TrimSpace(level); level != "" {
+			levels = append(levels, level)
+		}
+	}
+	if len(levels) == 0 {
+		return nil
+	}
+
+	return &model.Category{
+		Name: levels[len(levels)-1],
+		Path: strings.Join(levels, model.CategoryPathSeparator),
+	}
+}
@@ -0,0 +1,43 @@
+package svc
+
+import (
+	"context"
+
+	"github.com/amahdian/cliplab-be/domain/contracts/resp"
+	"github.com/amahdian/cliplab-be/global/env"
+	"github.com/amahdian/cliplab-be/global/errs"
+	"github.com/amahdian/cliplab-be/storage"
+	"github.com/google/uuid"
+)
+
+type ChannelSvc interface {
+	// GetPostingTimes returns the engagement of the known reels of the channel by local weekday and hour.
+	GetPostingTimes(channelId uuid.UUID) (*resp.PostingTimes, error)
+}
+
+type channelSvc struct {
+	ctx  context.Context
+	stg  storage.PgStorage
+	envs *env.Envs
+}
+
+func newChannelSvc(ctx context.Context, stg storage.PgStorage, envs *env.Envs) ChannelSvc {
+	return &channelSvc{
+		ctx:  ctx,
+		stg:  stg,
+		envs: envs,
+	}
+}
+
+func (s *channelSvc) GetPostingTimes(channelId uuid.UUID) (*resp.PostingTimes, error) {
+	if _, err := s.stg.Channel(s.ctx).FindById(channelId); err != nil {
+		return nil, errs.Newf(errs.NotFound, err, "channel not found")
+	}
+
+	reels, err := s.stg.ChannelReel(s.ctx).ListByChannelId(channelId)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to list channel reels")
+	}
+
+	return newPostingTimes(channelId, reels), nil
+}
@@ -0,0 +1,251 @@
+package svc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/amahdian/cliplab-be/clients/gpt"
+	"github.com/amahdian/cliplab-be/domain/contracts/resp"
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/amahdian/cliplab-be/global/env"
+	"github.com/amahdian/cliplab-be/global/errs"
+	"github.com/amahdian/cliplab-be/pkg/logger"
+	"github.com/amahdian/cliplab-be/storage"
+	"github.com/google/uuid"
+)
+
+const (
+	// chatHistoryLimit is the number of previous messages sent along with a new question.
+	chatHistoryLimit = 20
+	// maxChatTranscriptLength bounds the transcript used to ground the conversation.
+	maxChatTranscriptLength = 8000
+)
+
+// WsEventChatMessage is sent for every streamed part of a chat answer delivered over the web socket.
+const WsEventChatMessage = "chat_message"
+
+const chatSystemPrompt = `You are a short-form video strategist helping a creator understand a single clip.
+Answer using the clip data below: its transcript, analysis, metrics and how it performed against the channel's usual results.
+If the data does not contain the answer, say so instead of guessing. Keep answers concise and actionable.`
+
+type ChatSvc interface {
+	// Chat stores the question and streams the answer, which is stored once complete.
+	Chat(userId uuid.UUID, postId, message string) (<-chan *model.StreamedMessage, error)
+	GetHistory(userId uuid.UUID, postId string) ([]*model.ChatMessage, error)
+	ClearHistory(userId uuid.UUID, postId string) error
+	// ForwardToWebSocket sends a streamed answer to the user's web socket instead of the response.
+	ForwardToWebSocket(userId uuid.UUID, postId string, stream <-chan *model.StreamedMessage)
+}
+
+type chatSvc struct {
+	ctx   context.Context
+	stg   storage.PgStorage
+	envs  *env.Envs
+	wsSvc WebSocketSvc
+
+	GptClient gpt.Client
+}
+
+func newChatSvc(ctx context.Context, stg storage.PgStorage, envs *env.Envs, gptClient gpt.Client, wsSvc WebSocketSvc) ChatSvc {
+	return &chatSvc{
+		ctx:       ctx,
+		stg:       stg,
+		envs:      envs,
+		wsSvc:     wsSvc,
+		GptClient: gptClient,
+	}
+}
+
+func (s *chatSvc) Chat(userId uuid.UUID, postId, message string) (<-chan *model.StreamedMessage, error) {
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return nil, errs.Newf(errs.InvalidArgument, nil, "message is empty")
+	}
+	if s.GptClient == nil {
+		return nil, errs.Newf(errs.Unavailable, nil, "chat is not configured")
+	}
+	if err := ensurePostAnalyzedByUser(s.ctx, s.stg, userId, postId); err != nil {
+		return nil, err
+	}
+
+	grounding, err := s.buildGrounding(postId)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := s.stg.ChatMessage(s.ctx).ListRecent(userId, postId, chatHistoryLimit)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to list chat history")
+	}
+
+	messages := []gpt.ChatMessage{{Role: "system", Content: chatSystemPrompt + "\n\n" + grounding}}
+	for _, m := range history {
+		messages = append(messages, gpt.ChatMessage{Role: string(m.Role), Content: m.Content})
+	}
+	messages = append(messages, gpt.ChatMessage{Role: string(model.ChatRoleUser), Content: message})
+
+	body, err := json.Marshal(gpt.ChatRequest{Model: gpt.ChatModel, Messages: messages, Stream: true})
+	if err != nil {
+		return nil, errs.Wrapf(err, "failed to marshal chat request")
+	}
+
+	question := &model.ChatMessage{UserId: userId, PostId: postId, Role: model.ChatRoleUser, Content: message}
+	if err = s.stg.ChatMessage(s.ctx).CreateOne(question); err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to save chat message")
+	}
+
+	tokens, err := s.GptClient.StreamChatCompletions(body)
+	if err != nil {
+		s.discardQuestion(question)
+		return nil, errs.Newf(errs.Unavailable, err, "failed to start chat completion")
+	}
+
+	stream := make(chan *model.StreamedMessage)
+	go s.relayAnswer(question, tokens, stream)
+	return stream, nil
+}
+
+// relayAnswer forwards the tokens of the answer to the question and stores it once complete.
+// The answer is read to the end and stored even when the client went away, and the question is
+// discarded when no answer was generated so the history holds no unanswered questions.
+func (s *chatSvc) relayAnswer(question *model.ChatMessage, tokens <-chan string, stream chan<- *model.StreamedMessage) {
+	defer close(stream)
+
+	listening := true
+	send := func(message *model.StreamedMessage) {
+		if !listening {
+			return
+		}
+		select {
+		case stream <- message:
+		case <-s.ctx.Done():
+			listening = false
+		}
+	}
+
+	var answer strings.Builder
+	for token := range tokens {
+		answer.WriteString(token)
+		send(&model.StreamedMessage{Type: model.StreamEventTypeResponse, Content: token})
+	}
+
+	if answer.Len() == 0 {
+		s.discardQuestion(question)
+		send(&model.StreamedMessage{Type: model.StreamEventTypeError, Content: "no answer was generated"})
+		return
+	}
+
+	reply := &model.ChatMessage{UserId: question.UserId, PostId: question.PostId, Role: model.ChatRoleAssistant, Content: answer.String()}
+	if err := s.stg.ChatMessage(context.WithoutCancel(s.ctx)).CreateOne(reply); err != nil {
+		logger.Errorf("Failed to save chat answer for post %s: %v", question.PostId, err)
+	}
+	send(&model.StreamedMessage{Type: model.StreamEventTypeDone, Content: reply.ID.String()})
+}
+
+// discardQuestion deletes a question that got no answer.
+func (s *chatSvc) discardQuestion(question *model.ChatMessage) {
+	if err := s.stg.ChatMessage(context.WithoutCancel(s.ctx)).DeleteById(question.ID); err != nil {
+		logger.Errorf("Failed to discard chat question for post %s: %v", question.PostId, err)
+	}
+}
+
+func (s *chatSvc) ForwardToWebSocket(userId uuid.UUID, postId string, stream <-chan *model.StreamedMessage) {
+	go func() {
+		for message := range stream {
+			event := &resp.ChatStreamEvent{PostId: postId, Type: message.Type, Content: message.Content}
+			if err := s.wsSvc.Send(userId, WsEventChatMessage, event); err != nil {
+				logger.Debugf("Chat answer of post %s not pushed: %v", postId, err)
+			}
+		}
+	}()
+}
+
+func (s *chatSvc) GetHistory(userId uuid.UUID, postId string) ([]*model.ChatMessage, error) {
+	if err := ensurePostAnalyzedByUser(s.ctx, s.stg, userId, postId); err != nil {
+		return nil, err
+	}
+
+	messages, err := s.stg.ChatMessage(s.ctx).ListRecent(userId, postId, chatHistoryLimit*5)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to list chat history")
+	}
+	return messages, nil
+}
+
+func (s *chatSvc) ClearHistory(userId uuid.UUID, postId string) error {
+	if err := s.stg.ChatMessage(s.ctx).DeleteConversation(userId, postId); err != nil {
+		return errs.Newf(errs.Internal, err, "failed to clear chat history")
+	}
+	return nil
+}
+
+// buildGrounding describes the post, its analysis and its channel baseline for the model.
+func (s *chatSvc) buildGrounding(postId string) (string, error) {
+	post, err := s.stg.Post(s.ctx).FindByHashId(postId)
+	if err != nil {
+		return "", errs.Newf(errs.NotFound, err, "post %s not found", postId)
+	}
+	analysis, err := s.stg.PostAnalysis(s.ctx).FindByPostId(postId)
+	if err != nil {
+		return "", errs.Newf(errs.FailedPrecondition, err, "post %s is not analyzed yet", postId)
+	}
+	contents, err := s.stg.PostContent(s.ctx).ListByPostId(postId)
+	if err != nil {
+		return "", errs.Newf(errs.Internal, err, "failed to list post contents")
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Clip by @%s, posted %s\n", post.UserAnchor, post.PostDate.Format("2006-01-02 15:04 MST"))
+	fmt.Fprintf(&b, "Likes: %d, comments: %d, plays: %d\n", post.LikeCount, post.CommentCount, post.VideoPlayCount)
+
+	if post.ChannelId != nil {
+		histories, err := s.stg.ChannelHistory(s.ctx).ListLatestByChannelIds([]uuid.UUID{*post.ChannelId})
+		if err == nil && len(histories) > 0 {
+			h := histories[0]
+			fmt.Fprintf(&b, "Channel baseline: %d followers, average likes %d, average comments %d, average plays %d\n",
+				h.FollowersCount, h.AverageLikes, h.AverageComments, h.AverageVideoPlays)
+		}
+	}
+
+	fmt.Fprintf(&b, "\nBig idea: %s\n", analysis.BigIdea)
+	fmt.Fprintf(&b, "Why it went viral: %s\n", analysis.WhyViral)
+	fmt.Fprintf(&b, "Viral score: %d/100, verdict: %s (%s)\n", analysis.ViralScore, analysis.Verdict.Status, analysis.Verdict.Reasoning)
+	fmt.Fprintf(&b, "Audience sentiment: %s (%d/100)\n", analysis.AudienceSentiment, analysis.SentimentScore)
+	if len(analysis.Metrics) > 0 {
+		b.WriteString("Metrics:\n")
+		for _, m := range analysis.Metrics {
+			fmt.Fprintf(&b, "- %s: %d/100. %s Suggestion: %s\n", m.Label, m.Score, m.Explanation, m.Suggestion)
+		}
+	}
+	if len(analysis.Strengths) > 0 {
+		fmt.Fprintf(&b, "Strengths: %s\n", strings.Join(analysis.Strengths, "; "))
+	}
+	if len(analysis.Weaknesses) > 0 {
+		fmt.Fprintf(&b, "Weaknesses: %s\n", strings.Join(analysis.Weaknesses, "; "))
+	}
+
+	var transcript strings.Builder
+	for _, content := range contents {
+		switch content.Type {
+		case model.ContentCaption:
+			fmt.Fprintf(&b, "\nCaption: %s\n", content.Text)
+		case model.ContentTranscript:
+			timestamp := ""
+			if meta, ok := content.Metadata.(*model.SegmentPostContentMetadata); ok {
+				timestamp = meta.Timestamp + " "
+			}
+			fmt.Fprintf(&transcript, "%s%s\n", timestamp, content.Text)
+		}
+	}
+	if transcript.Len() > 0 {
+		text := []rune(transcript.String())
+		if len(text) > maxChatTranscriptLength {
+			text = text[:maxChatTranscriptLength]
+		}
+		fmt.Fprintf(&b, "\nTranscript:\n%s", string(text))
+	}
+
+	return b.String(), nil
+}
@@ -0,0 +1,194 @@
+package svc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/amahdian/cliplab-be/clients/gpt"
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/amahdian/cliplab-be/global/env"
+	"github.com/amahdian/cliplab-be/global/errs"
+	"github.com/amahdian/cliplab-be/pkg/logger"
+	"github.com/amahdian/cliplab-be/storage"
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+)
+
+const compareSystemPrompt = `You are a short-form video strategist comparing clips side by side.
+Using only the data given, explain in a few short paragraphs why the strongest clip outperforms the others:
+refer to the metric scores, the engagement against each channel's own baseline and the ideas of the clips.
+End with two or three concrete lessons the weaker clips could apply.`
+
+type CompareSvc interface {
+	// ComparePosts compares posts analyzed by the user and saves the comparison.
+	ComparePosts(userId uuid.UUID, postIds []string) (*model.Comparison, error)
+	GetComparison(userId uuid.UUID, id uuid.UUID) (*model.Comparison, error)
+	ListComparisons(userId uuid.UUID) ([]*model.Comparison, error)
+}
+
+type compareSvc struct {
+	ctx  context.Context
+	stg  storage.PgStorage
+	envs *env.Envs
+
+	GptClient gpt.Client
+}
+
+func newCompareSvc(ctx context.Context, stg storage.PgStorage, envs *env.Envs, gptClient gpt.Client) CompareSvc {
+	return &compareSvc{
+		ctx:       ctx,
+		stg:       stg,
+		envs:      envs,
+		GptClient: gptClient,
+	}
+}
+
+func (s *compareSvc) ComparePosts(userId uuid.UUID, postIds []string) (*model.Comparison, error) {
+	for _, postId := range postIds {
+		if err := ensurePostAnalyzedByUser(s.ctx, s.stg, userId, postId); err != nil {
+			return nil, err
+		}
+	}
+
+	posts, err := s.stg.Post(s.ctx).ListByHashIds(postIds)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to list posts")
+	}
+	analyses, err := s.stg.PostAnalysis(s.ctx).ListByPostIds(postIds)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to list post analyses")
+	}
+	channelIds := lo.Uniq(lo.FilterMap(posts, func(p *model.Post, _ int) (uuid.UUID, bool) {
+		return lo.FromPtr(p.ChannelId), p.ChannelId != nil
+	}))
+	histories, err := s.stg.ChannelHistory(s.ctx).ListLatestByChannelIds(channelIds)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to list channel histories")
+	}
+
+	postById := lo.KeyBy(posts, func(p *model.Post) string { return p.ID })
+	analysisByPost := lo.KeyBy(analyses, func(a *model.PostAnalysis) string { return a.PostId })
+	historyByChannel := lo.KeyBy(histories, func(h *model.ChannelHistory) uuid.UUID { return h.ChannelID })
+
+	comparison := &model.Comparison{UserId: userId, PostIds: postIds}
+	for _, postId := range postIds {
+		post, ok := postById[postId]
+		if !ok {
+			return nil, errs.Newf(errs.NotFound, nil, "post %s not found", postId)
+		}
+		analysis, ok := analysisByPost[postId]
+		if !ok {
+			return nil, errs.Newf(errs.FailedPrecondition, nil, "post %s is not analyzed yet", postId)
+		}
+
+		compared := &model.ComparedPost{
+			PostId:       post.ID,
+			Link:         post.Link,
+			ImageUrl:     post.ImageURL,
+			UserHandler:  post.UserAnchor,
+			PostDate:     post.PostDate,
+			LikeCount:    post.LikeCount,
+			CommentCount: post.CommentCount,
+			PlayCount:    post.VideoPlayCount,
+			BigIdea:      analysis.BigIdea,
+			ViralScore:   analysis.ViralScore,
+			Verdict:      analysis.Verdict,
+		}
+		if post.ChannelId != nil {
+			if history, ok := historyByChannel[*post.ChannelId]; ok {
+				compared.Baseline = &model.ChannelBaseline{
+					Followers:       history.FollowersCount,
+					AverageLikes:    history.AverageLikes,
+					AverageComments: history.AverageComments,
+					AveragePlays:    history.AverageVideoPlays,
+				}
+				compared.LikesLift = baselineLift(post.LikeCount, history.AverageLikes)
+				compared.CommentsLift = baselineLift(post.CommentCount, history.AverageComments)
+				compared.PlaysLift = baselineLift(post.VideoPlayCount, history.AverageVideoPlays)
+			}
+		}
+		comparison.Posts = append(comparison.Posts, compared)
+	}
+
+	for _, key := range model.MetricKeys {
+		metric := &model.ComparedMetric{Key: key}
+		for _, postId := range postIds {
+			m, ok := lo.Find(analysisByPost[postId].Metrics, func(m model.PostAnalysisMetric) bool { return m.Key == key })
+			metric.Scores = append(metric.Scores, lo.Ternary(ok, lo.ToPtr(m.Score), nil))
+		}
+		comparison.Metrics = append(comparison.Metrics, metric)
+	}
+
+	comparison.Narrative = s.narrate(comparison, analysisByPost)
+
+	if err = s.stg.Comparison(s.ctx).CreateOne(comparison); err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to save comparison")
+	}
+	return comparison, nil
+}
+
+func (s *compareSvc) GetComparison(userId uuid.UUID, id uuid.UUID) (*model.Comparison, error) {
+	comparison, err := s.stg.Comparison(s.ctx).FindById(id)
+	if err != nil {
+		return nil, err
+	}
+	if comparison.UserId != userId {
+		return nil, errs.Newf(errs.NotFound, nil, "comparison %s not found", id)
+	}
+	return comparison, nil
+}
+
+func (s *compareSvc) ListComparisons(userId uuid.UUID) ([]*model.Comparison, error) {
+	comparisons, err := s.stg.Comparison(s.ctx).ListByUserId(userId)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to list comparisons")
+	}
+	return comparisons, nil
+}
+
+// narrate asks the model why the best post outperforms the others.
+// The comparison is still useful without it, so failures only leave the narrative empty.
+func (s *compareSvc) narrate(comparison *model.Comparison, analysisByPost map[string]*model.PostAnalysis) string {
+	if s.GptClient == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	for i, post := range comparison.Posts {
+		analysis := analysisByPost[post.PostId]
+		fmt.Fprintf(&b, "Clip %d by @%s, posted %s\n", i+1, post.UserHandler, post.PostDate.Format("2006-01-02"))
+		fmt.Fprintf(&b, "Big idea: %s\n", post.BigIdea)
+		fmt.Fprintf(&b, "Likes %d, comments %d, plays %d\n", post.LikeCount, post.CommentCount, post.PlayCount)
+		if post.Baseline != nil {
+			fmt.Fprintf(&b, "Channel: %d followers, average likes %d, comments %d, plays %d\n",
+				post.Baseline.Followers, post.Baseline.AverageLikes, post.Baseline.AverageComments, post.Baseline.AveragePlays)
+		}
+		fmt.Fprintf(&b, "Viral score %d, verdict %s: %s\n", post.ViralScore, post.Verdict.Status, post.Verdict.Reasoning)
+		for _, m := range analysis.Metrics {
+			fmt.Fprintf(&b, "- %s: %d. %s\n", m.Key, m.Score, m.Explanation)
+		}
+		b.WriteString("\n")
+	}
+
+	narrative, err := s.GptClient.ChatCompletion(gpt.ChatRequest{
+		Model: gpt.ChatModel,
+		Messages: []gpt.ChatMessage{
+			{Role: "system", Content: compareSystemPrompt},
+			{Role: "user", Content: b.String()},
+		},
+	})
+	if err != nil {
+		logger.Warnf("Failed to write comparison narrative: %v", err)
+		return ""
+	}
+	return strings.TrimSpace(narrative)
+}
+
+// baselineLift returns the ratio of a count to the channel average, nil without an average.
+func baselineLift(count, average int64) *float64 {
+	if average <= 0 {
+		return nil
+	}
+	return lo.ToPtr(float64(count) / float64(average))
+}
@@ -0,0 +1,618 @@
+package svc
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/amahdian/cliplab-be/storage"
+	"github.com/google/uuid"
+	"github.com/pgvector/pgvector-go"
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+)
+
+// fakeStg is an in-memory storage for the services under test.
+// The storages and methods a test does not use are left nil and panic when called.
+type fakeStg struct {
+	storage.PgStorage
+
+	users         *fakeUserStg
+	sessions      *fakeUserSessionStg
+	refreshTokens *fakeRefreshTokenStg
+	codes         *fakeUserCodeStg
+	recoveryCodes *fakeUserRecoveryCodeStg
+	identities    *fakeUserIdentityStg
+	requests      *fakeAnalyzeRequestStg
+	posts         *fakePostStg
+	contents      *fakePostContentStg
+	snapshots     *fakePostStatSnapshotStg
+	analyses      *fakePostAnalysisStg
+	chats         *fakeChatMessageStg
+	remixes       *fakeRemixVariantStg
+	comparisons   *fakeComparisonStg
+	workspaces    *fakeWorkspaceStg
+	members       *fakeWorkspaceMemberStg
+	invitations   *fakeWorkspaceInvitationStg
+}
+
+func newFakeStg() *fakeStg {
+	return &fakeStg{
+		users:         &fakeUserStg{table: newFakeTable(func(m *model.User) *uuid.UUID { return &m.ID })},
+		sessions:      &fakeUserSessionStg{table: newFakeTable(func(m *model.UserSession) *uuid.UUID { return &m.ID })},
+		refreshTokens: &fakeRefreshTokenStg{table: newFakeTable(func(m *model.RefreshToken) *uuid.UUID { return &m.ID })},
+		codes:         &fakeUserCodeStg{table: newFakeTable(func(m *model.UserCode) *uuid.UUID { return &m.ID })},
+		recoveryCodes: &fakeUserRecoveryCodeStg{},
+		identities:    &fakeUserIdentityStg{},
+		requests:      &fakeAnalyzeRequestStg{table: newFakeTable(func(m *model.AnalyzeRequest) *uuid.UUID { return &m.ID })},
+		posts:         &fakePostStg{library: map[uuid.UUID][]*model.LibraryPost{}},
+		contents:      &fakePostContentStg{table: newFakeTable(func(m *model.PostContent) *uuid.UUID { return &m.ID })},
+		snapshots:     &fakePostStatSnapshotStg{},
+		analyses:      &fakePostAnalysisStg{},
+		chats:         &fakeChatMessageStg{},
+		remixes:       &fakeRemixVariantStg{},
+		comparisons:   &fakeComparisonStg{},
+		workspaces:    &fakeWorkspaceStg{table: newFakeTable(func(m *model.Workspace) *uuid.UUID { return &m.ID })},
+		members:       &fakeWorkspaceMemberStg{},
+		invitations:   &fakeWorkspaceInvitationStg{table: newFakeTable(func(m *model.WorkspaceInvitation) *uuid.UUID { return &m.ID })},
+	}
+}
+
+func (s *fakeStg) User(context.Context) storage.UserStorage { return s.users }
+
+func (s *fakeStg) UserSession(context.Context) storage.UserSessionStorage { return s.sessions }
+
+func (s *fakeStg) RefreshToken(context.Context) storage.RefreshTokenStorage { return s.refreshTokens }
+
+func (s *fakeStg) UserCode(context.Context) storage.UserCodeStorage { return s.codes }
+
+func (s *fakeStg) UserRecoveryCode(context.Context) storage.UserRecoveryCodeStorage {
+	return s.recoveryCodes
+}
+
+func (s *fakeStg) UserIdentity(context.Context) storage.UserIdentityStorage { return s.identities }
+
+func (s *fakeStg) AnalyzeRequest(context.Context) storage.AnalyzeRequestStorage { return s.requests }
+
+func (s *fakeStg) Post(context.Context) storage.PostStorage { return s.posts }
+
+func (s *fakeStg) PostContent(context.Context) storage.PostContentStorage { return s.contents }
+
+func (s *fakeStg) PostStatSnapshot(context.Context) storage.PostStatSnapshotStorage {
+	return s.snapshots
+}
+
+func (s *fakeStg) PostAnalysis(context.Context) storage.PostAnalysisStorage { return s.analyses }
+
+func (s *fakeStg) ChatMessage(context.Context) storage.ChatMessageStorage { return s.chats }
+
+func (s *fakeStg) RemixVariant(context.Context) storage.RemixVariantStorage { return s.remixes }
+
+func (s *fakeStg) Comparison(context.Context) storage.ComparisonStorage { return s.comparisons }
+
+func (s *fakeStg) Workspace(context.Context) storage.WorkspaceStorage { return s.workspaces }
+
+func (s *fakeStg) WorkspaceMember(context.Context) storage.WorkspaceMemberStorage { return s.members }
+
+func (s *fakeStg) WorkspaceInvitation(context.Context) storage.WorkspaceInvitationStorage {
+	return s.invitations
+}
+
+// fakeTable keeps the models by id. The models are stored as given, so the changes of the callers are shared.
+type fakeTable[M any] struct {
+	rows map[uuid.UUID]M
+	id   func(M) *uuid.UUID
+}
+
+func newFakeTable[M any](id func(M) *uuid.UUID) *fakeTable[M] {
+	return &fakeTable[M]{rows: map[uuid.UUID]M{}, id: id}
+}
+
+func (t *fakeTable[M]) create(m M) error {
+	if id := t.id(m); *id == uuid.Nil {
+		*id = uuid.New()
+	}
+	t.rows[*t.id(m)] = m
+	return nil
+}
+
+func (t *fakeTable[M]) find(id uuid.UUID) (M, error) {
+	m, ok := t.rows[id]
+	if !ok {
+		return m, gorm.ErrRecordNotFound
+	}
+	return m, nil
+}
+
+func (t *fakeTable[M]) update(m M) error {
+	t.rows[*t.id(m)] = m
+	return nil
+}
+
+func (t *fakeTable[M]) filter(match func(M) bool) []M {
+	var list []M
+	for _, m := range t.rows {
+		if match(m) {
+			list = append(list, m)
+		}
+	}
+	return list
+}
+
+type fakeUserStg struct {
+	storage.UserStorage
+	table *fakeTable[*model.User]
+}
+
+func (s *fakeUserStg) CreateOne(m *model.User) error { return s.table.create(m) }
+
+func (s *fakeUserStg) FindById(id uuid.UUID) (*model.User, error) { return s.table.find(id) }
+
+func (s *fakeUserStg) UpdateOne(m *model.User, _ bool) error { return s.table.update(m) }
+
+func (s *fakeUserStg) FindByEmail(email string) (*model.User, error) {
+	users := s.table.filter(func(u *model.User) bool { return strings.EqualFold(u.Email, email) })
+	if len(users) == 0 {
+		return nil, nil
+	}
+	return users[0], nil
+}
+
+func (s *fakeUserStg) ClaimTotpStep(id uuid.UUID, step int64) (bool, error) {
+	user, err := s.table.find(id)
+	if err != nil || user.TotpLastStep >= step {
+		return false, nil
+	}
+	user.TotpLastStep = step
+	return true, nil
+}
+
+func (s *fakeUserStg) DeleteById(id uuid.UUID) error {
+	delete(s.table.rows, id)
+	return nil
+}
+
+func (s *fakeUserStg) ListDueForDeletion(before time.Time, limit int) ([]*model.User, error) {
+	users := s.table.filter(func(u *model.User) bool {
+		return u.DeletionScheduledAt != nil && u.DeletionScheduledAt.Before(before)
+	})
+	return users[:min(limit, len(users))], nil
+}
+
+func (s *fakeUserStg) CancelDeletion(id uuid.UUID) error {
+	if user, ok := s.table.rows[id]; ok {
+		user.DeletionScheduledAt = nil
+	}
+	return nil
+}
+
+type fakeUserSessionStg struct {
+	storage.UserSessionStorage
+	table *fakeTable[*model.UserSession]
+}
+
+func (s *fakeUserSessionStg) CreateOne(m *model.UserSession) error { return s.table.create(m) }
+
+func (s *fakeUserSessionStg) FindById(id uuid.UUID) (*model.UserSession, error) {
+	return s.table.find(id)
+}
+
+func (s *fakeUserSessionStg) UpdateOne(m *model.UserSession, _ bool) error { return s.table.update(m) }
+
+func (s *fakeUserSessionStg) RevokeAll(userId uuid.UUID) error {
+	now := time.Now()
+	for _, session := range s.table.filter(func(session *model.UserSession) bool { return session.UserId == userId }) {
+		session.RevokedAt = &now
+	}
+	return nil
+}
+
+func (s *fakeUserSessionStg) Revoke(userId, id uuid.UUID) (bool, error) {
+	session, ok := s.table.rows[id]
+	if !ok || session.UserId != userId || session.RevokedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	session.RevokedAt = &now
+	return true, nil
+}
+
+type fakeRefreshTokenStg struct {
+	storage.RefreshTokenStorage
+	table *fakeTable[*model.RefreshToken]
+}
+
+func (s *fakeRefreshTokenStg) CreateOne(m *model.RefreshToken) error { return s.table.create(m) }
+
+func (s *fakeRefreshTokenStg) FindByHash(tokenHash string) (*model.RefreshToken, error) {
+	tokens := s.table.filter(func(t *model.RefreshToken) bool { return t.TokenHash == tokenHash })
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+	return tokens[0], nil
+}
+
+func (s *fakeRefreshTokenStg) MarkUsed(id uuid.UUID) (bool, error) {
+	token, ok := s.table.rows[id]
+	if !ok || token.UsedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	token.UsedAt = &now
+	return true, nil
+}
+
+type fakeUserCodeStg struct {
+	storage.UserCodeStorage
+	table *fakeTable[*model.UserCode]
+}
+
+func (s *fakeUserCodeStg) CreateOne(m *model.UserCode) error {
+	if m.CreatedAt.IsZero() {
+		m.CreatedAt = time.Now()
+	}
+	return s.table.create(m)
+}
+
+func (s *fakeUserCodeStg) FindLatestActive(userId uuid.UUID, purpose model.CodePurpose) (*model.UserCode, error) {
+	return s.latest(func(c *model.UserCode) bool {
+		return c.UserId == userId && c.Purpose == purpose && c.ConsumedAt == nil
+	}), nil
+}
+
+func (s *fakeUserCodeStg) FindActiveByHash(purpose model.CodePurpose, codeHash string) (*model.UserCode, error) {
+	return s.latest(func(c *model.UserCode) bool {
+		return c.Purpose == purpose && c.CodeHash == codeHash && c.ConsumedAt == nil
+	}), nil
+}
+
+func (s *fakeUserCodeStg) FindLatest(userId uuid.UUID, purpose model.CodePurpose) (*model.UserCode, error) {
+	return s.latest(func(c *model.UserCode) bool {
+		return c.UserId == userId && c.Purpose == purpose
+	}), nil
+}
+
+func (s *fakeUserCodeStg) CountSince(userId uuid.UUID, purpose model.CodePurpose, since time.Time) (int64, error) {
+	return int64(len(s.table.filter(func(c *model.UserCode) bool {
+		return c.UserId == userId && c.Purpose == purpose && !c.CreatedAt.Before(since)
+	}))), nil
+}
+
+func (s *fakeUserCodeStg) ClaimAttempt(id uuid.UUID, maxAttempts int) (bool, error) {
+	code, ok := s.table.rows[id]
+	if !ok || code.Attempts >= maxAttempts {
+		return false, nil
+	}
+	code.Attempts++
+	return true, nil
+}
+
+func (s *fakeUserCodeStg) Consume(id uuid.UUID) (bool, error) {
+	code, ok := s.table.rows[id]
+	if !ok || code.ConsumedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	code.ConsumedAt = &now
+	return true, nil
+}
+
+func (s *fakeUserCodeStg) ConsumeAll(userId uuid.UUID, purpose model.CodePurpose) error {
+	now := time.Now()
+	for _, c := range s.table.filter(func(c *model.UserCode) bool {
+		return c.UserId == userId && c.Purpose == purpose && c.ConsumedAt == nil
+	}) {
+		c.ConsumedAt = &now
+	}
+	return nil
+}
+
+func (s *fakeUserCodeStg) latest(match func(*model.UserCode) bool) *model.UserCode {
+	var latest *model.UserCode
+	for _, c := range s.table.filter(match) {
+		if latest == nil || c.CreatedAt.After(latest.CreatedAt) {
+			latest = c
+		}
+	}
+	return latest
+}
+
+type fakeUserRecoveryCodeStg struct {
+	storage.UserRecoveryCodeStorage
+	list []*model.UserRecoveryCode
+}
+
+func (s *fakeUserRecoveryCodeStg) CreateMany(list []*model.UserRecoveryCode) error {
+	s.list = append(s.list, list...)
+	return nil
+}
+
+func (s *fakeUserRecoveryCodeStg) Claim(userId uuid.UUID, codeHash string) (bool, error) {
+	for _, c := range s.list {
+		if c.UserId == userId && c.CodeHash == codeHash && c.UsedAt == nil {
+			c.UsedAt = lo.ToPtr(time.Now())
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *fakeUserRecoveryCodeStg) DeleteByUserId(userId uuid.UUID) error {
+	s.list = lo.Reject(s.list, func(c *model.UserRecoveryCode, _ int) bool { return c.UserId == userId })
+	return nil
+}
+
+// filterByOwner returns the models whose owner, a user or a workspace, has the id.
+func filterByOwner[M any](list []M, id uuid.UUID, owner func(M) uuid.UUID) []M {
+	var res []M
+	for _, m := range list {
+		if owner(m) == id {
+			res = append(res, m)
+		}
+	}
+	return res
+}
+
+type fakeUserIdentityStg struct {
+	storage.UserIdentityStorage
+	list []*model.UserIdentity
+}
+
+func (s *fakeUserIdentityStg) CreateOne(m *model.UserIdentity) error {
+	m.ID = uuid.New()
+	s.list = append(s.list, m)
+	return nil
+}
+
+func (s *fakeUserIdentityStg) FindByProvider(provider model.Provider, providerId string) (*model.UserIdentity, error) {
+	for _, i := range s.list {
+		if i.Provider == provider && i.ProviderId == providerId {
+			return i, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *fakeUserIdentityStg) ListByUserId(userId uuid.UUID) ([]*model.UserIdentity, error) {
+	return filterByOwner(s.list, userId, func(m *model.UserIdentity) uuid.UUID { return m.UserId }), nil
+}
+
+type fakeAnalyzeRequestStg struct {
+	storage.AnalyzeRequestStorage
+	table *fakeTable[*model.AnalyzeRequest]
+}
+
+func (s *fakeAnalyzeRequestStg) CreateOne(m *model.AnalyzeRequest) error { return s.table.create(m) }
+
+func (s *fakeAnalyzeRequestStg) FindById(id uuid.UUID) (*model.AnalyzeRequest, error) {
+	return s.table.find(id)
+}
+
+func (s *fakeAnalyzeRequestStg) UpdateOne(m *model.AnalyzeRequest, _ bool) error {
+	return s.table.update(m)
+}
+
+func (s *fakeAnalyzeRequestStg) ListByUserId(userId uuid.UUID) ([]*model.AnalyzeRequest, error) {
+	return s.table.filter(func(r *model.AnalyzeRequest) bool { return r.UserId != nil && *r.UserId == userId }), nil
+}
+
+func (s *fakeAnalyzeRequestStg) ListByPostId(id string) ([]*model.AnalyzeRequest, error) {
+	return s.table.filter(func(r *model.AnalyzeRequest) bool { return r.PostId != nil && *r.PostId == id }), nil
+}
+
+func (s *fakeAnalyzeRequestStg) DetachUser(userId uuid.UUID) error {
+	requests, _ := s.ListByUserId(userId)
+	for _, r := range requests {
+		r.UserId = nil
+		r.UserIP = ""
+	}
+	return nil
+}
+
+type fakePostStg struct {
+	storage.PostStorage
+	// library holds the personal library of each user, workspace libraries are not faked.
+	library map[uuid.UUID][]*model.LibraryPost
+}
+
+func (s *fakePostStg) FindByHashId(id string) (*model.Post, error) {
+	return &model.Post{ID: id}, nil
+}
+
+func (s *fakePostStg) ListLibrary(owner model.LibraryOwner, _ string, page, size int) ([]*model.LibraryPost, int64, error) {
+	posts := s.library[owner.UserId]
+	from := min(page*size, len(posts))
+	return posts[from:min(from+size, len(posts))], int64(len(posts)), nil
+}
+
+// fakePostContentStg keeps the embeddings, but its searches find nothing.
+type fakePostContentStg struct {
+	storage.PostContentStorage
+	table *fakeTable[*model.PostContent]
+}
+
+func (s *fakePostContentStg) CreateMany(list []*model.PostContent) error {
+	for _, m := range list {
+		_ = s.table.create(m)
+	}
+	return nil
+}
+
+func (s *fakePostContentStg) ListByPostId(postId string) ([]*model.PostContent, error) {
+	return s.table.filter(func(c *model.PostContent) bool { return c.PostID == postId }), nil
+}
+
+func (s *fakePostContentStg) DeleteByPostId(postId string) error {
+	contents, _ := s.ListByPostId(postId)
+	for _, c := range contents {
+		delete(s.table.rows, c.ID)
+	}
+	return nil
+}
+
+func (s *fakePostContentStg) UpdateEmbedding(id uuid.UUID, embedding pgvector.Vector) error {
+	if content, ok := s.table.rows[id]; ok {
+		content.Embedding = &embedding
+	}
+	return nil
+}
+
+func (s *fakePostContentStg) SearchByEmbedding(model.LibraryOwner, pgvector.Vector, int) ([]*model.PostContentMatch, error) {
+	return nil, nil
+}
+
+func (s *fakePostContentStg) SearchSimilarPosts(string, model.SimilarPostsScope, model.LibraryOwner, int) ([]*model.PostContentMatch, error) {
+	return nil, nil
+}
+
+type fakePostAnalysisStg struct {
+	storage.PostAnalysisStorage
+	list []*model.PostAnalysis
+}
+
+func (s *fakePostAnalysisStg) CreateOne(m *model.PostAnalysis) error {
+	m.ID = uuid.New()
+	s.list = append(s.list, m)
+	return nil
+}
+
+func (s *fakePostAnalysisStg) UpsertOne(m *model.PostAnalysis, _ bool) error {
+	for i, a := range s.list {
+		if a.ID == m.ID {
+			s.list[i] = m
+			return nil
+		}
+	}
+	s.list = append(s.list, m)
+	return nil
+}
+
+func (s *fakePostAnalysisStg) FindByPostId(id string) (*model.PostAnalysis, error) {
+	for _, a := range s.list {
+		if a.PostId == id {
+			return a, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (s *fakePostAnalysisStg) ListByPostIds(ids []string) ([]*model.PostAnalysis, error) {
+	var res []*model.PostAnalysis
+	for _, a := range s.list {
+		if slices.Contains(ids, a.PostId) {
+			res = append(res, a)
+		}
+	}
+	return res, nil
+}
+
+type fakePostStatSnapshotStg struct {
+	storage.PostStatSnapshotStorage
+	list []*model.PostStatSnapshot
+}
+
+func (s *fakePostStatSnapshotStg) CreateSchedule(snapshots []*model.PostStatSnapshot) error {
+	s.list = append(s.list, snapshots...)
+	return nil
+}
+
+type fakeChatMessageStg struct {
+	storage.ChatMessageStorage
+	list []*model.ChatMessage
+}
+
+func (s *fakeChatMessageStg) ListByUserId(userId uuid.UUID) ([]*model.ChatMessage, error) {
+	return filterByOwner(s.list, userId, func(m *model.ChatMessage) uuid.UUID { return m.UserId }), nil
+}
+
+type fakeRemixVariantStg struct {
+	storage.RemixVariantStorage
+	list []*model.RemixVariant
+}
+
+func (s *fakeRemixVariantStg) ListByUserId(userId uuid.UUID) ([]*model.RemixVariant, error) {
+	return filterByOwner(s.list, userId, func(m *model.RemixVariant) uuid.UUID { return m.UserId }), nil
+}
+
+type fakeComparisonStg struct {
+	storage.ComparisonStorage
+	list []*model.Comparison
+}
+
+func (s *fakeComparisonStg) ListByUserId(userId uuid.UUID) ([]*model.Comparison, error) {
+	return filterByOwner(s.list, userId, func(m *model.Comparison) uuid.UUID { return m.UserId }), nil
+}
+
+type fakeWorkspaceStg struct {
+	storage.WorkspaceStorage
+	table *fakeTable[*model.Workspace]
+}
+
+func (s *fakeWorkspaceStg) CreateOne(m *model.Workspace) error { return s.table.create(m) }
+
+func (s *fakeWorkspaceStg) FindById(id uuid.UUID) (*model.Workspace, error) { return s.table.find(id) }
+
+func (s *fakeWorkspaceStg) DeleteById(id uuid.UUID) error {
+	delete(s.table.rows, id)
+	return nil
+}
+
+// fakeWorkspaceMemberStg keeps the memberships in a list, they are keyed by workspace and user.
+type fakeWorkspaceMemberStg struct {
+	storage.WorkspaceMemberStorage
+	list []*model.WorkspaceMember
+}
+
+func (s *fakeWorkspaceMemberStg) CreateOne(m *model.WorkspaceMember) error {
+	s.list = append(s.list, m)
+	return nil
+}
+
+func (s *fakeWorkspaceMemberStg) Find(workspaceId, userId uuid.UUID) (*model.WorkspaceMember, error) {
+	for _, m := range s.list {
+		if m.WorkspaceId == workspaceId && m.UserId == userId {
+			return m, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *fakeWorkspaceMemberStg) ListByWorkspaceId(workspaceId uuid.UUID) ([]*model.WorkspaceMember, error) {
+	return filterByOwner(s.list, workspaceId, func(m *model.WorkspaceMember) uuid.UUID { return m.WorkspaceId }), nil
+}
+
+func (s *fakeWorkspaceMemberStg) ListByUserId(userId uuid.UUID) ([]*model.WorkspaceMember, error) {
+	return filterByOwner(s.list, userId, func(m *model.WorkspaceMember) uuid.UUID { return m.UserId }), nil
+}
+
+func (s *fakeWorkspaceMemberStg) UpdateRole(workspaceId, userId uuid.UUID, role model.WorkspaceRole) error {
+	if m, _ := s.Find(workspaceId, userId); m != nil {
+		m.Role = role
+	}
+	return nil
+}
+
+type fakeWorkspaceInvitationStg struct {
+	storage.WorkspaceInvitationStorage
+	table *fakeTable[*model.WorkspaceInvitation]
+}
+
+func (s *fakeWorkspaceInvitationStg) CreateOne(m *model.WorkspaceInvitation) error {
+	return s.table.create(m)
+}
+
+func (s *fakeWorkspaceInvitationStg) UpdateOne(m *model.WorkspaceInvitation, _ bool) error {
+	return s.table.update(m)
+}
+
+func (s *fakeWorkspaceInvitationStg) FindPendingByHash(tokenHash string) (*model.WorkspaceInvitation, error) {
+	pending := s.table.filter(func(i *model.WorkspaceInvitation) bool {
+		return i.TokenHash == tokenHash && i.AcceptedAt == nil
+	})
+	if len(pending) == 0 {
+		return nil, nil
+	}
+	return pending[0], nil
+}
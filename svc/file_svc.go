@@ -37,8 +37,8 @@ type fileSvc struct {
 }
 
 func newFileSvc(ctx context.Context, config StorageConfig) FileSvc {
-	if config.AwsConfig == nil && config.AwsConfig.Endpoint == nil {
-		return &noopFileSvc{}
+	if config.AwsConfig == nil || config.AwsConfig.Endpoint == nil {
+		return &noopFileSvc{ctx: ctx}
 	}
 
 	mySession := session.Must(session.NewSession())
@@ -117,6 +117,10 @@ func (s *fileSvc) GetFileDownloadUrl(objectKey string, expiry time.Duration) (st
 }
 
 func (s *fileSvc) DownloadFiles(urls []string) ([]string, error) {
+	return downloadFiles(s.ctx, urls)
+}
+
+func downloadFiles(ctx context.Context, urls []string) ([]string, error) {
 	// 1. Create a unique temporary directory for this batch of downloads.
 	tempDir, err := os.MkdirTemp(os.TempDir(), "downloads_*")
 	if err != nil {
@@ -127,7 +131,7 @@ func (s *fileSvc) DownloadFiles(urls []string) ([]string, error) {
 
 	results := make([]string, len(urls))
 
-	g, _ := errgroup.WithContext(s.ctx)
+	g, _ := errgroup.WithContext(ctx)
 	for i, url := range urls {
 		g.Go(func() error {
 			filePath, err := downloadFile(url, tempDir)
@@ -203,7 +207,11 @@ func generateSafeFilename(fileURL string) (string, error) {
 	return newFilename, nil
 }
 
-type noopFileSvc struct{}
+// noopFileSvc is used when the object storage is not configured.
+// Downloads do not depend on the object storage, so they still work.
+type noopFileSvc struct {
+	ctx context.Context
+}
 
 func (s *noopFileSvc) StoreInS3(fileURL, folder, objectKey string) (*string, error) {
 	return nil, nil
@@ -214,5 +222,5 @@ func (s *noopFileSvc) GetFileDownloadUrl(objectKey string, expiry time.Duration)
 }
 
 func (s *noopFileSvc) DownloadFiles(urls []string) ([]string, error) {
-	return nil, nil
+	return downloadFiles(s.ctx, urls)
 }
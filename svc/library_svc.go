@@ -0,0 +1,89 @@
+package svc
+
+import (
+	"context"
+
+	"github.com/amahdian/cliplab-be/domain/contracts/resp"
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/amahdian/cliplab-be/global/env"
+	"github.com/amahdian/cliplab-be/global/errs"
+	"github.com/amahdian/cliplab-be/pkg/paged"
+	"github.com/amahdian/cliplab-be/storage"
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+)
+
+type LibrarySvc interface {
+	GetLibrary(userId uuid.UUID, workspaceId *uuid.UUID, category string, page, size int) (*paged.PaginatedResponse, error)
+	GetBenchmarks(category string) ([]*model.NicheBenchmark, error)
+}
+
+type librarySvc struct {
+	ctx  context.Context
+	stg  storage.PgStorage
+	envs *env.Envs
+}
+
+func newLibrarySvc(ctx context.Context, stg storage.PgStorage, envs *env.Envs) LibrarySvc {
+	return &librarySvc{
+		ctx:  ctx,
+		stg:  stg,
+		envs: envs,
+	}
+}
+
+// GetLibrary returns the posts analyzed by the user, or into the workspace when one is given,
+// optionally restricted to a category and its subcategories.
+func (s *librarySvc) GetLibrary(userId uuid.UUID, workspaceId *uuid.UUID, category string, page, size int) (*paged.PaginatedResponse, error) {
+	owner, err := resolveLibraryOwner(s.ctx, s.stg, userId, workspaceId, model.WorkspaceRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	posts, total, err := s.stg.Post(s.ctx).ListLibrary(owner, category, page, size)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to list library")
+	}
+
+	postCategories, err := s.stg.PostCategory(s.ctx).ListByPostIds(lo.Map(posts, func(p *model.LibraryPost, _ int) string {
+		return p.PostId
+	}))
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to list post categories")
+	}
+	categoriesByPost := map[string][]string{}
+	for _, pc := range postCategories {
+		if pc.Category != nil {
+			categoriesByPost[pc.PostId] = append(categoriesByPost[pc.PostId], pc.Category.Path)
+		}
+	}
+
+	items := lo.Map(posts, func(p *model.LibraryPost, _ int) *resp.LibraryPost {
+		return &resp.LibraryPost{
+			RequestId:    p.RequestId.String(),
+			PostId:       p.PostId,
+			Link:         p.Link,
+			ImageUrl:     p.ImageUrl,
+			UserHandler:  p.UserAnchor,
+			PostDate:     p.PostDate,
+			LikeCount:    p.LikeCount,
+			CommentCount: p.CommentCount,
+			ViewCount:    p.VideoPlayCount,
+			ViralScore:   p.ViralScore,
+			BigIdea:      p.BigIdea,
+			Categories:   categoriesByPost[p.PostId],
+			AnalyzedAt:   p.AnalyzedAt,
+		}
+	})
+
+	return paged.NewPaginatedResponse(items, len(items), int(total), page, size, ""), nil
+}
+
+// GetBenchmarks returns the performance of the analyzed posts per niche.
+func (s *librarySvc) GetBenchmarks(category string) ([]*model.NicheBenchmark, error) {
+	benchmarks, err := s.stg.Post(s.ctx).SummarizeBenchmarks(category)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to summarize benchmarks")
+	}
+	return benchmarks, nil
+}
@@ -0,0 +1,136 @@
+package svc
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/amahdian/cliplab-be/clients/rocksolid"
+	"github.com/amahdian/cliplab-be/domain/model"
+)
+
+// minOutlierSampleSize is the number of channel reels needed for the statistics to mean anything.
+const minOutlierSampleSize = 3
+
+type reelCounts struct {
+	Views    int64
+	Likes    int64
+	Comments int64
+}
+
+// channelReelCounts returns the counts of the channel reels, leaving out the analyzed post.
+func channelReelCounts(postId string, reels rocksolid.Reels) []reelCounts {
+	counts := make([]reelCounts, 0, len(reels.Reels))
+	for _, reel := range reels.Reels {
+		media := reel.Node.Media
+		if media.Code == postId {
+			continue
+		}
+		views := media.PlayCount
+		if views == 0 {
+			views = media.ViewCount
+		}
+		counts = append(counts, reelCounts{Views: views, Likes: media.LikeCount, Comments: media.CommentCount})
+	}
+	return counts
+}
+
+// newOutlierStats measures the counts of a post against the counts of the channel reels.
+// It returns nil when there are too few reels to compare with.
+func newOutlierStats(post reelCounts, reels []reelCounts) *model.OutlierStats {
+	if len(reels) < minOutlierSampleSize {
+		return nil
+	}
+
+	column := func(get func(reelCounts) int64) []float64 {
+		values := make([]float64, len(reels))
+		for i, r := range reels {
+			values[i] = float64(get(r))
+		}
+		return values
+	}
+
+	return &model.OutlierStats{
+		SampleSize: len(reels),
+		Views:      newOutlierStat(post.Views, column(func(r reelCounts) int64 { return r.Views })),
+		Likes:      newOutlierStat(post.Likes, column(func(r reelCounts) int64 { return r.Likes })),
+		Comments:   newOutlierStat(post.Comments, column(func(r reelCounts) int64 { return r.Comments })),
+	}
+}
+
+func newOutlierStat(value int64, sample []float64) *model.OutlierStat {
+	sort.Float64s(sample)
+	v := float64(value)
+
+	var sum, below, equal float64
+	for _, s := range sample {
+		sum += s
+		if s < v {
+			below++
+		} else if s == v {
+			equal++
+		}
+	}
+	n := float64(len(sample))
+	mean := sum / n
+
+	var squares float64
+	for _, s := range sample {
+		squares += (s - mean) * (s - mean)
+	}
+	stdDev := math.Sqrt(squares / n)
+
+	median := sortedMedian(sample)
+
+	stat := &model.OutlierStat{
+		Value:  value,
+		Median: median,
+		Mean:   round2(mean),
+		StdDev: round2(stdDev),
+		// ties count as half above and half below
+		Percentile: round2((below + equal/2) / n * 100),
+	}
+	if stdDev > 0 {
+		stat.ZScore = round2((v - mean) / stdDev)
+	}
+	if median > 0 {
+		stat.MedianMultiple = round2(v / median)
+	}
+	return stat
+}
+
+// outlierSummary describes how far the post is from the channel median, e.g. "4.2x the channel's median views".
+func outlierSummary(stats *model.OutlierStats) string {
+	if stats == nil {
+		return ""
+	}
+
+	metric, stat := "views", stats.Views
+	if stat == nil || stat.MedianMultiple == 0 {
+		metric, stat = "likes", stats.Likes
+	}
+	if stat == nil || stat.MedianMultiple == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%sx the channel's median %s", formatMultiple(stat.MedianMultiple), metric)
+}
+
+func formatMultiple(multiple float64) string {
+	if multiple >= 10 {
+		return fmt.Sprintf("%.0f", multiple)
+	}
+	return fmt.Sprintf("%.1f", multiple)
+}
+
+// sortedMedian returns the median of a sorted, non-empty sample.
+func sortedMedian(sample []float64) float64 {
+	median := sample[len(sample)/2]
+	if len(sample)%2 == 0 {
+		median = (sample[len(sample)/2-1] + sample[len(sample)/2]) / 2
+	}
+	return median
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
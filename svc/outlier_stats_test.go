@@ -0,0 +1,64 @@
+package svc
+
+import (
+	"testing"
+
+	"github.com/amahdian/cliplab-be/clients/rocksolid"
+)
+
+func TestNewOutlierStats(t *testing.T) {
+	reels := []reelCounts{
+		{Views: 1000, Likes: 100, Comments: 10},
+		{Views: 2000, Likes: 200, Comments: 20},
+		{Views: 3000, Likes: 300, Comments: 30},
+		{Views: 4000, Likes: 400, Comments: 40},
+		{Views: 5000, Likes: 500, Comments: 30},
+	}
+
+	stats := newOutlierStats(reelCounts{Views: 12600, Likes: 300, Comments: 5}, reels)
+	if stats == nil || stats.SampleSize != 5 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+
+	views := stats.Views
+	if views.Median != 3000 || views.MedianMultiple != 4.2 || views.Percentile != 100 {
+		t.Errorf("unexpected views: %+v", views)
+	}
+	// mean 3000, population standard deviation ~1414.21
+	if views.ZScore != 6.79 {
+		t.Errorf("unexpected views z-score: %v", views.ZScore)
+	}
+
+	// a tie counts half
+	if stats.Likes.Percentile != 50 || stats.Likes.ZScore != 0 {
+		t.Errorf("unexpected likes: %+v", stats.Likes)
+	}
+	if stats.Comments.Percentile != 0 || stats.Comments.ZScore >= 0 {
+		t.Errorf("unexpected comments: %+v", stats.Comments)
+	}
+
+	if got := outlierSummary(stats); got != "4.2x the channel's median views" {
+		t.Errorf("unexpected summary: %q", got)
+	}
+}
+
+func TestNewOutlierStatsNeedsSample(t *testing.T) {
+	if stats := newOutlierStats(reelCounts{Views: 10}, []reelCounts{{Views: 1}, {Views: 2}}); stats != nil {
+		t.Errorf("expected no stats for a small sample, got %+v", stats)
+	}
+}
+
+func TestChannelReelCountsSkipsPost(t *testing.T) {
+	var reels rocksolid.Reels
+	for _, code := range []string{"abc", "post", "def"} {
+		var reel rocksolid.Reel
+		reel.Node.Media.Code = code
+		reel.Node.Media.ViewCount = 7
+		reels.Reels = append(reels.Reels, reel)
+	}
+
+	counts := channelReelCounts("post", reels)
+	if len(counts) != 2 || counts[0].Views != 7 {
+		t.Errorf("unexpected counts: %+v", counts)
+	}
+}
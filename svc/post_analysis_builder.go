@@ -0,0 +1,244 @@
+package svc
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/amahdian/cliplab-be/clients/gemini"
+	"github.com/amahdian/cliplab-be/clients/gpt"
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/amahdian/cliplab-be/global/errs"
+	"github.com/amahdian/cliplab-be/pkg/logger"
+	"github.com/amahdian/cliplab-be/svc/scoring"
+	"github.com/pemistahl/lingua-go"
+	"github.com/samber/lo"
+)
+
+// analysisSections is the order in which the model generates the sections of an analysis.
+var analysisSections = []string{
+	gemini.SectionSummary,
+	gemini.SectionContent,
+	gemini.SectionAnalysis,
+	gemini.SectionRemix,
+	gemini.SectionPublish,
+}
+
+// postAnalysisBuilder maps a Gemini analysis to the post analysis and transcripts of a post.
+// Sections are applied one at a time, so a streamed analysis can be persisted while it is generated.
+type postAnalysisBuilder struct {
+	detector lingua.LanguageDetector
+	scorer   scoring.Scorer
+
+	result      gemini.AnalysisResponse
+	analysis    *model.PostAnalysis
+	transcripts []*model.PostContent
+}
+
+func newPostAnalysisBuilder(postId string, detector lingua.LanguageDetector, scorer scoring.Scorer) *postAnalysisBuilder {
+	return &postAnalysisBuilder{
+		detector: detector,
+		scorer:   scorer,
+		analysis: &model.PostAnalysis{PostId: postId},
+	}
+}
+
+// decode reads a single streamed section into the result.
+func (b *postAnalysisBuilder) decode(section string, raw json.RawMessage) error {
+	var target interface{}
+	switch section {
+	case gemini.SectionSummary:
+		target = &b.result.Summary
+	case gemini.SectionContent:
+		target = &b.result.Content
+	case gemini.SectionAnalysis:
+		target = &b.result.Analysis
+	case gemini.SectionRemix:
+		target = &b.result.Remix
+	case gemini.SectionPublish:
+		target = &b.result.Publish
+	default:
+		return errs.Newf(errs.InvalidArgument, nil, "unknown analysis section %s", section)
+	}
+
+	if err := json.Unmarshal(raw, target); err != nil {
+		return errs.Wrapf(err, "failed to decode analysis section %s", section)
+	}
+	return nil
+}
+
+func (b *postAnalysisBuilder) applied(section string) bool {
+	return lo.Contains(b.analysis.Sections, section)
+}
+
+// apply copies a decoded section of the result to the post analysis.
+func (b *postAnalysisBuilder) apply(section string) {
+	switch section {
+	case gemini.SectionSummary:
+		b.applySummary()
+	case gemini.SectionContent:
+		b.applyContent()
+	case gemini.SectionAnalysis:
+		b.applyAnalysis()
+	case gemini.SectionRemix:
+		b.applyRemix()
+	case gemini.SectionPublish:
+		b.applyPublish()
+	default:
+		return
+	}
+
+	if !b.applied(section) {
+		b.analysis.Sections = append(b.analysis.Sections, section)
+	}
+}
+
+func (b *postAnalysisBuilder) applySummary() {
+	summary := b.result.Summary
+	b.analysis.BigIdea = summary.BigIdea
+	b.analysis.WhyViral = summary.WhyViral
+	b.analysis.AudienceSentiment = summary.AudienceSentiment
+	b.analysis.SentimentScore = summary.SentimentScore
+	b.analysis.Verdict = model.PostVerdict{
+		Status:    summary.Verdict.Status,
+		Reasoning: summary.Verdict.Reasoning,
+	}
+}
+
+func (b *postAnalysisBuilder) applyContent() {
+	b.transcripts = nil
+	for _, seg := range b.result.Content.Segments {
+		lang, _ := b.detector.DetectLanguageOf(seg.Content)
+
+		b.transcripts = append(b.transcripts, &model.PostContent{
+			PostID:   b.analysis.PostId,
+			Type:     model.ContentTranscript,
+			Text:     seg.Content,
+			Language: lang.IsoCode639_1().String(),
+			Metadata: &model.SegmentPostContentMetadata{
+				Timestamp: seg.Timestamp,
+				Speaker:   seg.Speaker,
+				Emotion:   seg.Emotion,
+				Source:    model.TranscriptSourceGemini,
+			},
+		})
+	}
+}
+
+// applyTranscription replaces the transcript with the segments transcribed by Whisper.
+func (b *postAnalysisBuilder) applyTranscription(transcription *gpt.TranscriptionResult) {
+	segments := transcription.Segments
+	if len(segments) == 0 {
+		segments = []gpt.TranscriptionSegment{{Text: transcription.Text}}
+	}
+
+	b.transcripts = nil
+	for _, seg := range segments {
+		text := strings.TrimSpace(seg.Text)
+		if text == "" {
+			continue
+		}
+		lang, _ := b.detector.DetectLanguageOf(text)
+
+		b.transcripts = append(b.transcripts, &model.PostContent{
+			PostID:   b.analysis.PostId,
+			Type:     model.ContentTranscript,
+			Text:     text,
+			Language: lang.IsoCode639_1().String(),
+			Metadata: &model.SegmentPostContentMetadata{
+				Timestamp: formatTranscriptTimestamp(seg.Start),
+				Source:    model.TranscriptSourceWhisper,
+			},
+		})
+	}
+}
+
+// formatTranscriptTimestamp formats seconds like the timestamps of the Gemini transcript, e.g. [01:05].
+func formatTranscriptTimestamp(seconds float64) string {
+	total := int(seconds)
+	if total >= 3600 {
+		return fmt.Sprintf("[%d:%02d:%02d]", total/3600, total%3600/60, total%60)
+	}
+	return fmt.Sprintf("[%02d:%02d]", total/60, total%60)
+}
+
+func (b *postAnalysisBuilder) applyAnalysis() {
+	analysis := b.result.Analysis
+	b.analysis.Strengths = analysis.Strengths
+	b.analysis.Weaknesses = analysis.Weaknesses
+	b.analysis.Scope = model.PostAnalysisScope{
+		Level:      analysis.Scope.Level,
+		Confidence: analysis.Scope.Confidence,
+	}
+
+	b.analysis.Metrics = nil
+	for _, m := range analysis.Metrics {
+		key, ok := model.ResolveMetricKey(m.Key, m.Label)
+		if !ok {
+			logger.Warnf("Rejecting metric %q with unknown key %q of post %s", m.Label, m.Key, b.analysis.PostId)
+			continue
+		}
+		if lo.ContainsBy(b.analysis.Metrics, func(existing model.PostAnalysisMetric) bool { return existing.Key == key }) {
+			logger.Warnf("Rejecting duplicate metric %s of post %s", key, b.analysis.PostId)
+			continue
+		}
+
+		b.analysis.Metrics = append(b.analysis.Metrics, model.PostAnalysisMetric{
+			Key:         key,
+			Label:       m.Label,
+			Score:       m.Score,
+			Explanation: m.Explanation,
+			Suggestion:  m.Suggestion,
+		})
+	}
+
+	scoreAnalysis(b.scorer, b.analysis)
+}
+
+// scoreAnalysis computes the viral score of the analysis from its metrics and scope.
+func scoreAnalysis(scorer scoring.Scorer, analysis *model.PostAnalysis) {
+	in := scoring.Input{
+		ScopeLevel:      analysis.Scope.Level,
+		ScopeConfidence: analysis.Scope.Confidence,
+	}
+	for _, m := range analysis.Metrics {
+		switch m.Key {
+		case model.MetricTopic:
+			in.Topic = m.Score
+		case model.MetricHook:
+			in.Hook = m.Score
+		case model.MetricPacing:
+			in.Pacing = m.Score
+		case model.MetricValue:
+			in.Value = m.Score
+		case model.MetricShareability:
+			in.Shareability = m.Score
+		case model.MetricCta:
+			in.Cta = m.Score
+		}
+	}
+
+	analysis.ViralScoreBreakdown = scorer.Score(in)
+	analysis.ViralScore = int(analysis.ViralScoreBreakdown.Score)
+}
+
+func (b *postAnalysisBuilder) applyRemix() {
+	b.analysis.HookIdeas = b.result.Remix.HookIdeas
+	b.analysis.ScriptIdeas = b.result.Remix.ScriptIdeas
+}
+
+func (b *postAnalysisBuilder) applyPublish() {
+	publish := b.result.Publish
+
+	hashtags := make([]string, len(publish.Hashtags))
+	for i, hashtag := range publish.Hashtags {
+		hashtags[i] = strings.ReplaceAll(hashtag, "#", "")
+	}
+
+	b.analysis.Hashtags = hashtags
+	b.analysis.Captions = model.PostAnalysisCaptions{
+		Casual:       publish.Captions.Casual,
+		Professional: publish.Captions.Professional,
+		Viral:        publish.Captions.Viral,
+	}
+}
@@ -0,0 +1,51 @@
+package svc
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/amahdian/cliplab-be/clients/gemini"
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/amahdian/cliplab-be/svc/scoring"
+	"github.com/samber/lo"
+)
+
+func TestApplyAnalysisScope(t *testing.T) {
+	cases := []struct {
+		level      string
+		confidence int
+		adjusted   bool
+	}{
+		{"Local", 90, true},
+		{"Local", 40, false},
+		{"Global", 90, false},
+	}
+
+	for _, c := range cases {
+		metrics := lo.Map(model.MetricKeys, func(key model.MetricKey, _ int) map[string]interface{} {
+			return map[string]interface{}{"key": key, "label": string(key), "score": 80}
+		})
+		raw, _ := json.Marshal(map[string]interface{}{
+			"scope":   map[string]interface{}{"level": c.level, "confidence": c.confidence},
+			"metrics": metrics,
+		})
+
+		b := newPostAnalysisBuilder("post", nil, scoring.NewWeightedScorer(scoring.DefaultConfig()))
+		if err := b.decode(gemini.SectionAnalysis, raw); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		b.apply(gemini.SectionAnalysis)
+
+		name := fmt.Sprintf("%s scope at %d%%", c.level, c.confidence)
+		if b.analysis.Scope.Level != c.level || b.analysis.Scope.Confidence != c.confidence {
+			t.Errorf("%s: unexpected scope %+v", name, b.analysis.Scope)
+		}
+		adjusted := lo.ContainsBy(b.analysis.ViralScoreBreakdown.Adjustments, func(a model.ViralScoreAdjustment) bool {
+			return a.Name == model.AdjustmentScope
+		})
+		if adjusted != c.adjusted {
+			t.Errorf("%s: expected the scope adjustment %v, got %+v", name, c.adjusted, b.analysis.ViralScoreBreakdown.Adjustments)
+		}
+	}
+}
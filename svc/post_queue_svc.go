@@ -2,12 +2,17 @@ package svc
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/amahdian/cliplab-be/clients/gemini"
+	"github.com/amahdian/cliplab-be/clients/gpt"
 	"github.com/amahdian/cliplab-be/clients/rocksolid"
+	"github.com/amahdian/cliplab-be/domain/contracts/resp"
 	"github.com/amahdian/cliplab-be/domain/model"
 	"github.com/amahdian/cliplab-be/global/env"
 	"github.com/amahdian/cliplab-be/global/errs"
@@ -16,6 +21,7 @@ import (
 	"github.com/amahdian/cliplab-be/svc/utils"
 	"github.com/google/uuid"
 	"github.com/pemistahl/lingua-go"
+	"github.com/pgvector/pgvector-go"
 	"github.com/samber/lo"
 )
 
@@ -30,16 +36,31 @@ type postQueueSvc struct {
 	envs *env.Envs
 
 	GeminiClient  gemini.Client
+	GptClient     gpt.Client
 	ScraperClient rocksolid.Client
+
+	fileSvc FileSvc
+	wsSvc   WebSocketSvc
 }
 
-func newPostQueueSvc(ctx context.Context, stg storage.PgStorage, envs *env.Envs, geminiClient gemini.Client, scraperClient rocksolid.Client) QueueSvc {
+func newPostQueueSvc(
+	ctx context.Context,
+	stg storage.PgStorage,
+	envs *env.Envs,
+	geminiClient gemini.Client,
+	gptClient gpt.Client,
+	scraperClient rocksolid.Client,
+	fileSvc FileSvc,
+	wsSvc WebSocketSvc) QueueSvc {
 	return &postQueueSvc{
 		ctx:           ctx,
 		stg:           stg,
 		envs:          envs,
 		GeminiClient:  geminiClient,
+		GptClient:     gptClient,
 		ScraperClient: scraperClient,
+		fileSvc:       fileSvc,
+		wsSvc:         wsSvc,
 	}
 }
 
@@ -77,6 +98,10 @@ func (s *postQueueSvc) ProcessRequest(url string, requestId uuid.UUID, platform
 			_ = s.stg.AnalyzeRequest(s.ctx).UpdateOne(request, false)
 			return err
 		} else {
+			// the post is stored by the scrap, link it so partial results can be looked up
+			request.PostId = lo.ToPtr(post.ID)
+			_ = s.stg.AnalyzeRequest(s.ctx).UpdateOne(request, false)
+
 			err = s.processInstagramScrap(request, reelDto, otherReelsDto)
 			if err != nil {
 				request.Status = model.RequestStatusFailed
@@ -132,10 +157,67 @@ func (s *postQueueSvc) RenewPost(url string, requestId uuid.UUID, platform model
 func (s *postQueueSvc) processInstagramScrap(request *model.AnalyzeRequest, reelDto *rocksolid.ReelData, otherReelsDto *rocksolid.Reels) error {
 	detector := lingua.NewLanguageDetectorBuilder().FromAllSpokenLanguages().Build()
 
+	builder := newPostAnalysisBuilder(*request.PostId, detector, newScorer(s.envs))
+	// a retried request overwrites the analysis of the previous attempt
+	if existing, err := s.stg.PostAnalysis(s.ctx).FindByPostId(*request.PostId); err == nil {
+		builder.analysis.ID = existing.ID
+		builder.analysis.CreatedAt = existing.CreatedAt
+	}
+	// and replaces its contents, streamed ones are saved before the analysis succeeds
+	if err := s.stg.PostContent(s.ctx).DeleteByPostId(*request.PostId); err != nil {
+		return errs.Newf(errs.Internal, err, "failed to delete the contents of the previous attempt")
+	}
+	counts := reelCounts{
+		Views:    int64(reelDto.VideoPlayCount),
+		Likes:    reelDto.EdgeMediaPreviewLike.Count,
+		Comments: reelDto.EdgeMediaToParentComment.Count,
+	}
+	builder.analysis.OutlierStats = newOutlierStats(counts, channelReelCounts(*request.PostId, *otherReelsDto))
+
+	// A. Add Caption (from Instagram reelDto)
+	var contents []*model.PostContent
+	if len(reelDto.EdgeMediaToCaption.Edges) > 0 {
+		lang, _ := detector.DetectLanguageOf(reelDto.EdgeMediaToCaption.Edges[0].Node.Text)
+		contents = append(contents, &model.PostContent{
+			PostID:   *request.PostId,
+			Type:     model.ContentCaption,
+			Text:     reelDto.EdgeMediaToCaption.Edges[0].Node.Text,
+			Language: lang.IsoCode639_1().String(),
+		})
+	}
+
+	var onSection gemini.SectionHandler
+	if s.envs.Gemini.Streaming {
+		onSection = func(section string, raw json.RawMessage) {
+			if err := builder.decode(section, raw); err != nil {
+				logger.Warnf("Skipping streamed section of request %s: %v", request.ID, err)
+				return
+			}
+			builder.apply(section)
+			if section == gemini.SectionContent {
+				contents = s.saveContents(append(contents, builder.transcripts...))
+			}
+			s.saveAnalysis(builder.analysis)
+			s.pushAnalysisSection(request, builder, section)
+		}
+	}
+
 	// 2. Get Advanced Video Analysis from Gemini
-	llmRequest, llmResponse, analysis, err := s.getInstagramVideoAnalysis(*reelDto, *otherReelsDto, detector)
+	videoPath, err := s.downloadVideo(reelDto.VideoURL)
+	if err != nil {
+		return err
+	}
+	// the local copy is uploaded to Gemini and kept for the transcription fallback
+	defer func() {
+		_ = os.RemoveAll(filepath.Dir(videoPath))
+	}()
+
+	llmRequest, llmResponse, usage, analysis, err := s.getInstagramVideoAnalysis(*reelDto, *otherReelsDto, videoPath, detector, onSection)
 	request.LlmRequest = llmRequest
 	request.LlmResponse = llmResponse
+	if usage != nil {
+		request.Usage = newLlmUsage(usage, s.envs)
+	}
 	_ = s.stg.AnalyzeRequest(s.ctx).UpdateOne(request, false)
 
 	if err != nil {
@@ -146,122 +228,235 @@ func (s *postQueueSvc) processInstagramScrap(request *model.AnalyzeRequest, reel
 		return errs.Newf(errs.Internal, nil, "Gemini analysis is empty")
 	}
 
-	// 3. Prepare PostContent slice
-	var contents []*model.PostContent
+	// 3. Apply the sections that were not streamed from the final result
+	builder.result = *analysis
+	for _, section := range analysisSections {
+		if builder.applied(section) {
+			continue
+		}
+		builder.apply(section)
+		if section == gemini.SectionContent {
+			contents = append(contents, builder.transcripts...)
+		}
+	}
 
-	// A. Add Caption (from Instagram reelDto)
-	if len(reelDto.EdgeMediaToCaption.Edges) > 0 {
-		lang, _ := detector.DetectLanguageOf(reelDto.EdgeMediaToCaption.Edges[0].Node.Text)
+	// C. Gemini returns no transcript for music-only videos or when it could not fetch the media
+	if len(builder.transcripts) == 0 {
+		if transcription := s.transcribeVideo(videoPath); transcription != nil {
+			builder.applyTranscription(transcription)
+			contents = append(contents, builder.transcripts...)
+			if onSection != nil {
+				s.pushAnalysisSection(request, builder, gemini.SectionContent)
+			}
+		}
+	}
+
+	// B. Add the big idea, so posts can be searched by their core message
+	if builder.analysis.BigIdea != "" {
+		lang, _ := detector.DetectLanguageOf(builder.analysis.BigIdea)
 		contents = append(contents, &model.PostContent{
 			PostID:   *request.PostId,
-			Type:     model.ContentCaption,
-			Text:     reelDto.EdgeMediaToCaption.Edges[0].Node.Text,
+			Type:     model.ContentBigIdea,
+			Text:     builder.analysis.BigIdea,
 			Language: lang.IsoCode639_1().String(),
 		})
 	}
 
-	// C. Add Transcript (from Gemini Segments)
-	if len(analysis.Content.Segments) > 0 {
-		for _, seg := range analysis.Content.Segments {
-			lang, _ := detector.DetectLanguageOf(seg.Content)
-
-			contents = append(contents, &model.PostContent{
-				PostID:   *request.PostId,
-				Type:     model.ContentTranscript,
-				Text:     seg.Content,
-				Language: lang.IsoCode639_1().String(),
-				Metadata: &model.SegmentPostContentMetadata{
-					Timestamp: seg.Timestamp,
-					Speaker:   seg.Speaker,
-					Emotion:   seg.Emotion,
-				},
-			})
-		}
+	// 4. Save all contents and the post analysis to database
+	s.saveContents(contents)
+	s.saveAnalysis(builder.analysis)
+
+	// 5. Index the contents for semantic search
+	s.embedPostContents(*request.PostId)
+
+	// 6. Assign the post to the niches of the category taxonomy
+	s.classifyPost(*request.PostId)
+
+	// 7. Follow the counts of the post to calibrate the viral score against them
+	scheduleStatSnapshots(s.ctx, s.stg, builder.analysis, counts)
+
+	return nil
+}
+
+// maxClassificationTextLength bounds the post text sent for classification.
+const maxClassificationTextLength = 6000
+
+// classifyPost assigns the post to the categories matching its caption, big idea and transcript.
+// New subcategories proposed under an existing main category are stored as suggestions for review.
+func (s *postQueueSvc) classifyPost(postId string) {
+	if s.GptClient == nil {
+		return
 	}
 
-	// 4. Save all contents to database
-	if len(contents) > 0 {
-		if err = s.stg.PostContent(s.ctx).CreateMany(contents); err != nil {
-			logger.Error("Failed to save post contents:", err)
-		}
+	categories, err := s.stg.Category(s.ctx).ListOrdered()
+	if err != nil {
+		logger.Error("Failed to list categories for classification:", err)
+		return
+	}
+	if len(categories) == 0 {
+		return
 	}
 
-	hashtags := make([]string, len(analysis.Publish.Hashtags))
-	for i, hashtag := range analysis.Publish.Hashtags {
-		hashtags[i] = strings.ReplaceAll(hashtag, "#", "")
-	}
-
-	// 5. Save Post Analysis
-	postAnalysis := &model.PostAnalysis{
-		PostId:            *request.PostId,
-		BigIdea:           analysis.Summary.BigIdea,
-		WhyViral:          analysis.Summary.WhyViral,
-		AudienceSentiment: analysis.Summary.AudienceSentiment,
-		SentimentScore:    analysis.Summary.SentimentScore,
-		Verdict:           analysis.Summary.Verdict,
-		Strengths:         analysis.Analysis.Strengths,
-		Weaknesses:        analysis.Analysis.Weaknesses,
-		HookIdeas:         analysis.Remix.HookIdeas,
-		ScriptIdeas:       analysis.Remix.ScriptIdeas,
-		Hashtags:          hashtags,
-		Captions: model.PostAnalysisCaptions{
-			Casual:       analysis.Publish.Captions.Casual,
-			Professional: analysis.Publish.Captions.Professional,
-			Viral:        analysis.Publish.Captions.Viral,
-		},
-	}
-
-	topicScore := 0
-	hookScore := 0
-	pacingScore := 0
-	valueDeliveryScore := 0
-	shareabilityScore := 0
-	ctaScore := 0
-
-	for _, m := range analysis.Analysis.Metrics {
-		postAnalysis.Metrics = append(postAnalysis.Metrics, model.PostAnalysisMetric{
-			Label:       m.Label,
-			Score:       m.Score,
-			Explanation: m.Explanation,
-			Suggestion:  m.Suggestion,
-		})
+	contents, err := s.stg.PostContent(s.ctx).ListByPostId(postId)
+	if err != nil {
+		logger.Error("Failed to list post contents for classification:", err)
+		return
+	}
+	text := classificationText(contents)
+	if text == "" {
+		return
+	}
+
+	suggestions, err := s.GptClient.ClassifyPost(text, lo.Map(categories, func(c *model.Category, _ int) string {
+		return c.Path
+	}))
+	if err != nil {
+		logger.Warnf("Failed to classify post %s: %v", postId, err)
+		return
+	}
+
+	byPath := lo.KeyBy(categories, func(c *model.Category) string {
+		return strings.ToLower(c.Path)
+	})
+	roots := lo.SliceToMap(lo.Filter(categories, func(c *model.Category, _ int) bool {
+		return !c.Suggested
+	}), func(c *model.Category) (string, bool) {
+		return strings.ToLower(c.Root()), true
+	})
 
-		if strings.Contains(strings.ToLower(m.Label), "top") {
-			topicScore = m.Score
+	var categoryIds []uuid.UUID
+	for _, suggestion := range suggestions {
+		category := newCategory(strings.TrimLeft(suggestion, "-*• "))
+		if category == nil {
 			continue
 		}
-		if strings.Contains(strings.ToLower(m.Label), "hook") {
-			hookScore = m.Score
+		if existing, ok := byPath[strings.ToLower(category.Path)]; ok {
+			categoryIds = append(categoryIds, existing.ID)
 			continue
 		}
-		if strings.Contains(strings.ToLower(m.Label), "pacing") {
-			pacingScore = m.Score
+
+		// the model may only extend the taxonomy below one of its main categories
+		if category.Path == category.Root() || !roots[strings.ToLower(category.Root())] {
+			logger.Warnf("Ignoring category %s suggested for post %s", category.Path, postId)
 			continue
 		}
-		if strings.Contains(strings.ToLower(m.Label), "value") {
-			valueDeliveryScore = m.Score
+		category.Suggested = true
+		if err = s.stg.Category(s.ctx).CreateOne(category); err != nil {
+			logger.Errorf("Failed to save suggested category %s: %v", category.Path, err)
 			continue
 		}
-		if strings.Contains(strings.ToLower(m.Label), "share") {
-			shareabilityScore = m.Score
+		byPath[strings.ToLower(category.Path)] = category
+		categoryIds = append(categoryIds, category.ID)
+	}
+
+	if err = s.stg.PostCategory(s.ctx).ReplaceForPost(postId, lo.Uniq(categoryIds)); err != nil {
+		logger.Errorf("Failed to save categories of post %s: %v", postId, err)
+	}
+}
+
+// classificationText joins the big idea, caption and transcript of a post.
+func classificationText(contents []*model.PostContent) string {
+	var bigIdea, caption, transcript []string
+	for _, content := range contents {
+		switch content.Type {
+		case model.ContentBigIdea:
+			bigIdea = append(bigIdea, content.Text)
+		case model.ContentCaption:
+			caption = append(caption, content.Text)
+		case model.ContentTranscript:
+			transcript = append(transcript, content.Text)
+		}
+	}
+
+	text := strings.TrimSpace(strings.Join(lo.Compact([]string{
+		strings.Join(bigIdea, "\n"),
+		strings.Join(caption, "\n"),
+		strings.Join(transcript, " "),
+	}), "\n\n"))
+
+	if runes := []rune(text); len(runes) > maxClassificationTextLength {
+		text = string(runes[:maxClassificationTextLength])
+	}
+	return text
+}
+
+// embedPostContents computes the embeddings of the post contents that don't have one yet.
+func (s *postQueueSvc) embedPostContents(postId string) {
+	if s.GptClient == nil {
+		return
+	}
+
+	contents, err := s.stg.PostContent(s.ctx).ListByPostId(postId)
+	if err != nil {
+		logger.Error("Failed to list post contents for embedding:", err)
+		return
+	}
+
+	for _, content := range contents {
+		if content.Embedding != nil || strings.TrimSpace(content.Text) == "" {
 			continue
 		}
-		if strings.Contains(strings.ToLower(m.Label), "cta") {
-			ctaScore = m.Score
+
+		vector, err := s.GptClient.EmbedText(content.Text)
+		if err != nil {
+			logger.Warnf("Failed to embed content %s of post %s: %v", content.ID, postId, err)
 			continue
 		}
+		if err = s.stg.PostContent(s.ctx).UpdateEmbedding(content.ID, pgvector.NewVector(vector)); err != nil {
+			logger.Errorf("Failed to save embedding of content %s: %v", content.ID, err)
+		}
 	}
+}
 
-	postAnalysis.ViralScore = int(getViralScore(
-		topicScore, hookScore, pacingScore, valueDeliveryScore,
-		shareabilityScore, ctaScore,
-		analysis.Analysis.Scope.Confidence, analysis.Analysis.Scope.Level))
+// saveContents stores the contents that are not saved yet and returns the ones that failed.
+func (s *postQueueSvc) saveContents(contents []*model.PostContent) []*model.PostContent {
+	unsaved := lo.Filter(contents, func(c *model.PostContent, _ int) bool {
+		return c.ID == uuid.Nil
+	})
+	if len(unsaved) == 0 {
+		return nil
+	}
+
+	if err := s.stg.PostContent(s.ctx).CreateMany(unsaved); err != nil {
+		logger.Error("Failed to save post contents:", err)
+		return unsaved
+	}
+	return nil
+}
 
-	if err = s.stg.PostAnalysis(s.ctx).CreateOne(postAnalysis); err != nil {
+func (s *postQueueSvc) saveAnalysis(analysis *model.PostAnalysis) {
+	var err error
+	if analysis.ID == uuid.Nil {
+		err = s.stg.PostAnalysis(s.ctx).CreateOne(analysis)
+	} else {
+		err = s.stg.PostAnalysis(s.ctx).UpsertOne(analysis, false)
+	}
+	if err != nil {
 		logger.Error("Failed to save post analysis:", err)
 	}
+}
 
-	return nil
+// pushAnalysisSection notifies the requesting user that a section of the analysis is ready.
+func (s *postQueueSvc) pushAnalysisSection(request *model.AnalyzeRequest, builder *postAnalysisBuilder, section string) {
+	if request.UserId == nil {
+		return
+	}
+
+	event := &resp.AnalysisSectionEvent{
+		RequestId: request.ID.String(),
+		PostId:    builder.analysis.PostId,
+		Section:   section,
+		Analysis:  builder.analysis,
+	}
+	if section == gemini.SectionContent {
+		event.Segments = lo.Map(builder.transcripts, func(c *model.PostContent, _ int) *resp.PostContentSegmentResponse {
+			return newSegmentResponse(c)
+		})
+	}
+
+	if err := s.wsSvc.Send(*request.UserId, WsEventAnalysisSection, event); err != nil {
+		logger.Debugf("Analysis section %s of request %s not pushed: %v", section, request.ID, err)
+	}
 }
 
 func (s *postQueueSvc) renewInstagramScrap(post *model.Post) (*rocksolid.ReelData, *rocksolid.Reels, error) {
@@ -297,7 +492,8 @@ func (s *postQueueSvc) renewInstagramScrap(post *model.Post) (*rocksolid.ReelDat
 		post.ChannelId = &channel.ID
 	}
 
-	_ = s.stg.Post(s.ctx).UpdateOne(post, false)
+	// new posts are only known by their shortcode until the first scrap
+	_ = s.stg.Post(s.ctx).UpsertOne(post, false)
 
 	if dto.VideoURL == "" {
 		return dto, nil, nil
@@ -339,13 +535,50 @@ func (s *postQueueSvc) renewInstagramScrap(post *model.Post) (*rocksolid.ReelDat
 		AverageVideoViews: avgViews,
 		AverageVideoPlays: avgPlays,
 	})
+	s.saveChannelReels(*post.ChannelId, *otherReelsDto)
 
 	_ = s.stg.Post(s.ctx).UpdateOne(post, false)
 
 	return dto, otherReelsDto, nil
 }
 
-func (s *postQueueSvc) getInstagramVideoAnalysis(dto rocksolid.ReelData, otherReelsDto rocksolid.Reels, detector lingua.LanguageDetector) (string, string, *gemini.AnalysisResponse, error) {
+// saveChannelReels keeps the posting time and latest counts of the scraped reels of the channel.
+func (s *postQueueSvc) saveChannelReels(channelId uuid.UUID, reels rocksolid.Reels) {
+	var channelReels []*model.ChannelReel
+	for _, reel := range reels.Reels {
+		media := reel.Node.Media
+		if media.Code == "" || media.TakenAt == 0 {
+			continue
+		}
+		plays := media.PlayCount
+		if plays == 0 {
+			plays = media.ViewCount
+		}
+		channelReels = append(channelReels, &model.ChannelReel{
+			ChannelId:    channelId,
+			Code:         media.Code,
+			TakenAt:      time.Unix(media.TakenAt, 0),
+			PlayCount:    plays,
+			LikeCount:    media.LikeCount,
+			CommentCount: media.CommentCount,
+		})
+	}
+
+	// a reel pinned to the profile can be listed twice
+	channelReels = lo.UniqBy(channelReels, func(r *model.ChannelReel) string { return r.Code })
+	if err := s.stg.ChannelReel(s.ctx).UpsertMany(channelReels); err != nil {
+		logger.Errorf("Failed to save the reels of channel %s: %v", channelId, err)
+	}
+}
+
+// getInstagramVideoAnalysis analyzes the reel with Gemini.
+// When onSection is set the analysis is streamed and onSection is called for every completed section.
+func (s *postQueueSvc) getInstagramVideoAnalysis(
+	dto rocksolid.ReelData,
+	otherReelsDto rocksolid.Reels,
+	videoPath string,
+	detector lingua.LanguageDetector,
+	onSection gemini.SectionHandler) (string, string, *gemini.Usage, *gemini.AnalysisResponse, error) {
 
 	// 2. We usually analyze the main video (first one) or the longest one.
 	// Instagram carousels might have multiple videos, but for MVP we process the primary one.
@@ -399,68 +632,144 @@ func (s *postQueueSvc) getInstagramVideoAnalysis(dto rocksolid.ReelData, otherRe
 		}
 	}
 
+	// CDN urls are signed and expire, and Gemini cannot always fetch them,
+	// so we hand the video over through the Files API instead.
+	videoFile, err := s.uploadVideoToGemini(videoPath)
+	if err != nil {
+		return "", "", nil, nil, err
+	}
+	defer s.deleteGeminiFile(videoFile)
+
 	logger.Infof("Starting AI analysis for video: %s", targetVideo)
 
-	// 3. Call the Gemini client using the direct URL
+	// 3. Call the Gemini client using the uploaded file
 	// We pass "Instagram" as the platform context
-	llmRequest, llmResponse, result, err := s.GeminiClient.AnalyzeVideo(
-		model.PlatformInstagram,
-		targetVideo,
-		caption,
-		coauthors,
-		comments,
-		videoStats,
-		averageStats,
-		publishedAt,
-		language)
+	var llmRequest, llmResponse string
+	var result *gemini.AnalysisResponse
+	var usage *gemini.Usage
+	if onSection != nil {
+		llmRequest, llmResponse, result, usage, err = s.GeminiClient.AnalyzeVideoStream(
+			model.PlatformInstagram,
+			videoFile.Uri,
+			caption,
+			coauthors,
+			comments,
+			videoStats,
+			averageStats,
+			publishedAt,
+			language,
+			onSection)
+	} else {
+		llmRequest, llmResponse, result, usage, err = s.GeminiClient.AnalyzeVideo(
+			model.PlatformInstagram,
+			videoFile.Uri,
+			caption,
+			coauthors,
+			comments,
+			videoStats,
+			averageStats,
+			publishedAt,
+			language)
+	}
 	if err != nil {
 		logger.Errorf("Failed to analyze video via Gemini: %v", err)
-		return llmRequest, llmResponse, nil, errs.Newf(errs.Internal, err, "failed to analyze video content")
+		return llmRequest, llmResponse, usage, nil, errs.Newf(errs.Internal, err, "failed to analyze video content")
 	}
 
 	logger.Infof("Finished analysis for video: %s", targetVideo)
 
-	return llmRequest, llmResponse, result, nil
+	return llmRequest, llmResponse, usage, result, nil
 }
 
-func getViralScore(topicScore, hookScore, pacingScore, valueDeliveryScore, shareabilityScore, ctaScore, scopeConfidence int, scope string) float64 {
-	t := float64(topicScore)
-	h := float64(hookScore)
-	p := float64(pacingScore)
-	v := float64(valueDeliveryScore)
-	s := float64(shareabilityScore)
-	c := float64(ctaScore)
-
-	gateMultiplier := 1.0
-	if t < 60 || s < 60 {
-		gateMultiplier = 0.6
-	}
-
-	scopeMultiplier := 1.0
-
-	if scopeConfidence >= 70 {
-		switch scope {
-		case "Local":
-			scopeMultiplier = 0.75
-		case "National":
-			scopeMultiplier = 0.9
-		case "Global":
-			scopeMultiplier = 1.0
-		}
+// downloadVideo downloads the video to a local file, the caller removes its directory when done.
+func (s *postQueueSvc) downloadVideo(videoUrl string) (string, error) {
+	paths, err := s.fileSvc.DownloadFiles([]string{videoUrl})
+	if err != nil {
+		return "", errs.Wrapf(err, "failed to download video")
+	}
+	if len(paths) == 0 || paths[0] == "" {
+		return "", errs.Newf(errs.Internal, nil, "failed to download video %s", videoUrl)
+	}
+	return paths[0], nil
+}
+
+// uploadVideoToGemini uploads the downloaded video to the Gemini Files API.
+// It returns once the uploaded file is ready to be used in a prompt.
+func (s *postQueueSvc) uploadVideoToGemini(videoPath string) (*gemini.File, error) {
+	file, err := s.GeminiClient.UploadFile(videoPath, "video/mp4")
+	if err != nil {
+		return nil, errs.Wrapf(err, "failed to upload video to gemini")
 	}
 
-	// Base weighted score
-	score := (h*0.25 + t*0.2 + p*0.15 + v*0.15 + s*0.15 + c*0.1) * gateMultiplier * scopeMultiplier
+	activeFile, err := s.GeminiClient.WaitForFileActive(file.Name, 5*time.Minute)
+	if err != nil {
+		s.deleteGeminiFile(file)
+		return nil, errs.Wrapf(err, "uploaded video is not usable")
+	}
+
+	return activeFile, nil
+}
+
+// maxTranscriptionFileSize is the largest file accepted by the transcription api.
+const maxTranscriptionFileSize = 25 * 1024 * 1024
+
+// transcribeVideo transcribes the audio of the downloaded video with Whisper, it returns nil when not possible.
+func (s *postQueueSvc) transcribeVideo(videoPath string) *gpt.TranscriptionResult {
+	if s.GptClient == nil {
+		return nil
+	}
+
+	info, err := os.Stat(videoPath)
+	if err != nil {
+		logger.Warnf("Failed to read video for transcription: %v", err)
+		return nil
+	}
+	if info.Size() > maxTranscriptionFileSize {
+		logger.Warnf("Video of %d bytes is too large to transcribe", info.Size())
+		return nil
+	}
 
-	if c > 90 && s < 70 {
-		score *= 0.85
+	data, err := os.ReadFile(videoPath)
+	if err != nil {
+		logger.Warnf("Failed to read video for transcription: %v", err)
+		return nil
 	}
 
-	if score > 100 {
-		score = 100
-	} else if score < 0 {
-		score = 0
+	transcription, err := s.GptClient.TranscribeAudio(data, filepath.Base(videoPath))
+	if err != nil {
+		logger.Warnf("Failed to transcribe video: %v", err)
+		return nil
 	}
+	return transcription
+}
 
-	return score
+func (s *postQueueSvc) deleteGeminiFile(file *gemini.File) {
+	if err := s.GeminiClient.DeleteFile(file.Name); err != nil {
+		logger.Warnf("Failed to delete gemini file %s: %v", file.Name, err)
+	}
+}
+
+// newLlmUsage maps the usage reported by Gemini to the model and prices it with the configured price table.
+func newLlmUsage(usage *gemini.Usage, envs *env.Envs) model.LlmUsage {
+	prices := envs.LlmPricing
+
+	cost := float64(usage.PromptTokens)*prices.PromptPerMTok/1e6 +
+		float64(usage.VideoTokens)*prices.VideoPerMTok/1e6 +
+		float64(usage.AudioTokens)*prices.AudioPerMTok/1e6 +
+		float64(usage.OutputTokens)*prices.OutputPerMTok/1e6
+	if usage.SearchQueries > 0 {
+		// grounding is billed per grounded prompt, regardless of the number of queries
+		cost += prices.GroundingPer1K / 1000
+	}
+
+	return model.LlmUsage{
+		LlmModel:      usage.Model,
+		PromptTokens:  usage.PromptTokens,
+		VideoTokens:   usage.VideoTokens,
+		AudioTokens:   usage.AudioTokens,
+		OutputTokens:  usage.OutputTokens,
+		TotalTokens:   usage.TotalTokens,
+		SearchQueries: usage.SearchQueries,
+		LlmCost:       cost,
+	}
 }
@@ -0,0 +1,255 @@
+package svc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/amahdian/cliplab-be/clients/gemini"
+	"github.com/amahdian/cliplab-be/clients/gpt"
+	"github.com/amahdian/cliplab-be/clients/rocksolid"
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/amahdian/cliplab-be/global/env"
+	"github.com/amahdian/cliplab-be/pkg/logger"
+	"github.com/amahdian/cliplab-be/pkg/logger/logging"
+	"github.com/pgvector/pgvector-go"
+	"github.com/samber/lo"
+)
+
+func TestNewLlmUsage(t *testing.T) {
+	envs := &env.Envs{}
+	envs.LlmPricing.PromptPerMTok = 0.30
+	envs.LlmPricing.VideoPerMTok = 0.30
+	envs.LlmPricing.AudioPerMTok = 1.00
+	envs.LlmPricing.OutputPerMTok = 2.50
+	envs.LlmPricing.GroundingPer1K = 35
+
+	usage := &gemini.Usage{
+		Model:        gemini.AnalysisModel,
+		PromptTokens: 1_000_000,
+		VideoTokens:  2_000_000,
+		AudioTokens:  500_000,
+		OutputTokens: 100_000,
+		TotalTokens:  3_600_000,
+	}
+	cases := []struct {
+		name          string
+		searchQueries int
+		cost          float64
+	}{
+		// 0.30 + 0.60 + 0.50 + 0.25
+		{"without grounding", 0, 1.65},
+		// grounding adds 35 / 1000 once, however many queries were issued
+		{"with grounding", 3, 1.685},
+	}
+
+	for _, c := range cases {
+		usage.SearchQueries = c.searchQueries
+		got := newLlmUsage(usage, envs)
+		if math.Abs(got.LlmCost-c.cost) > 1e-9 {
+			t.Errorf("%s: expected a cost of %v, got %v", c.name, c.cost, got.LlmCost)
+		}
+		if got.LlmModel != usage.Model || got.PromptTokens != usage.PromptTokens || got.VideoTokens != usage.VideoTokens ||
+			got.AudioTokens != usage.AudioTokens || got.OutputTokens != usage.OutputTokens ||
+			got.TotalTokens != usage.TotalTokens || got.SearchQueries != c.searchQueries {
+			t.Errorf("%s: unexpected usage %+v", c.name, got)
+		}
+	}
+}
+
+// fakeFileSvc downloads every url to an empty file in its own directory.
+type fakeFileSvc struct {
+	FileSvc
+	dir       string
+	downloads int
+}
+
+func (s *fakeFileSvc) DownloadFiles(urls []string) ([]string, error) {
+	var paths []string
+	for range urls {
+		s.downloads++
+		dir, err := os.MkdirTemp(s.dir, "download")
+		if err != nil {
+			return nil, err
+		}
+		path := filepath.Join(dir, "video.mp4")
+		if err = os.WriteFile(path, nil, 0o600); err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// fakeGeminiClient streams the summary and content sections, then fails when failAfterContent is set.
+type fakeGeminiClient struct {
+	gemini.Client
+	sections         map[string]string
+	failAfterContent bool
+}
+
+func (c *fakeGeminiClient) UploadFile(string, string) (*gemini.File, error) {
+	return &gemini.File{Name: "files/video", Uri: "https://gemini.test/files/video"}, nil
+}
+
+func (c *fakeGeminiClient) WaitForFileActive(name string, _ time.Duration) (*gemini.File, error) {
+	return &gemini.File{Name: name, Uri: "https://gemini.test/" + name, State: gemini.FileStateActive}, nil
+}
+
+func (c *fakeGeminiClient) DeleteFile(string) error { return nil }
+
+func (c *fakeGeminiClient) AnalyzeVideoStream(
+	_ model.SocialPlatform, _, _ string, _, _ []string, _, _ map[string]float64, _ time.Time, _ string,
+	onSection gemini.SectionHandler) (string, string, *gemini.AnalysisResponse, *gemini.Usage, error) {
+	for _, section := range []string{gemini.SectionSummary, gemini.SectionContent} {
+		onSection(section, json.RawMessage(c.sections[section]))
+	}
+	if c.failAfterContent {
+		return "", "", nil, nil, errors.New("stream interrupted")
+	}
+
+	var result gemini.AnalysisResponse
+	raw, _ := json.Marshal(lo.MapValues(c.sections, func(v string, _ string) json.RawMessage { return json.RawMessage(v) }))
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return "", "", nil, nil, err
+	}
+	return "", "", &result, nil, nil
+}
+
+func TestProcessInstagramScrapRetryReplacesStreamedContents(t *testing.T) {
+	logger.Configure(logging.ErrorLevel, logging.TextFormat)
+	stg := newFakeStg()
+	envs := &env.Envs{}
+	envs.Gemini.Streaming = true
+	geminiClient := &fakeGeminiClient{
+		sections: map[string]string{
+			gemini.SectionSummary: `{"big_idea": "cats rule the internet"}`,
+			gemini.SectionContent: `{"segments": [{"timestamp": "00:01", "content": "look at this cat"}, {"timestamp": "00:04", "content": "it is jumping"}]}`,
+		},
+		failAfterContent: true,
+	}
+	fileSvc := &fakeFileSvc{dir: t.TempDir()}
+	s := newPostQueueSvc(context.Background(), stg, envs, geminiClient, nil, nil, fileSvc, nil).(*postQueueSvc)
+
+	request := &model.AnalyzeRequest{PostId: lo.ToPtr("post")}
+	_ = stg.requests.CreateOne(request)
+	reel := &rocksolid.ReelData{
+		VideoURL: "https://cdn.test/video.mp4",
+		EdgeMediaToCaption: rocksolid.EdgeMediaToCaption{
+			Edges: []rocksolid.CaptionEdge{{Node: rocksolid.CaptionNode{Text: "my cat"}}},
+		},
+	}
+
+	if err := s.processInstagramScrap(request, reel, &rocksolid.Reels{}); err == nil {
+		t.Fatalf("expected the interrupted stream to fail")
+	}
+	if contents, _ := stg.contents.ListByPostId("post"); len(contents) != 3 {
+		t.Fatalf("expected the caption and transcript to be saved while streaming, got %d contents", len(contents))
+	}
+
+	geminiClient.failAfterContent = false
+	if err := s.processInstagramScrap(request, reel, &rocksolid.Reels{}); err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	contents, _ := stg.contents.ListByPostId("post")
+	counts := lo.CountValuesBy(contents, func(c *model.PostContent) model.PostContentType { return c.Type })
+	want := map[model.PostContentType]int{model.ContentCaption: 1, model.ContentTranscript: 2, model.ContentBigIdea: 1}
+	if !reflect.DeepEqual(counts, want) {
+		t.Errorf("expected the contents %v after the retry, got %v", want, counts)
+	}
+	if len(stg.analyses.list) != 1 || stg.analyses.list[0].BigIdea != "cats rule the internet" {
+		t.Errorf("expected a single analysis, got %d", len(stg.analyses.list))
+	}
+	if fileSvc.downloads != 2 {
+		t.Errorf("expected the video to be downloaded once per attempt, got %d downloads", fileSvc.downloads)
+	}
+}
+
+// fakeGptClient transcribes every audio to the same text and embeds the texts not listed in failEmbedding.
+type fakeGptClient struct {
+	gpt.Client
+	transcribed   [][]byte
+	embedded      []string
+	failEmbedding map[string]bool
+}
+
+func (c *fakeGptClient) EmbedText(text string) ([]float32, error) {
+	if c.failEmbedding[text] {
+		return nil, errors.New("rate limited")
+	}
+	c.embedded = append(c.embedded, text)
+	return []float32{0.1, 0.2}, nil
+}
+
+func (c *fakeGptClient) TranscribeAudio(data []byte, _ string) (*gpt.TranscriptionResult, error) {
+	c.transcribed = append(c.transcribed, data)
+	return &gpt.TranscriptionResult{Text: "meow", Language: "en"}, nil
+}
+
+func TestTranscribeVideo(t *testing.T) {
+	logger.Configure(logging.ErrorLevel, logging.TextFormat)
+	gptClient := &fakeGptClient{}
+	s := newPostQueueSvc(context.Background(), newFakeStg(), &env.Envs{}, nil, gptClient, nil, nil, nil).(*postQueueSvc)
+
+	small := filepath.Join(t.TempDir(), "small.mp4")
+	if err := os.WriteFile(small, []byte("audio"), 0o600); err != nil {
+		t.Fatalf("write the video: %v", err)
+	}
+	if transcription := s.transcribeVideo(small); transcription == nil || transcription.Text != "meow" {
+		t.Errorf("expected the video to be transcribed, got %+v", transcription)
+	}
+
+	// a sparse file, so the size limit is checked without writing 25 MB
+	large := filepath.Join(t.TempDir(), "large.mp4")
+	if err := os.WriteFile(large, nil, 0o600); err != nil {
+		t.Fatalf("write the video: %v", err)
+	}
+	if err := os.Truncate(large, maxTranscriptionFileSize+1); err != nil {
+		t.Fatalf("grow the video: %v", err)
+	}
+	if transcription := s.transcribeVideo(large); transcription != nil {
+		t.Errorf("expected a video over the size limit to be skipped, got %+v", transcription)
+	}
+	if len(gptClient.transcribed) != 1 || string(gptClient.transcribed[0]) != "audio" {
+		t.Errorf("expected only the small video to be sent, got %d", len(gptClient.transcribed))
+	}
+}
+
+func TestEmbedPostContents(t *testing.T) {
+	logger.Configure(logging.ErrorLevel, logging.TextFormat)
+	stg := newFakeStg()
+	gptClient := &fakeGptClient{failEmbedding: map[string]bool{"flaky": true}}
+	s := newPostQueueSvc(context.Background(), stg, &env.Envs{}, nil, gptClient, nil, nil, nil).(*postQueueSvc)
+
+	embedded := pgvector.NewVector([]float32{0.3, 0.4})
+	_ = stg.contents.CreateMany([]*model.PostContent{
+		{PostID: "post", Type: model.ContentCaption, Text: "caption"},
+		{PostID: "post", Type: model.ContentTranscript, Text: "  "},
+		{PostID: "post", Type: model.ContentBigIdea, Text: "embedded", Embedding: &embedded},
+		{PostID: "post", Type: model.ContentTranscript, Text: "flaky"},
+		{PostID: "other", Type: model.ContentCaption, Text: "other post"},
+	})
+
+	s.embedPostContents("post")
+	if !reflect.DeepEqual(gptClient.embedded, []string{"caption"}) {
+		t.Errorf("expected only the caption to be embedded, got %v", gptClient.embedded)
+	}
+
+	// a failed embedding is retried on the next run, the saved ones are not computed again
+	gptClient.failEmbedding = nil
+	s.embedPostContents("post")
+	if !reflect.DeepEqual(gptClient.embedded, []string{"caption", "flaky"}) {
+		t.Errorf("expected the failed content to be retried, got %v", gptClient.embedded)
+	}
+	contents, _ := stg.contents.ListByPostId("post")
+	missing := lo.Filter(contents, func(c *model.PostContent, _ int) bool { return c.Embedding == nil })
+	if len(missing) != 1 || missing[0].Text != "  " {
+		t.Errorf("expected only the blank content to stay without an embedding, got %d", len(missing))
+	}
+}
@@ -25,8 +25,8 @@ import (
 )
 
 type AnalyzeSvc interface {
-	AddRequestToAnalyzeQueue(url url.URL, user *auth.UserInfo, ip net.IP) (*resp.PostQueueResponse, error)
-	GetAnalyzeResult(id uuid.UUID) (*resp.AnalyzeResult, error)
+	AddRequestToAnalyzeQueue(url url.URL, user *auth.UserInfo, workspaceId *uuid.UUID, ip net.IP) (*resp.PostQueueResponse, error)
+	GetAnalyzeResult(id uuid.UUID, user *auth.UserInfo) (*resp.AnalyzeResult, error)
 }
 
 type analyzeSvc struct {
@@ -53,12 +53,21 @@ func newAnalyzeSvc(
 	}
 }
 
-func (s *analyzeSvc) AddRequestToAnalyzeQueue(url url.URL, user *auth.UserInfo, ip net.IP) (*resp.PostQueueResponse, error) {
+func (s *analyzeSvc) AddRequestToAnalyzeQueue(url url.URL, user *auth.UserInfo, workspaceId *uuid.UUID, ip net.IP) (*resp.PostQueueResponse, error) {
 	platform := detectSocialMediaID(url)
 	if platform != model.PlatformInstagram {
 		return nil, errs.Newf(errs.InvalidArgument, nil, "unsupported platform, we only support Instagram reels for now")
 	}
 
+	if workspaceId != nil {
+		if user.Id == uuid.Nil {
+			return nil, errs.Newf(errs.Unauthenticated, nil, "sign in to analyze into a workspace")
+		}
+		if _, err := requireWorkspaceRole(s.ctx, s.stg, user.Id, *workspaceId, model.WorkspaceRoleEditor); err != nil {
+			return nil, err
+		}
+	}
+
 	estimatedTime := getEstimatedTimeByPlatform(platform)
 
 	shortcode := utils.GetInstagramShortcode(url.String())
@@ -89,7 +98,15 @@ func (s *analyzeSvc) AddRequestToAnalyzeQueue(url url.URL, user *auth.UserInfo,
 	}
 
 	for _, request := range requests {
-		if user.Id != uuid.Nil && request.UserId != nil && *request.UserId == user.Id {
+		if workspaceId != nil {
+			// a post is analyzed once per workspace, whoever of its members asked first
+			if request.WorkspaceId != nil && *request.WorkspaceId == *workspaceId {
+				analyzeRequest = request
+				break
+			}
+			continue
+		}
+		if user.Id != uuid.Nil && request.UserId != nil && *request.UserId == user.Id && request.WorkspaceId == nil {
 			analyzeRequest = request
 			break
 		}
@@ -100,9 +117,12 @@ func (s *analyzeSvc) AddRequestToAnalyzeQueue(url url.URL, user *auth.UserInfo,
 	}
 	if analyzeRequest == nil {
 		analyzeRequest = &model.AnalyzeRequest{
-			UserIP: ip.String(),
-			Link:   url.String(),
-			Status: model.RequestStatusPending,
+			UserIP:   ip.String(),
+			Link:     url.String(),
+			Platform: platform,
+			Status:   model.RequestStatusPending,
+
+			WorkspaceId: workspaceId,
 		}
 		if user.Id != uuid.Nil {
 			analyzeRequest.UserId = &user.Id
@@ -161,11 +181,20 @@ func (s *analyzeSvc) AddRequestToAnalyzeQueue(url url.URL, user *auth.UserInfo,
 	}, nil
 }
 
-func (s *analyzeSvc) GetAnalyzeResult(id uuid.UUID) (*resp.AnalyzeResult, error) {
+// GetAnalyzeResult returns the result of the request, the requests of a workspace are only visible to its members.
+func (s *analyzeSvc) GetAnalyzeResult(id uuid.UUID, user *auth.UserInfo) (*resp.AnalyzeResult, error) {
 	r, err := s.stg.AnalyzeRequest(s.ctx).FindById(id)
 	if err != nil {
 		return nil, errs.Newf(errs.Internal, err, "failed to find post by id")
 	}
+	if r.WorkspaceId != nil {
+		if user.Id == uuid.Nil {
+			return nil, errs.Newf(errs.Unauthenticated, nil, "sign in to see the analyses of a workspace")
+		}
+		if _, err = requireWorkspaceRole(s.ctx, s.stg, user.Id, *r.WorkspaceId, model.WorkspaceRoleViewer); err != nil {
+			return nil, err
+		}
+	}
 
 	request := *r
 	if request.Status == model.RequestStatusFailed {
@@ -173,12 +202,23 @@ func (s *analyzeSvc) GetAnalyzeResult(id uuid.UUID) (*resp.AnalyzeResult, error)
 	}
 
 	if request.Status != model.RequestStatusCompleted {
+		// streamed analyses are persisted section by section, return what is ready so far
+		if request.PostId != nil {
+			if res, err := s.buildAnalyzeResult(&request); err == nil && res.Analysis != nil {
+				return res, nil
+			}
+		}
+
 		return &resp.AnalyzeResult{
 			Status:   request.Status,
 			Platform: model.PlatformInstagram,
 		}, nil
 	}
 
+	return s.buildAnalyzeResult(&request)
+}
+
+func (s *analyzeSvc) buildAnalyzeResult(request *model.AnalyzeRequest) (*resp.AnalyzeResult, error) {
 	p, err := s.stg.Post(s.ctx).FindByHashId(*request.PostId)
 	if err != nil {
 		return nil, errs.Newf(errs.Internal, err, "failed to find post by hash id")
@@ -221,20 +261,13 @@ func (s *analyzeSvc) GetAnalyzeResult(id uuid.UUID) (*resp.AnalyzeResult, error)
 	}
 
 	res.Analysis = analysis
+	res.OutlierStats = analysis.OutlierStats
+	res.OutlierSummary = outlierSummary(analysis.OutlierStats)
 
 	for _, content := range contents {
 		switch content.Type {
 		case model.ContentTranscript:
-			metaData := content.Metadata.(*model.SegmentPostContentMetadata)
-			res.Segments = append(res.Segments, &resp.PostContentSegmentResponse{
-				PostContentResponse: &resp.PostContentResponse{
-					Content:  content.Text,
-					Language: content.Language,
-				},
-				Timestamp: metaData.Timestamp,
-				Emotion:   metaData.Emotion,
-				Speaker:   metaData.Speaker,
-			})
+			res.Segments = append(res.Segments, newSegmentResponse(content))
 		case model.ContentCaption:
 			res.Caption = &resp.PostContentResponse{
 				Content:  content.Text,
@@ -246,6 +279,55 @@ func (s *analyzeSvc) GetAnalyzeResult(id uuid.UUID) (*resp.AnalyzeResult, error)
 	return res, nil
 }
 
+// ensurePostAnalyzedByUser checks the user analyzed the post or can read it in the library of one of their workspaces,
+// other users' posts are reported as not found.
+func ensurePostAnalyzedByUser(ctx context.Context, stg storage.PgStorage, userId uuid.UUID, postId string) error {
+	requests, err := stg.AnalyzeRequest(ctx).ListByPostId(postId)
+	if err != nil {
+		return errs.Newf(errs.Internal, err, "failed to list analyze requests")
+	}
+
+	analyzed := lo.ContainsBy(requests, func(r *model.AnalyzeRequest) bool {
+		return r.UserId != nil && *r.UserId == userId && r.WorkspaceId == nil
+	})
+	if analyzed {
+		return nil
+	}
+
+	workspaceIds := lo.Uniq(lo.FilterMap(requests, func(r *model.AnalyzeRequest, _ int) (uuid.UUID, bool) {
+		return lo.FromPtr(r.WorkspaceId), r.WorkspaceId != nil
+	}))
+	for _, workspaceId := range workspaceIds {
+		_, err = requireWorkspaceRole(ctx, stg, userId, workspaceId, model.WorkspaceRoleViewer)
+		if err == nil {
+			return nil
+		}
+		if !errs.IsCode(err, errs.NotFound) {
+			return err
+		}
+	}
+	return errs.Newf(errs.NotFound, nil, "post %s not found", postId)
+}
+
+func newSegmentResponse(content *model.PostContent) *resp.PostContentSegmentResponse {
+	metaData := content.Metadata.(*model.SegmentPostContentMetadata)
+	// transcripts stored before the source was recorded all come from Gemini
+	source := metaData.Source
+	if source == "" {
+		source = model.TranscriptSourceGemini
+	}
+	return &resp.PostContentSegmentResponse{
+		PostContentResponse: &resp.PostContentResponse{
+			Content:  content.Text,
+			Language: content.Language,
+		},
+		Timestamp: metaData.Timestamp,
+		Emotion:   metaData.Emotion,
+		Speaker:   metaData.Speaker,
+		Source:    source,
+	}
+}
+
 func detectSocialMediaID(url url.URL) model.SocialPlatform {
 	text := strings.TrimSpace(url.String())
 	text = strings.Split(text, "?")[0]
@@ -0,0 +1,187 @@
+package svc
+
+import (
+	"sort"
+	"time"
+
+	"github.com/amahdian/cliplab-be/domain/contracts/resp"
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/google/uuid"
+)
+
+const (
+	// minTimezoneSampleSize is the number of reels needed to infer the timezone of a channel.
+	minTimezoneSampleSize = 10
+	// quietWindowHours is the length of the daily window in which a channel is assumed not to post.
+	quietWindowHours = 6
+	// quietWindowLocalCenter is the local hour assumed to be in the middle of the quiet window.
+	quietWindowLocalCenter = 4
+	// minBestSlotPosts is the number of reels a slot needs to be recommended.
+	minBestSlotPosts = 2
+	bestSlotCount    = 3
+)
+
+// inferUtcOffset guesses the timezone of a channel from the hours its reels were posted.
+// The longest stretch of the day without posts is assumed to be the night of the creator.
+func inferUtcOffset(times []time.Time) (int, bool) {
+	if len(times) < minTimezoneSampleSize {
+		return 0, false
+	}
+
+	var hours [24]int
+	for _, t := range times {
+		hours[t.UTC().Hour()]++
+	}
+
+	var windows [24]int
+	for start := range windows {
+		for i := 0; i < quietWindowHours; i++ {
+			windows[start] += hours[(start+i)%24]
+		}
+	}
+	quietest := windows[0]
+	for _, posts := range windows {
+		quietest = min(quietest, posts)
+	}
+
+	// the longest run of the quietest windows, walking the day twice as the run can wrap around midnight
+	bestStart, bestLength, runStart, runLength := 0, 0, 0, 0
+	for i := 0; i < 48; i++ {
+		if windows[i%24] != quietest {
+			runLength = 0
+			continue
+		}
+		if runLength == 0 {
+			runStart = i
+		}
+		runLength = min(runLength+1, 24)
+		if runLength > bestLength {
+			bestStart, bestLength = runStart, runLength
+		}
+	}
+	if bestLength == 24 {
+		// posts are spread evenly over the day
+		return 0, false
+	}
+
+	center := bestStart + bestLength/2 + quietWindowHours/2
+	offset := quietWindowLocalCenter - center
+	// keep the offset within the real timezones, UTC-11 to UTC+12
+	return ((offset+11)%24+24)%24 - 11, true
+}
+
+// newPostingTimes aggregates the reels of a channel by the local weekday and hour they were posted.
+func newPostingTimes(channelId uuid.UUID, reels []*model.ChannelReel) *resp.PostingTimes {
+	times := make([]time.Time, len(reels))
+	for i, reel := range reels {
+		times[i] = reel.TakenAt
+	}
+	offset, inferred := inferUtcOffset(times)
+	zone := time.FixedZone("", offset*3600)
+
+	var cellReels [7][24][]*model.ChannelReel
+	var weekdayReels [7][]*model.ChannelReel
+	var hourReels [24][]*model.ChannelReel
+	for _, reel := range reels {
+		local := reel.TakenAt.In(zone)
+		weekday, hour := int(local.Weekday()), local.Hour()
+		cellReels[weekday][hour] = append(cellReels[weekday][hour], reel)
+		weekdayReels[weekday] = append(weekdayReels[weekday], reel)
+		hourReels[hour] = append(hourReels[hour], reel)
+	}
+
+	channelMedian := medianPlays(reels)
+	res := &resp.PostingTimes{
+		ChannelId:        channelId,
+		SampleSize:       len(reels),
+		UtcOffset:        offset,
+		TimezoneInferred: inferred,
+		MedianPlays:      channelMedian,
+	}
+	for weekday := range cellReels {
+		for hour := range cellReels[weekday] {
+			res.Cells = append(res.Cells, &resp.PostingTimeCell{
+				Weekday:         weekday,
+				Hour:            hour,
+				PostingTimeStat: newPostingTimeStat(cellReels[weekday][hour], channelMedian),
+			})
+		}
+		res.Weekdays = append(res.Weekdays, &resp.PostingTimeWeekday{
+			Weekday:         weekday,
+			PostingTimeStat: newPostingTimeStat(weekdayReels[weekday], channelMedian),
+		})
+	}
+	for hour := range hourReels {
+		res.Hours = append(res.Hours, &resp.PostingTimeHour{
+			Hour:            hour,
+			PostingTimeStat: newPostingTimeStat(hourReels[hour], channelMedian),
+		})
+	}
+	res.BestSlots = bestPostingSlots(res.Cells)
+
+	return res
+}
+
+func newPostingTimeStat(reels []*model.ChannelReel, channelMedian float64) resp.PostingTimeStat {
+	stat := resp.PostingTimeStat{Posts: len(reels)}
+	if len(reels) == 0 {
+		return stat
+	}
+
+	stat.MedianPlays = medianPlays(reels)
+	if channelMedian > 0 {
+		stat.Lift = round2(stat.MedianPlays / channelMedian)
+	}
+
+	var rates float64
+	var rated int
+	for _, reel := range reels {
+		if reel.PlayCount == 0 {
+			continue
+		}
+		rates += float64(reel.LikeCount+reel.CommentCount) / float64(reel.PlayCount) * 100
+		rated++
+	}
+	if rated > 0 {
+		stat.EngagementRate = round2(rates / float64(rated))
+	}
+	return stat
+}
+
+// bestPostingSlots returns the slots with the highest lift, preferring the ones posted in more than once.
+func bestPostingSlots(cells []*resp.PostingTimeCell) []*resp.PostingTimeCell {
+	var slots []*resp.PostingTimeCell
+	for _, minPosts := range []int{minBestSlotPosts, 1} {
+		for _, cell := range cells {
+			if cell.Posts >= minPosts {
+				slots = append(slots, cell)
+			}
+		}
+		if len(slots) > 0 {
+			break
+		}
+	}
+
+	sort.SliceStable(slots, func(i, j int) bool {
+		if slots[i].Lift != slots[j].Lift {
+			return slots[i].Lift > slots[j].Lift
+		}
+		return slots[i].Posts > slots[j].Posts
+	})
+	if len(slots) > bestSlotCount {
+		slots = slots[:bestSlotCount]
+	}
+	return slots
+}
+
+func medianPlays(reels []*model.ChannelReel) float64 {
+	if len(reels) == 0 {
+		return 0
+	}
+	plays := make([]float64, len(reels))
+	for i, reel := range reels {
+		plays[i] = float64(reel.PlayCount)
+	}
+	sort.Float64s(plays)
+	return sortedMedian(plays)
+}
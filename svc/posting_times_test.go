@@ -0,0 +1,53 @@
+package svc
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/google/uuid"
+)
+
+func TestNewPostingTimes(t *testing.T) {
+	// a creator in UTC+3 posting every hour from 8:00 to 22:00 local time
+	zone := time.FixedZone("", 3*3600)
+	var reels []*model.ChannelReel
+	for hour := 8; hour <= 22; hour++ {
+		plays := int64(1000)
+		if hour == 19 {
+			plays = 5000
+		}
+		reels = append(reels, &model.ChannelReel{
+			Code:      strconv.Itoa(hour),
+			TakenAt:   time.Date(2025, 3, 3, hour, 30, 0, 0, zone), // a Monday
+			PlayCount: plays,
+			LikeCount: plays / 10,
+		})
+	}
+
+	times := newPostingTimes(uuid.New(), reels)
+	if !times.TimezoneInferred || times.UtcOffset != 3 {
+		t.Fatalf("unexpected timezone: %d (inferred: %v)", times.UtcOffset, times.TimezoneInferred)
+	}
+	if len(times.Cells) != 7*24 || len(times.Weekdays) != 7 || len(times.Hours) != 24 {
+		t.Fatalf("unexpected heatmap size: %d cells", len(times.Cells))
+	}
+
+	cell := times.Cells[1*24+19]
+	if cell.Weekday != 1 || cell.Hour != 19 || cell.Posts != 1 || cell.Lift != 5 || cell.EngagementRate != 10 {
+		t.Errorf("unexpected cell: %+v", cell)
+	}
+	if times.Weekdays[1].Posts != 15 || times.Weekdays[0].Posts != 0 {
+		t.Errorf("unexpected weekdays: %+v, %+v", times.Weekdays[0], times.Weekdays[1])
+	}
+	if len(times.BestSlots) != 3 || times.BestSlots[0].Hour != 19 {
+		t.Errorf("unexpected best slots: %+v", times.BestSlots[0])
+	}
+}
+
+func TestInferUtcOffsetNeedsEnoughReels(t *testing.T) {
+	if _, inferred := inferUtcOffset([]time.Time{time.Now()}); inferred {
+		t.Error("inferred a timezone from a single reel")
+	}
+}
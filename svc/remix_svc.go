@@ -0,0 +1,235 @@
+package svc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/amahdian/cliplab-be/clients/gpt"
+	"github.com/amahdian/cliplab-be/domain/contracts/req"
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/amahdian/cliplab-be/global/env"
+	"github.com/amahdian/cliplab-be/global/errs"
+	"github.com/amahdian/cliplab-be/storage"
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+)
+
+const (
+	defaultRemixCount = 3
+	// maxRemixTranscriptLength bounds the transcript sent along with a remix request.
+	maxRemixTranscriptLength = 4000
+	// maxRemixAvoided is the number of earlier variants the model is asked not to repeat.
+	maxRemixAvoided = 20
+)
+
+var remixInstructions = map[model.RemixSection]string{
+	model.RemixSectionHooks:    "opening hooks, the first line spoken or shown in the first 3 seconds of a new video built on the same idea",
+	model.RemixSectionScripts:  "complete short-form video scripts built on the same idea, with a hook, the body and a call to action, marking each beat with a timestamp like [00:05]",
+	model.RemixSectionCaptions: "post captions for a new video built on the same idea",
+	model.RemixSectionHashtags: "hashtag sets of 5 to 10 hashtags, each variant is a single line of space separated hashtags starting with #",
+}
+
+var remixLengths = map[string]string{
+	"short":  "Keep every variant short: hooks under 8 words, scripts around 15 seconds, captions a single sentence.",
+	"medium": "Use a medium length: hooks under 15 words, scripts around 30 seconds, captions two or three sentences.",
+	"long":   "Go long: scripts around 60 seconds and captions up to a short paragraph.",
+}
+
+type RemixSvc interface {
+	// Remix generates new variants of a remix section of the post without analyzing the video again.
+	Remix(userId uuid.UUID, postId string, data *req.RemixPost) ([]*model.RemixVariant, error)
+	ListVariants(userId uuid.UUID, postId string, section model.RemixSection, favoritesOnly bool) ([]*model.RemixVariant, error)
+	SetFavorite(userId uuid.UUID, id uuid.UUID, favorite bool) (*model.RemixVariant, error)
+}
+
+type remixSvc struct {
+	ctx  context.Context
+	stg  storage.PgStorage
+	envs *env.Envs
+
+	GptClient gpt.Client
+}
+
+func newRemixSvc(ctx context.Context, stg storage.PgStorage, envs *env.Envs, gptClient gpt.Client) RemixSvc {
+	return &remixSvc{
+		ctx:       ctx,
+		stg:       stg,
+		envs:      envs,
+		GptClient: gptClient,
+	}
+}
+
+func (s *remixSvc) Remix(userId uuid.UUID, postId string, data *req.RemixPost) ([]*model.RemixVariant, error) {
+	if s.GptClient == nil {
+		return nil, errs.Newf(errs.Unavailable, nil, "remix is not configured")
+	}
+	if err := ensurePostAnalyzedByUser(s.ctx, s.stg, userId, postId); err != nil {
+		return nil, err
+	}
+
+	analysis, err := s.stg.PostAnalysis(s.ctx).FindByPostId(postId)
+	if err != nil {
+		return nil, errs.Newf(errs.FailedPrecondition, err, "post %s is not analyzed yet", postId)
+	}
+	contents, err := s.stg.PostContent(s.ctx).ListByPostId(postId)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to list post contents")
+	}
+	previous, err := s.stg.RemixVariant(s.ctx).ListByUserAndPost(userId, postId, data.Section, false)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to list remix variants")
+	}
+
+	count := data.Count
+	if count <= 0 {
+		count = defaultRemixCount
+	}
+	language := data.Language
+	if language == "" {
+		language = "en"
+		if caption, ok := lo.Find(contents, func(c *model.PostContent) bool { return c.Type == model.ContentCaption }); ok && caption.Language != "" {
+			language = strings.ToLower(caption.Language)
+		}
+	}
+
+	content, err := s.GptClient.ChatCompletion(gpt.ChatRequest{
+		Model: gpt.ChatModel,
+		Messages: []gpt.ChatMessage{
+			{Role: "system", Content: remixSystemPrompt(data.Section, data.Tone, data.TargetLength, language, count)},
+			{Role: "user", Content: remixUserPrompt(data.Section, analysis, contents, previous)},
+		},
+		ResponseFormat: &gpt.ResponseFormat{Type: "json_object"},
+	})
+	if err != nil {
+		return nil, errs.Newf(errs.Unavailable, err, "failed to generate remix")
+	}
+
+	var result struct {
+		Variants []string `json:"variants"`
+	}
+	if err = json.Unmarshal([]byte(content), &result); err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to parse remix")
+	}
+
+	batchId := uuid.New()
+	var variants []*model.RemixVariant
+	for _, text := range lo.Uniq(result.Variants) {
+		if text = strings.TrimSpace(text); text == "" {
+			continue
+		}
+		variants = append(variants, &model.RemixVariant{
+			UserId:       userId,
+			PostId:       postId,
+			BatchId:      batchId,
+			Section:      data.Section,
+			Tone:         data.Tone,
+			TargetLength: data.TargetLength,
+			Language:     language,
+			Content:      text,
+		})
+		if len(variants) == count {
+			break
+		}
+	}
+	if len(variants) == 0 {
+		return nil, errs.Newf(errs.Internal, nil, "no remix variant was generated")
+	}
+
+	if err = s.stg.RemixVariant(s.ctx).CreateMany(variants); err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to save remix variants")
+	}
+	return variants, nil
+}
+
+func (s *remixSvc) ListVariants(userId uuid.UUID, postId string, section model.RemixSection, favoritesOnly bool) ([]*model.RemixVariant, error) {
+	variants, err := s.stg.RemixVariant(s.ctx).ListByUserAndPost(userId, postId, section, favoritesOnly)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to list remix variants")
+	}
+	return variants, nil
+}
+
+func (s *remixSvc) SetFavorite(userId uuid.UUID, id uuid.UUID, favorite bool) (*model.RemixVariant, error) {
+	variant, err := s.stg.RemixVariant(s.ctx).FindById(id)
+	if err != nil {
+		return nil, err
+	}
+	if variant.UserId != userId {
+		return nil, errs.Newf(errs.NotFound, nil, "remix variant %s not found", id)
+	}
+
+	variant.Favorite = favorite
+	if err = s.stg.RemixVariant(s.ctx).UpdateOne(variant, true); err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to update remix variant")
+	}
+	return variant, nil
+}
+
+func remixSystemPrompt(section model.RemixSection, tone, length, language string, count int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "You are a short-form video copywriter. Write %d distinct %s.\n", count, remixInstructions[section])
+	if tone != "" {
+		fmt.Fprintf(&b, "Use a %s tone.\n", tone)
+	}
+	if hint, ok := remixLengths[length]; ok {
+		b.WriteString(hint + "\n")
+	}
+	fmt.Fprintf(&b, "Write in the language with ISO code %q.\n", language)
+	b.WriteString(`Do not repeat or lightly reword the existing variants you are given.
+Return a json object of the form {"variants": ["..."]}.`)
+	return b.String()
+}
+
+func remixUserPrompt(section model.RemixSection, analysis *model.PostAnalysis, contents []*model.PostContent, previous []*model.RemixVariant) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Big idea: %s\n", analysis.BigIdea)
+	fmt.Fprintf(&b, "Why it worked: %s\n", analysis.WhyViral)
+	if len(analysis.Strengths) > 0 {
+		fmt.Fprintf(&b, "Strengths: %s\n", strings.Join(analysis.Strengths, "; "))
+	}
+
+	var transcript []string
+	for _, content := range contents {
+		switch content.Type {
+		case model.ContentCaption:
+			fmt.Fprintf(&b, "Original caption: %s\n", content.Text)
+		case model.ContentTranscript:
+			transcript = append(transcript, content.Text)
+		}
+	}
+	if len(transcript) > 0 {
+		text := []rune(strings.Join(transcript, " "))
+		if len(text) > maxRemixTranscriptLength {
+			text = text[:maxRemixTranscriptLength]
+		}
+		fmt.Fprintf(&b, "Original transcript: %s\n", string(text))
+	}
+
+	var existing []string
+	switch section {
+	case model.RemixSectionHooks:
+		existing = analysis.HookIdeas
+	case model.RemixSectionScripts:
+		existing = analysis.ScriptIdeas
+	case model.RemixSectionCaptions:
+		existing = []string{analysis.Captions.Casual, analysis.Captions.Professional, analysis.Captions.Viral}
+	case model.RemixSectionHashtags:
+		if len(analysis.Hashtags) > 0 {
+			existing = []string{"#" + strings.Join(analysis.Hashtags, " #")}
+		}
+	}
+	for _, variant := range previous {
+		existing = append(existing, variant.Content)
+	}
+	existing = lo.Compact(existing)
+	if len(existing) > maxRemixAvoided {
+		existing = existing[:maxRemixAvoided]
+	}
+	if len(existing) > 0 {
+		b.WriteString("\nExisting variants:\n- " + strings.Join(existing, "\n- "))
+	}
+
+	return b.String()
+}
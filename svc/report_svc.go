@@ -0,0 +1,75 @@
+package svc
+
+import (
+	"context"
+	"time"
+
+	"github.com/amahdian/cliplab-be/domain/contracts/resp"
+	"github.com/amahdian/cliplab-be/global/env"
+	"github.com/amahdian/cliplab-be/global/errs"
+	"github.com/amahdian/cliplab-be/storage"
+)
+
+type ReportSvc interface {
+	GetLlmCostReport(from, to time.Time) (*resp.LlmCostReport, error)
+	GetCalibrationReport() (*resp.CalibrationReport, error)
+}
+
+type reportSvc struct {
+	ctx  context.Context
+	stg  storage.PgStorage
+	envs *env.Envs
+}
+
+func newReportSvc(ctx context.Context, stg storage.PgStorage, envs *env.Envs) ReportSvc {
+	return &reportSvc{
+		ctx:  ctx,
+		stg:  stg,
+		envs: envs,
+	}
+}
+
+func (s *reportSvc) GetLlmCostReport(from, to time.Time) (*resp.LlmCostReport, error) {
+	if !from.Before(to) {
+		return nil, errs.Newf(errs.InvalidArgument, nil, "'from' must be before 'to'")
+	}
+
+	rows, err := s.stg.AnalyzeRequest(s.ctx).SummarizeLlmCosts(from, to)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to summarize llm costs")
+	}
+
+	report := &resp.LlmCostReport{
+		From: from,
+		To:   to,
+		Rows: rows,
+	}
+	for _, row := range rows {
+		report.Requests += row.Requests
+		report.TotalCost += row.Cost
+	}
+
+	return report, nil
+}
+
+func (s *reportSvc) GetCalibrationReport() (*resp.CalibrationReport, error) {
+	bucketSize := s.envs.Calibration.BucketSize
+	if bucketSize <= 0 || bucketSize > 100 {
+		return nil, errs.Newf(errs.FailedPrecondition, nil, "calibration bucket size must be between 1 and 100")
+	}
+
+	buckets, err := s.stg.PostStatSnapshot(s.ctx).SummarizeCalibration(bucketSize)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to summarize calibration")
+	}
+	correlations, err := s.stg.PostStatSnapshot(s.ctx).CorrelateCalibration()
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to correlate calibration")
+	}
+
+	return &resp.CalibrationReport{
+		BucketSize:   bucketSize,
+		Correlations: correlations,
+		Buckets:      buckets,
+	}, nil
+}
@@ -0,0 +1,155 @@
+// Package scoring computes the viral score of an analysis from the scores of its metrics.
+package scoring
+
+import (
+	"math"
+
+	"github.com/amahdian/cliplab-be/domain/model"
+)
+
+// Input holds the metric scores of an analysis (0-100) and the reach of its topic.
+type Input struct {
+	Topic        int
+	Hook         int
+	Pacing       int
+	Value        int
+	Shareability int
+	Cta          int
+
+	ScopeLevel      string
+	ScopeConfidence int
+}
+
+// Scorer turns the metric scores of an analysis into a viral score and explains how it was computed.
+type Scorer interface {
+	Score(in Input) *model.ViralScoreBreakdown
+}
+
+// Config holds the weights, gates and penalties of the weighted scorer.
+type Config struct {
+	// Version identifies the formula, it is stored with every breakdown.
+	Version string
+
+	TopicWeight        float64
+	HookWeight         float64
+	PacingWeight       float64
+	ValueWeight        float64
+	ShareabilityWeight float64
+	CtaWeight          float64
+
+	// the whole score is multiplied by GateMultiplier when topic or shareability is below GateThreshold
+	GateThreshold  int
+	GateMultiplier float64
+
+	// the scope multipliers only apply when the model is at least ScopeMinConfidence sure of the scope
+	ScopeMinConfidence int
+	LocalMultiplier    float64
+	NationalMultiplier float64
+	GlobalMultiplier   float64
+
+	// a CTA above CtaPenaltyCtaAbove on a post with shareability below CtaPenaltyShareBelow is penalized
+	CtaPenaltyCtaAbove   int
+	CtaPenaltyShareBelow int
+	CtaPenaltyMultiplier float64
+}
+
+// DefaultConfig is the formula the viral score was originally computed with.
+func DefaultConfig() Config {
+	return Config{
+		Version:              "v1",
+		TopicWeight:          0.2,
+		HookWeight:           0.25,
+		PacingWeight:         0.15,
+		ValueWeight:          0.15,
+		ShareabilityWeight:   0.15,
+		CtaWeight:            0.1,
+		GateThreshold:        60,
+		GateMultiplier:       0.6,
+		ScopeMinConfidence:   70,
+		LocalMultiplier:      0.75,
+		NationalMultiplier:   0.9,
+		GlobalMultiplier:     1.0,
+		CtaPenaltyCtaAbove:   90,
+		CtaPenaltyShareBelow: 70,
+		CtaPenaltyMultiplier: 0.85,
+	}
+}
+
+type weightedScorer struct {
+	cfg Config
+}
+
+// NewWeightedScorer returns a scorer computing a weighted sum of the metrics, adjusted by gates and penalties.
+func NewWeightedScorer(cfg Config) Scorer {
+	return &weightedScorer{cfg: cfg}
+}
+
+func (s *weightedScorer) Score(in Input) *model.ViralScoreBreakdown {
+	cfg := s.cfg
+	b := &model.ViralScoreBreakdown{Version: cfg.Version}
+
+	for _, c := range []struct {
+		key    model.MetricKey
+		score  int
+		weight float64
+	}{
+		{model.MetricTopic, in.Topic, cfg.TopicWeight},
+		{model.MetricHook, in.Hook, cfg.HookWeight},
+		{model.MetricPacing, in.Pacing, cfg.PacingWeight},
+		{model.MetricValue, in.Value, cfg.ValueWeight},
+		{model.MetricShareability, in.Shareability, cfg.ShareabilityWeight},
+		{model.MetricCta, in.Cta, cfg.CtaWeight},
+	} {
+		contribution := float64(c.score) * c.weight
+		b.Components = append(b.Components, model.ViralScoreComponent{
+			Key:          c.key,
+			Score:        c.score,
+			Weight:       c.weight,
+			Contribution: round(contribution),
+		})
+		b.BaseScore += contribution
+	}
+
+	score := b.BaseScore
+	if in.Topic < cfg.GateThreshold || in.Shareability < cfg.GateThreshold {
+		b.Adjustments = append(b.Adjustments, model.ViralScoreAdjustment{
+			Name:       model.AdjustmentGate,
+			Multiplier: cfg.GateMultiplier,
+			Reason:     "topic or shareability is below the gate threshold",
+		})
+		score *= cfg.GateMultiplier
+	}
+
+	if in.ScopeConfidence >= cfg.ScopeMinConfidence {
+		multiplier, ok := map[string]float64{
+			"Local":    cfg.LocalMultiplier,
+			"National": cfg.NationalMultiplier,
+			"Global":   cfg.GlobalMultiplier,
+		}[in.ScopeLevel]
+		if ok && multiplier != 1 {
+			b.Adjustments = append(b.Adjustments, model.ViralScoreAdjustment{
+				Name:       model.AdjustmentScope,
+				Multiplier: multiplier,
+				Reason:     in.ScopeLevel + " scope",
+			})
+			score *= multiplier
+		}
+	}
+
+	if in.Cta > cfg.CtaPenaltyCtaAbove && in.Shareability < cfg.CtaPenaltyShareBelow {
+		b.Adjustments = append(b.Adjustments, model.ViralScoreAdjustment{
+			Name:       model.AdjustmentCtaPenalty,
+			Multiplier: cfg.CtaPenaltyMultiplier,
+			Reason:     "a strong call to action on a post that is not shareable",
+		})
+		score *= cfg.CtaPenaltyMultiplier
+	}
+
+	b.BaseScore = round(b.BaseScore)
+	b.Score = math.Max(0, math.Min(100, score))
+	return b
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
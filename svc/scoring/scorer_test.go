@@ -0,0 +1,80 @@
+package scoring
+
+import (
+	"math"
+	"testing"
+
+	"github.com/amahdian/cliplab-be/domain/model"
+)
+
+func TestWeightedScorer(t *testing.T) {
+	scorer := NewWeightedScorer(DefaultConfig())
+
+	tests := []struct {
+		name        string
+		in          Input
+		base        float64
+		score       float64
+		adjustments []string
+	}{
+		{
+			name:  "no adjustment",
+			in:    Input{Topic: 80, Hook: 80, Pacing: 80, Value: 80, Shareability: 80, Cta: 80, ScopeLevel: "Global", ScopeConfidence: 90},
+			base:  80,
+			score: 80,
+		},
+		{
+			name:        "weak topic closes the gate",
+			in:          Input{Topic: 50, Hook: 80, Pacing: 80, Value: 80, Shareability: 80, Cta: 80},
+			base:        74,
+			score:       44.4,
+			adjustments: []string{model.AdjustmentGate},
+		},
+		{
+			name:        "local scope and cta penalty",
+			in:          Input{Topic: 80, Hook: 80, Pacing: 80, Value: 80, Shareability: 65, Cta: 95, ScopeLevel: "Local", ScopeConfidence: 80},
+			base:        79.25,
+			score:       50.521875,
+			adjustments: []string{model.AdjustmentScope, model.AdjustmentCtaPenalty},
+		},
+		{
+			name:  "unsure scope is ignored",
+			in:    Input{Topic: 80, Hook: 80, Pacing: 80, Value: 80, Shareability: 80, Cta: 80, ScopeLevel: "Local", ScopeConfidence: 50},
+			base:  80,
+			score: 80,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := scorer.Score(tt.in)
+			if math.Abs(b.BaseScore-tt.base) > 0.01 {
+				t.Errorf("unexpected base score: got %v, want %v", b.BaseScore, tt.base)
+			}
+			if math.Abs(b.Score-tt.score) > 0.0001 {
+				t.Errorf("unexpected score: got %v, want %v", b.Score, tt.score)
+			}
+
+			var fired []string
+			for _, a := range b.Adjustments {
+				fired = append(fired, a.Name)
+			}
+			if len(fired) != len(tt.adjustments) {
+				t.Fatalf("unexpected adjustments: got %v, want %v", fired, tt.adjustments)
+			}
+			for i := range fired {
+				if fired[i] != tt.adjustments[i] {
+					t.Errorf("unexpected adjustments: got %v, want %v", fired, tt.adjustments)
+				}
+			}
+
+			var sum float64
+			for _, c := range b.Components {
+				sum += c.Contribution
+			}
+			if math.Abs(sum-b.BaseScore) > 0.05 {
+				t.Errorf("contributions %v do not add up to the base score %v", sum, b.BaseScore)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package svc
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/amahdian/cliplab-be/global/env"
+	"github.com/amahdian/cliplab-be/global/errs"
+	"github.com/amahdian/cliplab-be/pkg/logger"
+	"github.com/amahdian/cliplab-be/storage"
+	"github.com/amahdian/cliplab-be/svc/scoring"
+	"github.com/google/uuid"
+)
+
+const rescoreBatchSize = 200
+
+// RescoreResult summarizes a rescore run.
+type RescoreResult struct {
+	Scanned int
+	Changed int
+	Failed  int
+}
+
+type ScoringSvc interface {
+	// Rescore recomputes the viral score of every stored analysis with the configured formula.
+	// With dryRun the new scores are computed and counted but not saved.
+	Rescore(dryRun bool) (*RescoreResult, error)
+}
+
+type scoringSvc struct {
+	ctx    context.Context
+	stg    storage.PgStorage
+	envs   *env.Envs
+	scorer scoring.Scorer
+}
+
+func newScoringSvc(ctx context.Context, stg storage.PgStorage, envs *env.Envs) ScoringSvc {
+	return &scoringSvc{
+		ctx:    ctx,
+		stg:    stg,
+		envs:   envs,
+		scorer: newScorer(envs),
+	}
+}
+
+// newScorer builds the scorer configured by the SCORING_* variables.
+func newScorer(envs *env.Envs) scoring.Scorer {
+	cfg := envs.Scoring
+	return scoring.NewWeightedScorer(scoring.Config{
+		Version:              cfg.Version,
+		TopicWeight:          cfg.TopicWeight,
+		HookWeight:           cfg.HookWeight,
+		PacingWeight:         cfg.PacingWeight,
+		ValueWeight:          cfg.ValueWeight,
+		ShareabilityWeight:   cfg.ShareabilityWeight,
+		CtaWeight:            cfg.CtaWeight,
+		GateThreshold:        cfg.GateThreshold,
+		GateMultiplier:       cfg.GateMultiplier,
+		ScopeMinConfidence:   cfg.ScopeMinConfidence,
+		LocalMultiplier:      cfg.LocalMultiplier,
+		NationalMultiplier:   cfg.NationalMultiplier,
+		GlobalMultiplier:     cfg.GlobalMultiplier,
+		CtaPenaltyCtaAbove:   cfg.CtaPenaltyCtaAbove,
+		CtaPenaltyShareBelow: cfg.CtaPenaltyShareBelow,
+		CtaPenaltyMultiplier: cfg.CtaPenaltyMultiplier,
+	})
+}
+
+func (s *scoringSvc) Rescore(dryRun bool) (*RescoreResult, error) {
+	result := &RescoreResult{}
+
+	lastId := uuid.Nil
+	for {
+		analyses, err := s.stg.PostAnalysis(s.ctx).ListAfterId(lastId, rescoreBatchSize)
+		if err != nil {
+			return result, errs.Newf(errs.Internal, err, "failed to list post analyses")
+		}
+		if len(analyses) == 0 {
+			return result, nil
+		}
+		lastId = analyses[len(analyses)-1].ID
+
+		for _, analysis := range analyses {
+			result.Scanned++
+			if analysis.Scope.Level == "" {
+				analysis.Scope = s.findStoredScope(analysis.PostId)
+			}
+			for i, m := range analysis.Metrics {
+				if key, ok := model.ResolveMetricKey(string(m.Key), m.Label); ok {
+					analysis.Metrics[i].Key = key
+				}
+			}
+
+			previous := analysis.ViralScore
+			scoreAnalysis(s.scorer, analysis)
+			if analysis.ViralScore != previous {
+				result.Changed++
+				logger.Debugf("Viral score of post %s changed from %d to %d", analysis.PostId, previous, analysis.ViralScore)
+			}
+			if dryRun {
+				continue
+			}
+
+			if err = s.stg.PostAnalysis(s.ctx).UpdateOne(analysis, true); err != nil {
+				result.Failed++
+				logger.Errorf("Failed to save rescored analysis of post %s: %v", analysis.PostId, err)
+			}
+		}
+	}
+}
+
+// findStoredScope reads the scope from the raw model response of the latest analyze request,
+// since it was not stored on analyses created before the score breakdown.
+func (s *scoringSvc) findStoredScope(postId string) model.PostAnalysisScope {
+	requests, err := s.stg.AnalyzeRequest(s.ctx).ListByPostId(postId)
+	if err != nil {
+		return model.PostAnalysisScope{}
+	}
+
+	for _, request := range requests {
+		if request.LlmResponse == "" {
+			continue
+		}
+		var response struct {
+			Analysis struct {
+				Scope model.PostAnalysisScope `json:"scope"`
+			} `json:"analysis"`
+		}
+		if err = json.Unmarshal([]byte(request.LlmResponse), &response); err == nil && response.Analysis.Scope.Level != "" {
+			return response.Analysis.Scope
+		}
+	}
+	return model.PostAnalysisScope{}
+}
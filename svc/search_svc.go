@@ -0,0 +1,234 @@
+package svc
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/amahdian/cliplab-be/clients/gpt"
+	"github.com/amahdian/cliplab-be/domain/contracts/resp"
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/amahdian/cliplab-be/global/env"
+	"github.com/amahdian/cliplab-be/global/errs"
+	"github.com/amahdian/cliplab-be/storage"
+	"github.com/google/uuid"
+	"github.com/pgvector/pgvector-go"
+	"github.com/samber/lo"
+)
+
+const defaultSearchLimit = 20
+
+const (
+	// similarityWeight is the share of the similar posts score given to the similarity of the ideas,
+	// the rest rewards posts that outperformed their channel.
+	similarityWeight = 0.7
+	// maxEngagementLift caps the engagement lift, so a single breakout post does not dominate the ranking.
+	maxEngagementLift = 10.0
+)
+
+type SearchSvc interface {
+	SearchPosts(userId uuid.UUID, workspaceId *uuid.UUID, query string, limit int) ([]*resp.SearchResult, error)
+	GetSimilarPosts(userId uuid.UUID, workspaceId *uuid.UUID, postId string, scope model.SimilarPostsScope, limit int) ([]*resp.SimilarPost, error)
+}
+
+type searchSvc struct {
+	ctx  context.Context
+	stg  storage.PgStorage
+	envs *env.Envs
+
+	GptClient gpt.Client
+}
+
+func newSearchSvc(ctx context.Context, stg storage.PgStorage, envs *env.Envs, gptClient gpt.Client) SearchSvc {
+	return &searchSvc{
+		ctx:       ctx,
+		stg:       stg,
+		envs:      envs,
+		GptClient: gptClient,
+	}
+}
+
+// SearchPosts ranks the posts of the library of the user, or of the workspace when one is given,
+// by the cosine similarity of their contents to the query.
+func (s *searchSvc) SearchPosts(userId uuid.UUID, workspaceId *uuid.UUID, query string, limit int) ([]*resp.SearchResult, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, errs.Newf(errs.InvalidArgument, nil, "search query is empty")
+	}
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	if s.GptClient == nil {
+		return nil, errs.Newf(errs.Unavailable, nil, "semantic search is not configured")
+	}
+	owner, err := resolveLibraryOwner(s.ctx, s.stg, userId, workspaceId, model.WorkspaceRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	vector, err := s.GptClient.EmbedText(query)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to embed search query")
+	}
+
+	matches, err := s.stg.PostContent(s.ctx).SearchByEmbedding(owner, pgvector.NewVector(vector), limit)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to search posts")
+	}
+	if len(matches) == 0 {
+		return []*resp.SearchResult{}, nil
+	}
+
+	contents, err := s.stg.PostContent(s.ctx).ListByIds(lo.Map(matches, func(m *model.PostContentMatch, _ int) uuid.UUID {
+		return m.ContentId
+	}))
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to list matched contents")
+	}
+	posts, err := s.stg.Post(s.ctx).ListByHashIds(lo.Map(matches, func(m *model.PostContentMatch, _ int) string {
+		return m.PostId
+	}))
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to list matched posts")
+	}
+
+	contentById := lo.KeyBy(contents, func(c *model.PostContent) uuid.UUID { return c.ID })
+	postById := lo.KeyBy(posts, func(p *model.Post) string { return p.ID })
+
+	results := make([]*resp.SearchResult, 0, len(matches))
+	for _, m := range matches {
+		post, ok := postById[m.PostId]
+		if !ok {
+			continue
+		}
+
+		result := &resp.SearchResult{
+			PostId:      post.ID,
+			Link:        post.Link,
+			ImageUrl:    post.ImageURL,
+			UserHandler: post.UserAnchor,
+			PostDate:    post.PostDate,
+			Similarity:  m.Similarity,
+		}
+		if content, ok := contentById[m.ContentId]; ok {
+			result.Match = &resp.SearchMatch{
+				Type:     content.Type,
+				Content:  content.Text,
+				Language: content.Language,
+			}
+			if meta, ok := content.Metadata.(*model.SegmentPostContentMetadata); ok {
+				result.Match.Timestamp = meta.Timestamp
+			}
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// GetSimilarPosts returns the analyzed posts sharing the idea of the given post,
+// ranked by a mix of idea similarity and engagement relative to their channel.
+func (s *searchSvc) GetSimilarPosts(userId uuid.UUID, workspaceId *uuid.UUID, postId string, scope model.SimilarPostsScope, limit int) ([]*resp.SimilarPost, error) {
+	if scope == "" {
+		scope = model.SimilarScopeAll
+	}
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+
+	if _, err := s.stg.Post(s.ctx).FindByHashId(postId); err != nil {
+		return nil, errs.Newf(errs.NotFound, err, "post %s not found", postId)
+	}
+	owner, err := resolveLibraryOwner(s.ctx, s.stg, userId, workspaceId, model.WorkspaceRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+	// the idea of the post is read from its content, so the user must be able to see the post itself
+	if err = ensurePostAnalyzedByUser(s.ctx, s.stg, userId, postId); err != nil {
+		return nil, err
+	}
+
+	matches, err := s.stg.PostContent(s.ctx).SearchSimilarPosts(postId, scope, owner, limit)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to find similar posts")
+	}
+	if len(matches) == 0 {
+		return []*resp.SimilarPost{}, nil
+	}
+
+	contents, err := s.stg.PostContent(s.ctx).ListByIds(lo.Map(matches, func(m *model.PostContentMatch, _ int) uuid.UUID {
+		return m.ContentId
+	}))
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to list matched contents")
+	}
+	posts, err := s.stg.Post(s.ctx).ListByHashIds(lo.Map(matches, func(m *model.PostContentMatch, _ int) string {
+		return m.PostId
+	}))
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to list matched posts")
+	}
+
+	channelIds := lo.Uniq(lo.FilterMap(posts, func(p *model.Post, _ int) (uuid.UUID, bool) {
+		return lo.FromPtr(p.ChannelId), p.ChannelId != nil
+	}))
+	histories, err := s.stg.ChannelHistory(s.ctx).ListLatestByChannelIds(channelIds)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to list channel histories")
+	}
+
+	contentById := lo.KeyBy(contents, func(c *model.PostContent) uuid.UUID { return c.ID })
+	postById := lo.KeyBy(posts, func(p *model.Post) string { return p.ID })
+	historyByChannel := lo.KeyBy(histories, func(h *model.ChannelHistory) uuid.UUID { return h.ChannelID })
+
+	results := make([]*resp.SimilarPost, 0, len(matches))
+	for _, m := range matches {
+		post, ok := postById[m.PostId]
+		if !ok {
+			continue
+		}
+
+		lift := 1.0
+		if post.ChannelId != nil {
+			if history, ok := historyByChannel[*post.ChannelId]; ok && history.AverageLikes+history.AverageComments > 0 {
+				lift = float64(post.LikeCount+post.CommentCount) / float64(history.AverageLikes+history.AverageComments)
+			}
+		}
+
+		result := &resp.SimilarPost{
+			PostId:         post.ID,
+			Link:           post.Link,
+			ImageUrl:       post.ImageURL,
+			UserHandler:    post.UserAnchor,
+			PostDate:       post.PostDate,
+			LikeCount:      post.LikeCount,
+			CommentCount:   post.CommentCount,
+			ViewCount:      post.VideoPlayCount,
+			Similarity:     m.Similarity,
+			EngagementLift: lift,
+			Score:          similarPostScore(m.Similarity, lift),
+		}
+		if content, ok := contentById[m.ContentId]; ok {
+			result.BigIdea = content.Text
+		}
+		results = append(results, result)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	return results, nil
+}
+
+// similarPostScore blends the similarity with the engagement lift on a log scale, both in [0, 1].
+func similarPostScore(similarity, lift float64) float64 {
+	lift = math.Min(math.Max(lift, 0), maxEngagementLift)
+	engagement := math.Log1p(lift) / math.Log1p(maxEngagementLift)
+
+	return similarityWeight*similarity + (1-similarityWeight)*engagement
+}
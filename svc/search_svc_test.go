@@ -0,0 +1,73 @@
+package svc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/amahdian/cliplab-be/global/errs"
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+)
+
+func TestGetSimilarPostsRequiresAccessToThePost(t *testing.T) {
+	stg := newFakeStg()
+	s := newSearchSvc(context.Background(), stg, nil, nil)
+	owner, member, stranger := uuid.New(), uuid.New(), uuid.New()
+
+	workspace := &model.Workspace{Name: "team"}
+	_ = stg.workspaces.CreateOne(workspace)
+	_ = stg.members.CreateOne(&model.WorkspaceMember{WorkspaceId: workspace.ID, Workspace: workspace, UserId: member, Role: model.WorkspaceRoleViewer})
+	_ = stg.requests.CreateOne(&model.AnalyzeRequest{UserId: &owner, PostId: lo.ToPtr("private")})
+	_ = stg.requests.CreateOne(&model.AnalyzeRequest{UserId: &owner, WorkspaceId: &workspace.ID, PostId: lo.ToPtr("shared")})
+
+	cases := []struct {
+		name        string
+		userId      uuid.UUID
+		workspaceId *uuid.UUID
+		postId      string
+		allowed     bool
+	}{
+		{"owner of the post", owner, nil, "private", true},
+		{"stranger", stranger, nil, "private", false},
+		{"member of the workspace of the post", member, &workspace.ID, "shared", true},
+		{"member asking about a private post", member, &workspace.ID, "private", false},
+	}
+
+	for _, c := range cases {
+		posts, err := s.GetSimilarPosts(c.userId, c.workspaceId, c.postId, "", 0)
+		if c.allowed && (err != nil || posts == nil) {
+			t.Errorf("%s: expected the similar posts, got %v", c.name, err)
+		}
+		if !c.allowed && !errs.IsCode(err, errs.NotFound) {
+			t.Errorf("%s: expected not found, got %v", c.name, err)
+		}
+	}
+}
+
+func TestSearchPosts(t *testing.T) {
+	stg := newFakeStg()
+	userId := uuid.New()
+
+	unconfigured := newSearchSvc(context.Background(), stg, nil, nil)
+	if _, err := unconfigured.SearchPosts(userId, nil, "cats", 0); !errs.IsCode(err, errs.Unavailable) {
+		t.Errorf("without embeddings: expected unavailable, got %v", err)
+	}
+
+	gptClient := &fakeGptClient{}
+	s := newSearchSvc(context.Background(), stg, nil, gptClient)
+	if _, err := s.SearchPosts(userId, nil, "  ", 0); !errs.IsCode(err, errs.InvalidArgument) {
+		t.Errorf("empty query: expected invalid argument, got %v", err)
+	}
+	if len(gptClient.embedded) != 0 {
+		t.Errorf("expected an empty query not to be embedded, got %v", gptClient.embedded)
+	}
+
+	results, err := s.SearchPosts(userId, nil, " cats ", 0)
+	if err != nil || results == nil || len(results) != 0 {
+		t.Errorf("no match: expected an empty list, got %v, %v", results, err)
+	}
+	if len(gptClient.embedded) != 1 || gptClient.embedded[0] != "cats" {
+		t.Errorf("expected the trimmed query to be embedded, got %v", gptClient.embedded)
+	}
+}
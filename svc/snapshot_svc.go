@@ -0,0 +1,136 @@
+package svc
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/amahdian/cliplab-be/clients/rocksolid"
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/amahdian/cliplab-be/global"
+	"github.com/amahdian/cliplab-be/global/env"
+	"github.com/amahdian/cliplab-be/global/errs"
+	"github.com/amahdian/cliplab-be/pkg/logger"
+	"github.com/amahdian/cliplab-be/storage"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// maxSnapshotAttempts is the number of scrapes tried before a snapshot is given up.
+	maxSnapshotAttempts = 3
+	// staleSnapshotAge is how long a queued snapshot waits before it is claimed again.
+	staleSnapshotAge = time.Hour
+)
+
+type SnapshotSvc interface {
+	// EnqueueDue pushes the snapshots that are due to the snapshot queue and returns how many were pushed.
+	EnqueueDue() (int, error)
+	// TakeSnapshot scrapes the current counts of the post of the snapshot.
+	TakeSnapshot(id uuid.UUID) error
+}
+
+type snapshotSvc struct {
+	ctx  context.Context
+	stg  storage.PgStorage
+	envs *env.Envs
+
+	RedisClient   *redis.Client
+	ScraperClient rocksolid.Client
+}
+
+func newSnapshotSvc(
+	ctx context.Context,
+	stg storage.PgStorage,
+	envs *env.Envs,
+	redisClient *redis.Client,
+	scraperClient rocksolid.Client) SnapshotSvc {
+	return &snapshotSvc{
+		ctx:           ctx,
+		stg:           stg,
+		envs:          envs,
+		RedisClient:   redisClient,
+		ScraperClient: scraperClient,
+	}
+}
+
+func (s *snapshotSvc) EnqueueDue() (int, error) {
+	now := time.Now()
+	snapshots, err := s.stg.PostStatSnapshot(s.ctx).ClaimDue(now, now.Add(-staleSnapshotAge), s.envs.Calibration.SnapshotBatchSize)
+	if err != nil {
+		return 0, errs.Newf(errs.Internal, err, "failed to claim due snapshots")
+	}
+
+	for _, snapshot := range snapshots {
+		jsonData, _ := json.Marshal(&model.SnapshotQueueData{Id: snapshot.ID})
+		if err = s.RedisClient.LPush(s.ctx, global.RedisSnapshotQueue, jsonData).Err(); err != nil {
+			// the snapshot is claimed again once it goes stale
+			return 0, errs.Newf(errs.Internal, err, "failed to queue snapshot")
+		}
+	}
+
+	return len(snapshots), nil
+}
+
+func (s *snapshotSvc) TakeSnapshot(id uuid.UUID) error {
+	snapshot, err := s.stg.PostStatSnapshot(s.ctx).FindById(id)
+	if err != nil {
+		return errs.Newf(errs.NotFound, err, "snapshot not found")
+	}
+	if snapshot.Status == model.SnapshotStatusDone || snapshot.Status == model.SnapshotStatusFailed {
+		return nil
+	}
+
+	dto, err := s.ScraperClient.GetInstagramPost(snapshot.PostId)
+	if err != nil {
+		snapshot.Attempts++
+		snapshot.Status = model.SnapshotStatusPending
+		snapshot.DueAt = time.Now().Add(time.Duration(snapshot.Attempts) * time.Hour)
+		if snapshot.Attempts >= maxSnapshotAttempts {
+			snapshot.Status = model.SnapshotStatusFailed
+		}
+		_ = s.stg.PostStatSnapshot(s.ctx).UpdateOne(snapshot, false)
+		return errs.Wrapf(err, "failed to scrape post %s for its day %d snapshot", snapshot.PostId, snapshot.DayOffset)
+	}
+
+	now := time.Now()
+	snapshot.Status = model.SnapshotStatusDone
+	snapshot.TakenAt = &now
+	snapshot.LikeCount = dto.EdgeMediaPreviewLike.Count
+	snapshot.CommentCount = dto.EdgeMediaToParentComment.Count
+	snapshot.PlayCount = int64(dto.VideoPlayCount)
+	if err = s.stg.PostStatSnapshot(s.ctx).UpdateOne(snapshot, true); err != nil {
+		return errs.Newf(errs.Internal, err, "failed to save snapshot")
+	}
+
+	return nil
+}
+
+// scheduleStatSnapshots stores the counts of an analyzed post as its baseline and
+// schedules the later snapshots. A post keeps the schedule of its first analysis.
+func scheduleStatSnapshots(ctx context.Context, stg storage.PgStorage, analysis *model.PostAnalysis, counts reelCounts) {
+	now := time.Now()
+	snapshots := []*model.PostStatSnapshot{{
+		PostId:       analysis.PostId,
+		ViralScore:   analysis.ViralScore,
+		Status:       model.SnapshotStatusDone,
+		DueAt:        now,
+		TakenAt:      &now,
+		LikeCount:    counts.Likes,
+		CommentCount: counts.Comments,
+		PlayCount:    counts.Views,
+	}}
+	for _, days := range model.SnapshotDayOffsets {
+		snapshots = append(snapshots, &model.PostStatSnapshot{
+			PostId:     analysis.PostId,
+			DayOffset:  days,
+			ViralScore: analysis.ViralScore,
+			Status:     model.SnapshotStatusPending,
+			DueAt:      now.AddDate(0, 0, days),
+		})
+	}
+
+	if err := stg.PostStatSnapshot(ctx).CreateSchedule(snapshots); err != nil {
+		logger.Errorf("Failed to schedule the stat snapshots of post %s: %v", analysis.PostId, err)
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 
 	"github.com/amahdian/cliplab-be/clients/gemini"
+	"github.com/amahdian/cliplab-be/clients/gpt"
+	"github.com/amahdian/cliplab-be/clients/mailer"
+	"github.com/amahdian/cliplab-be/clients/oidc"
 	"github.com/amahdian/cliplab-be/clients/rocksolid"
 	"github.com/redis/go-redis/v9"
 
@@ -18,6 +21,18 @@ type Svc interface {
 	NewFileSvc(ctx context.Context) FileSvc
 	NewQueueSvc(ctx context.Context) QueueSvc
 	NewWebSocketSvc(ctx context.Context) WebSocketSvc
+	NewReportSvc(ctx context.Context) ReportSvc
+	NewSearchSvc(ctx context.Context) SearchSvc
+	NewCategorySvc(ctx context.Context) CategorySvc
+	NewLibrarySvc(ctx context.Context) LibrarySvc
+	NewChatSvc(ctx context.Context) ChatSvc
+	NewRemixSvc(ctx context.Context) RemixSvc
+	NewScoringSvc(ctx context.Context) ScoringSvc
+	NewCompareSvc(ctx context.Context) CompareSvc
+	NewSnapshotSvc(ctx context.Context) SnapshotSvc
+	NewChannelSvc(ctx context.Context) ChannelSvc
+	NewApiKeySvc(ctx context.Context) ApiKeySvc
+	NewWorkspaceSvc(ctx context.Context) WorkspaceSvc
 }
 
 type StorageConfig struct {
@@ -29,8 +44,11 @@ type svcImpl struct {
 	pgStg         storage.PgStorage
 	Envs          *env.Envs
 	geminiClient  gemini.Client
+	gptClient     gpt.Client
 	scraperClient rocksolid.Client
 	redisClient   *redis.Client
+	mailer        mailer.Mailer
+	oidcClient    oidc.Client
 	storageConfig StorageConfig
 }
 
@@ -38,22 +56,28 @@ func NewSvc(
 	pgStg storage.PgStorage,
 	envs *env.Envs,
 	geminiClient gemini.Client,
+	gptClient gpt.Client,
 	scraperClient rocksolid.Client,
 	redisClient *redis.Client,
+	mailer mailer.Mailer,
+	oidcClient oidc.Client,
 	storageConfig StorageConfig) Svc {
 
 	return &svcImpl{
 		pgStg,
 		envs,
 		geminiClient,
+		gptClient,
 		scraperClient,
 		redisClient,
+		mailer,
+		oidcClient,
 		storageConfig,
 	}
 }
 
 func (s *svcImpl) NewUserSvc(ctx context.Context) UserSvc {
-	return newUserSvc(ctx, s.pgStg, s.Envs)
+	return newUserSvc(ctx, s.pgStg, s.Envs, s.mailer, s.oidcClient)
 }
 
 func (s *svcImpl) NewFileSvc(ctx context.Context) FileSvc {
@@ -65,9 +89,57 @@ func (s *svcImpl) NewPostSvc(ctx context.Context) AnalyzeSvc {
 }
 
 func (s *svcImpl) NewQueueSvc(ctx context.Context) QueueSvc {
-	return newPostQueueSvc(ctx, s.pgStg, s.Envs, s.geminiClient, s.scraperClient)
+	return newPostQueueSvc(ctx, s.pgStg, s.Envs, s.geminiClient, s.gptClient, s.scraperClient, s.NewFileSvc(ctx), s.NewWebSocketSvc(ctx))
 }
 
 func (s *svcImpl) NewWebSocketSvc(ctx context.Context) WebSocketSvc {
 	return NewWebSocketSvc()
 }
+
+func (s *svcImpl) NewReportSvc(ctx context.Context) ReportSvc {
+	return newReportSvc(ctx, s.pgStg, s.Envs)
+}
+
+func (s *svcImpl) NewSearchSvc(ctx context.Context) SearchSvc {
+	return newSearchSvc(ctx, s.pgStg, s.Envs, s.gptClient)
+}
+
+func (s *svcImpl) NewCategorySvc(ctx context.Context) CategorySvc {
+	return newCategorySvc(ctx, s.pgStg, s.Envs)
+}
+
+func (s *svcImpl) NewLibrarySvc(ctx context.Context) LibrarySvc {
+	return newLibrarySvc(ctx, s.pgStg, s.Envs)
+}
+
+func (s *svcImpl) NewChatSvc(ctx context.Context) ChatSvc {
+	return newChatSvc(ctx, s.pgStg, s.Envs, s.gptClient, s.NewWebSocketSvc(ctx))
+}
+
+func (s *svcImpl) NewRemixSvc(ctx context.Context) RemixSvc {
+	return newRemixSvc(ctx, s.pgStg, s.Envs, s.gptClient)
+}
+
+func (s *svcImpl) NewScoringSvc(ctx context.Context) ScoringSvc {
+	return newScoringSvc(ctx, s.pgStg, s.Envs)
+}
+
+func (s *svcImpl) NewCompareSvc(ctx context.Context) CompareSvc {
+	return newCompareSvc(ctx, s.pgStg, s.Envs, s.gptClient)
+}
+
+func (s *svcImpl) NewSnapshotSvc(ctx context.Context) SnapshotSvc {
+	return newSnapshotSvc(ctx, s.pgStg, s.Envs, s.redisClient, s.scraperClient)
+}
+
+func (s *svcImpl) NewChannelSvc(ctx context.Context) ChannelSvc {
+	return newChannelSvc(ctx, s.pgStg, s.Envs)
+}
+
+func (s *svcImpl) NewApiKeySvc(ctx context.Context) ApiKeySvc {
+	return newApiKeySvc(ctx, s.pgStg, s.Envs)
+}
+
+func (s *svcImpl) NewWorkspaceSvc(ctx context.Context) WorkspaceSvc {
+	return newWorkspaceSvc(ctx, s.pgStg, s.Envs, s.mailer)
+}
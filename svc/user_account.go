@@ -0,0 +1,285 @@
+package svc
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/amahdian/cliplab-be/clients/mailer"
+	"github.com/amahdian/cliplab-be/domain/contracts/req"
+	"github.com/amahdian/cliplab-be/domain/contracts/resp"
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/amahdian/cliplab-be/global/errs"
+	"github.com/amahdian/cliplab-be/pkg/logger"
+	"github.com/amahdian/cliplab-be/svc/utils"
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+)
+
+const (
+	accountDeletionEmailSubject = "Your ClipLab account will be deleted"
+	accountDeletionEmailBody    = "Your ClipLab account and its data will be deleted on %s.\n\nSign in before then to keep your account. If you did not ask for it, sign in and change your password."
+	deletionCodeEmailSubject    = "Confirm the deletion of your ClipLab account"
+	deletionCodeEmailBody       = "Your code to delete your ClipLab account is %s.\n\nThe code expires in %d minutes. If you did not ask for it, you can ignore this email."
+	// exportPageSize is the number of library posts read at once for an export.
+	exportPageSize = 100
+	// purgeBatchSize is the number of accounts purged by a run of the purge job.
+	purgeBatchSize = 50
+)
+
+// exportedAnalyzeRequest is an analyze request without the prompts and usage kept for debugging.
+type exportedAnalyzeRequest struct {
+	ID          uuid.UUID            `json:"id"`
+	WorkspaceId *uuid.UUID           `json:"workspaceId,omitempty"`
+	Link        string               `json:"link"`
+	Platform    model.SocialPlatform `json:"platform"`
+	PostId      *string              `json:"postId"`
+	Status      model.RequestStatus  `json:"status"`
+	FailReason  *string              `json:"failReason,omitempty"`
+	UserIP      string               `json:"userIp,omitempty"`
+	CreatedAt   time.Time            `json:"createdAt"`
+	UpdatedAt   time.Time            `json:"updatedAt"`
+}
+
+// exportedProfile is the profile of the user along with the sign-in methods linked to it.
+type exportedProfile struct {
+	*model.User
+	Identities []*model.UserIdentity `json:"identities"`
+}
+
+func (s *userSvc) ExportData(userID uuid.UUID) ([]byte, error) {
+	user, err := s.stg.User(s.ctx).FindById(userID)
+	if err != nil {
+		return nil, errs.Newf(errs.NotFound, err, "user not found")
+	}
+	identities, err := s.stg.UserIdentity(s.ctx).ListByUserId(userID)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to list the sign-in methods")
+	}
+
+	requests, err := s.stg.AnalyzeRequest(s.ctx).ListByUserId(userID)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to list the analyze requests")
+	}
+	exportedRequests := lo.Map(requests, func(r *model.AnalyzeRequest, _ int) *exportedAnalyzeRequest {
+		return &exportedAnalyzeRequest{
+			ID:          r.ID,
+			WorkspaceId: r.WorkspaceId,
+			Link:        r.Link,
+			Platform:    r.Platform,
+			PostId:      r.PostId,
+			Status:      r.Status,
+			FailReason:  r.FailReason,
+			UserIP:      r.UserIP,
+			CreatedAt:   r.CreatedAt,
+			UpdatedAt:   r.UpdatedAt,
+		}
+	})
+
+	var savedPosts []*model.LibraryPost
+	owner := model.LibraryOwner{UserId: userID}
+	for page := 0; ; page++ {
+		posts, total, err := s.stg.Post(s.ctx).ListLibrary(owner, "", page, exportPageSize)
+		if err != nil {
+			return nil, errs.Newf(errs.Internal, err, "failed to list the library")
+		}
+		savedPosts = append(savedPosts, posts...)
+		if len(posts) < exportPageSize || int64(len(savedPosts)) >= total {
+			break
+		}
+	}
+
+	postIds := lo.Uniq(lo.FilterMap(requests, func(r *model.AnalyzeRequest, _ int) (string, bool) {
+		return lo.FromPtr(r.PostId), r.PostId != nil
+	}))
+	analyses, err := s.stg.PostAnalysis(s.ctx).ListByPostIds(postIds)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to list the analyses")
+	}
+
+	chats, err := s.stg.ChatMessage(s.ctx).ListByUserId(userID)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to list the chat messages")
+	}
+	remixes, err := s.stg.RemixVariant(s.ctx).ListByUserId(userID)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to list the remix variants")
+	}
+	comparisons, err := s.stg.Comparison(s.ctx).ListByUserId(userID)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to list the comparisons")
+	}
+	memberships, err := s.stg.WorkspaceMember(s.ctx).ListByUserId(userID)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to list the workspaces")
+	}
+
+	files := []struct {
+		name string
+		data any
+	}{
+		{"profile.json", &exportedProfile{User: user, Identities: identities}},
+		{"analyze_requests.json", exportedRequests},
+		{"saved_posts.json", savedPosts},
+		{"analyses.json", analyses},
+		{"chat_messages.json", chats},
+		{"remix_variants.json", remixes},
+		{"comparisons.json", comparisons},
+		{"workspaces.json", memberships},
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, f := range files {
+		w, err := archive.Create(f.name)
+		if err != nil {
+			return nil, errs.Newf(errs.Internal, err, "failed to add %s to the export", f.name)
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err = encoder.Encode(f.data); err != nil {
+			return nil, errs.Newf(errs.Internal, err, "failed to write %s", f.name)
+		}
+	}
+	if err = archive.Close(); err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to write the export")
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *userSvc) SendDeletionCode(userID uuid.UUID) error {
+	user, err := s.stg.User(s.ctx).FindById(userID)
+	if err != nil {
+		return errs.Newf(errs.NotFound, err, "user not found")
+	}
+	if user.Password != nil || user.TotpEnabledAt != nil {
+		return errs.Newf(errs.FailedPrecondition, nil, "confirm the deletion with your password or two-factor code")
+	}
+
+	code, err := utils.GenerateOtp(s.envs.Otp.Length)
+	if err != nil {
+		return errs.Newf(errs.Internal, err, "failed to generate a code")
+	}
+	return s.issueCode(user, model.CodePurposeAccountDeletion, code, s.envs.Otp.Ttl, mailer.Message{
+		To:      user.Email,
+		Subject: deletionCodeEmailSubject,
+		Body:    fmt.Sprintf(deletionCodeEmailBody, code, int(s.envs.Otp.Ttl.Minutes())),
+	})
+}
+
+func (s *userSvc) DeleteAccount(userID uuid.UUID, data *req.DeleteAccount) (*resp.AccountDeletion, error) {
+	user, err := s.stg.User(s.ctx).FindById(userID)
+	if err != nil {
+		return nil, errs.Newf(errs.NotFound, err, "user not found")
+	}
+	if user.Password != nil && !utils.CheckPasswordHash(data.Password, *user.Password) {
+		return nil, errs.Newf(errs.InvalidArgument, nil, "password is incorrect")
+	}
+	if user.TotpEnabledAt != nil {
+		if err = s.confirmSecondFactor(user, data.Code); err != nil {
+			return nil, err
+		}
+	} else if user.Password == nil {
+		// a stolen access token alone must not be enough to delete an account signing in with a provider
+		if err = s.consumeCode(user, model.CodePurposeAccountDeletion, data.Code); err != nil {
+			return nil, err
+		}
+	}
+
+	if user.DeletionScheduledAt == nil {
+		user.DeletionScheduledAt = lo.ToPtr(time.Now().Add(s.envs.Account.DeletionGracePeriod))
+		if err = s.stg.User(s.ctx).UpdateOne(user, false); err != nil {
+			return nil, errs.Newf(errs.Internal, err, "failed to schedule the deletion")
+		}
+	}
+	if err = s.LogoutAll(userID); err != nil {
+		return nil, err
+	}
+
+	err = s.mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: accountDeletionEmailSubject,
+		Body:    fmt.Sprintf(accountDeletionEmailBody, user.DeletionScheduledAt.Format("January 2, 2006")),
+	})
+	if err != nil {
+		logger.Warnf("Failed to email the deletion of user %s: %v", user.ID, err)
+	}
+
+	return &resp.AccountDeletion{ScheduledAt: *user.DeletionScheduledAt}, nil
+}
+
+// cancelDeletion keeps the account of a user who signed in during the grace period.
+func (s *userSvc) cancelDeletion(user *model.User) error {
+	if user.DeletionScheduledAt == nil {
+		return nil
+	}
+	if err := s.stg.User(s.ctx).CancelDeletion(user.ID); err != nil {
+		return errs.Newf(errs.Internal, err, "failed to cancel the deletion")
+	}
+	user.DeletionScheduledAt = nil
+	return nil
+}
+
+func (s *userSvc) PurgeDeletedAccounts() (int, error) {
+	users, err := s.stg.User(s.ctx).ListDueForDeletion(time.Now(), purgeBatchSize)
+	if err != nil {
+		return 0, errs.Newf(errs.Internal, err, "failed to list the accounts to delete")
+	}
+
+	purged := 0
+	for _, user := range users {
+		if err = s.purgeAccount(user.ID); err != nil {
+			logger.Errorf("Failed to purge user %s: %v", user.ID, err)
+			continue
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// purgeAccount deletes the user. Their analyze requests are detached and anonymized, the workspaces they
+// were the only member of are deleted and the ones they were the last owner of are handed to the oldest member.
+// The rest of their data is deleted along with the user.
+func (s *userSvc) purgeAccount(userID uuid.UUID) error {
+	members := s.stg.WorkspaceMember(s.ctx)
+	memberships, err := members.ListByUserId(userID)
+	if err != nil {
+		return errs.Newf(errs.Internal, err, "failed to list the workspaces")
+	}
+	for _, membership := range memberships {
+		if membership.Role != model.WorkspaceRoleOwner {
+			continue
+		}
+		workspaceMembers, err := members.ListByWorkspaceId(membership.WorkspaceId)
+		if err != nil {
+			return errs.Newf(errs.Internal, err, "failed to list the members")
+		}
+		others := lo.Filter(workspaceMembers, func(m *model.WorkspaceMember, _ int) bool {
+			return m.UserId != userID
+		})
+		if len(others) == 0 {
+			if err = s.stg.Workspace(s.ctx).DeleteById(membership.WorkspaceId); err != nil {
+				return errs.Newf(errs.Internal, err, "failed to delete the workspace")
+			}
+			continue
+		}
+		hasOwner := lo.ContainsBy(others, func(m *model.WorkspaceMember) bool {
+			return m.Role == model.WorkspaceRoleOwner
+		})
+		if !hasOwner {
+			if err = members.UpdateRole(membership.WorkspaceId, others[0].UserId, model.WorkspaceRoleOwner); err != nil {
+				return errs.Newf(errs.Internal, err, "failed to hand over the workspace")
+			}
+		}
+	}
+
+	if err = s.stg.AnalyzeRequest(s.ctx).DetachUser(userID); err != nil {
+		return errs.Newf(errs.Internal, err, "failed to detach the analyze requests")
+	}
+	if err = s.stg.User(s.ctx).DeleteById(userID); err != nil {
+		return errs.Newf(errs.Internal, err, "failed to delete the user")
+	}
+	return nil
+}
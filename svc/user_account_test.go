@@ -0,0 +1,150 @@
+package svc
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/amahdian/cliplab-be/domain/contracts/req"
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/amahdian/cliplab-be/global/errs"
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+)
+
+func TestExportData(t *testing.T) {
+	stg := newFakeStg()
+	s := newTestUserSvc(stg)
+	user := &model.User{Email: "user@cliplab.test", Password: lo.ToPtr("password-hash")}
+	_ = stg.users.CreateOne(user)
+	other := uuid.New()
+
+	stg.identities.list = []*model.UserIdentity{{ID: uuid.New(), UserId: user.ID, Provider: "google"}}
+	_ = stg.requests.CreateOne(&model.AnalyzeRequest{UserId: &user.ID, UserIP: "10.0.0.1", PostId: lo.ToPtr("post-1"), Link: "https://cliplab.test/1"})
+	_ = stg.requests.CreateOne(&model.AnalyzeRequest{UserId: &other, PostId: lo.ToPtr("post-2"), Link: "https://cliplab.test/2"})
+	stg.posts.library[user.ID] = []*model.LibraryPost{{RequestId: uuid.New(), PostId: "post-1"}}
+	stg.analyses.list = []*model.PostAnalysis{{PostId: "post-1"}, {PostId: "post-2"}}
+	stg.chats.list = []*model.ChatMessage{
+		{UserId: user.ID, PostId: "post-1", Content: "my question"},
+		{UserId: other, PostId: "post-2", Content: "their question"},
+	}
+
+	data, err := s.ExportData(user.ID)
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("read the archive: %v", err)
+	}
+	files := map[string]string{}
+	for _, f := range archive.File {
+		r, _ := f.Open()
+		content, _ := io.ReadAll(r)
+		_ = r.Close()
+		files[f.Name] = string(content)
+	}
+
+	for _, name := range []string{"profile.json", "analyze_requests.json", "saved_posts.json", "analyses.json",
+		"chat_messages.json", "remix_variants.json", "comparisons.json", "workspaces.json"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("export misses %s", name)
+		}
+	}
+	if !strings.Contains(files["profile.json"], user.Email) || strings.Contains(files["profile.json"], "password-hash") {
+		t.Errorf("unexpected profile:\n%s", files["profile.json"])
+	}
+	if !strings.Contains(files["analyze_requests.json"], "10.0.0.1") || strings.Contains(files["analyze_requests.json"], "post-2") {
+		t.Errorf("unexpected analyze requests:\n%s", files["analyze_requests.json"])
+	}
+	if !strings.Contains(files["analyses.json"], "post-1") || strings.Contains(files["analyses.json"], "post-2") {
+		t.Errorf("unexpected analyses:\n%s", files["analyses.json"])
+	}
+	if !strings.Contains(files["chat_messages.json"], "my question") || strings.Contains(files["chat_messages.json"], "their question") {
+		t.Errorf("unexpected chat messages:\n%s", files["chat_messages.json"])
+	}
+}
+
+func TestDeleteAccountWithoutPasswordRequiresEmailedCode(t *testing.T) {
+	stg := newFakeStg()
+	s := newTestUserSvc(stg)
+	user := &model.User{Email: "user@cliplab.test"}
+	_ = stg.users.CreateOne(user)
+
+	if _, err := s.DeleteAccount(user.ID, &req.DeleteAccount{}); !errs.IsCode(err, errs.InvalidArgument) {
+		t.Fatalf("without a code: expected invalid argument, got %v", err)
+	}
+
+	if err := s.SendDeletionCode(user.ID); err != nil {
+		t.Fatalf("send the code: %v", err)
+	}
+	sent := s.mailer.(*fakeMailer).sent
+	code := regexp.MustCompile(`\d{6}`).FindString(sent[len(sent)-1].Body)
+
+	deletion, err := s.DeleteAccount(user.ID, &req.DeleteAccount{Code: code})
+	if err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if user.DeletionScheduledAt == nil || !deletion.ScheduledAt.After(time.Now()) {
+		t.Errorf("expected the deletion to be scheduled, got %v", deletion.ScheduledAt)
+	}
+}
+
+func TestSendDeletionCodeWithPassword(t *testing.T) {
+	stg := newFakeStg()
+	s := newTestUserSvc(stg)
+	user := &model.User{Email: "user@cliplab.test", Password: lo.ToPtr("password-hash")}
+	_ = stg.users.CreateOne(user)
+
+	if err := s.SendDeletionCode(user.ID); !errs.IsCode(err, errs.FailedPrecondition) {
+		t.Errorf("expected failed precondition, got %v", err)
+	}
+}
+
+func TestPurgeDeletedAccounts(t *testing.T) {
+	stg := newFakeStg()
+	s := newTestUserSvc(stg)
+	due := &model.User{Email: "due@cliplab.test", DeletionScheduledAt: lo.ToPtr(time.Now().Add(-time.Minute))}
+	later := &model.User{Email: "later@cliplab.test", DeletionScheduledAt: lo.ToPtr(time.Now().Add(time.Hour))}
+	member := &model.User{Email: "member@cliplab.test"}
+	for _, u := range []*model.User{due, later, member} {
+		_ = stg.users.CreateOne(u)
+	}
+
+	alone := &model.Workspace{Name: "alone"}
+	shared := &model.Workspace{Name: "shared"}
+	_ = stg.workspaces.CreateOne(alone)
+	_ = stg.workspaces.CreateOne(shared)
+	_ = stg.members.CreateOne(&model.WorkspaceMember{WorkspaceId: alone.ID, UserId: due.ID, Role: model.WorkspaceRoleOwner})
+	_ = stg.members.CreateOne(&model.WorkspaceMember{WorkspaceId: shared.ID, UserId: due.ID, Role: model.WorkspaceRoleOwner})
+	_ = stg.members.CreateOne(&model.WorkspaceMember{WorkspaceId: shared.ID, UserId: member.ID, Role: model.WorkspaceRoleEditor})
+	request := &model.AnalyzeRequest{UserId: &due.ID, UserIP: "10.0.0.1"}
+	_ = stg.requests.CreateOne(request)
+
+	purged, err := s.PurgeDeletedAccounts()
+	if err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+	if purged != 1 {
+		t.Errorf("expected one purged account, got %d", purged)
+	}
+	if _, err = stg.users.FindById(due.ID); err == nil {
+		t.Errorf("expected the due account to be deleted")
+	}
+	if _, err = stg.users.FindById(later.ID); err != nil {
+		t.Errorf("expected the account in its grace period to be kept")
+	}
+	if _, err = stg.workspaces.FindById(alone.ID); err == nil {
+		t.Errorf("expected the workspace of the only member to be deleted")
+	}
+	if m, _ := stg.members.Find(shared.ID, member.ID); m == nil || m.Role != model.WorkspaceRoleOwner {
+		t.Errorf("expected the shared workspace to be handed to the member, got %+v", m)
+	}
+	if request.UserId != nil || request.UserIP != "" {
+		t.Errorf("expected the analyze request to be detached, got %+v", request)
+	}
+}
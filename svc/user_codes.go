@@ -0,0 +1,106 @@
+package svc
+
+import (
+	"time"
+
+	"github.com/amahdian/cliplab-be/clients/mailer"
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/amahdian/cliplab-be/global/errs"
+	"github.com/amahdian/cliplab-be/svc/utils"
+)
+
+// issueCode replaces the active codes of the user for the purpose with the code and emails the message carrying it.
+// Codes are throttled by the resend interval and the hourly limit of the configuration.
+func (s *userSvc) issueCode(user *model.User, purpose model.CodePurpose, code string, ttl time.Duration, message mailer.Message) error {
+	codes := s.stg.UserCode(s.ctx)
+
+	latest, err := codes.FindLatest(user.ID, purpose)
+	if err != nil {
+		return errs.Newf(errs.Internal, err, "failed to find the latest code")
+	}
+	if latest != nil && time.Since(latest.CreatedAt) < s.envs.Otp.ResendInterval {
+		return errs.Newf(errs.ResourceExhausted, nil, "please wait before requesting a new code")
+	}
+	sent, err := codes.CountSince(user.ID, purpose, time.Now().Add(-time.Hour))
+	if err != nil {
+		return errs.Newf(errs.Internal, err, "failed to count the sent codes")
+	}
+	if sent >= int64(s.envs.Otp.MaxPerHour) {
+		return errs.Newf(errs.ResourceExhausted, nil, "too many codes requested, try again later")
+	}
+
+	if err = codes.ConsumeAll(user.ID, purpose); err != nil {
+		return errs.Newf(errs.Internal, err, "failed to invalidate the previous codes")
+	}
+	err = codes.CreateOne(&model.UserCode{
+		UserId:    user.ID,
+		Purpose:   purpose,
+		CodeHash:  utils.HashOtp(s.envs.Server.JwtSecret, code),
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		return errs.Newf(errs.Internal, err, "failed to save the code")
+	}
+
+	if err = s.mailer.Send(message); err != nil {
+		return errs.Newf(errs.Unavailable, err, "failed to send the code")
+	}
+	return nil
+}
+
+// consumeCode checks the code against the active code of the user for the purpose and consumes it on success.
+// Every guess counts against the attempts of the code.
+func (s *userSvc) consumeCode(user *model.User, purpose model.CodePurpose, code string) error {
+	codes := s.stg.UserCode(s.ctx)
+
+	active, err := codes.FindLatestActive(user.ID, purpose)
+	if err != nil {
+		return errs.Newf(errs.Internal, err, "failed to find the code")
+	}
+	if active == nil || time.Now().After(active.ExpiresAt) {
+		return errs.Newf(errs.InvalidArgument, nil, "the code has expired, request a new one")
+	}
+	claimed, err := codes.ClaimAttempt(active.ID, s.envs.Otp.MaxAttempts)
+	if err != nil {
+		return errs.Newf(errs.Internal, err, "failed to count the attempt")
+	}
+	if !claimed {
+		return errs.Newf(errs.ResourceExhausted, nil, "too many wrong attempts, request a new code")
+	}
+
+	if !utils.CheckOtpHash(s.envs.Server.JwtSecret, code, active.CodeHash) {
+		return errs.Newf(errs.InvalidArgument, nil, "invalid code")
+	}
+
+	consumed, err := codes.Consume(active.ID)
+	if err != nil {
+		return errs.Newf(errs.Internal, err, "failed to consume the code")
+	}
+	if !consumed {
+		return errs.Newf(errs.InvalidArgument, nil, "the code has already been used, request a new one")
+	}
+	return nil
+}
+
+// consumeToken finds the active code of the purpose matching the token and consumes it.
+// Tokens are long enough to be looked up by their hash alone, so they carry no attempt limit.
+func (s *userSvc) consumeToken(purpose model.CodePurpose, token string) (*model.UserCode, error) {
+	codes := s.stg.UserCode(s.ctx)
+
+	active, err := codes.FindActiveByHash(purpose, utils.HashOtp(s.envs.Server.JwtSecret, token))
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to find the token")
+	}
+	if active == nil || time.Now().After(active.ExpiresAt) {
+		return nil, errs.Newf(errs.InvalidArgument, nil, "the link is invalid or has expired")
+	}
+
+	consumed, err := codes.Consume(active.ID)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to consume the token")
+	}
+	if !consumed {
+		return nil, errs.Newf(errs.InvalidArgument, nil, "the link is invalid or has expired")
+	}
+	return active, nil
+}
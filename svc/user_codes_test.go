@@ -0,0 +1,72 @@
+package svc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/amahdian/cliplab-be/clients/mailer"
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/amahdian/cliplab-be/global/errs"
+	"github.com/google/uuid"
+)
+
+func issueTestCode(t *testing.T, s *userSvc, user *model.User, code string) {
+	t.Helper()
+	err := s.issueCode(user, model.CodePurposeEmailVerification, code, time.Minute, mailer.Message{To: user.Email})
+	if err != nil {
+		t.Fatalf("issue: %v", err)
+	}
+}
+
+func TestConsumeCodeOnce(t *testing.T) {
+	s := newTestUserSvc(newFakeStg())
+	user := &model.User{ID: uuid.New(), Email: "user@cliplab.test"}
+	issueTestCode(t, s, user, "123456")
+
+	if err := s.consumeCode(user, model.CodePurposeEmailVerification, "123456"); err != nil {
+		t.Fatalf("consume: %v", err)
+	}
+	if err := s.consumeCode(user, model.CodePurposeEmailVerification, "123456"); !errs.IsCode(err, errs.InvalidArgument) {
+		t.Errorf("reuse: expected invalid argument, got %v", err)
+	}
+}
+
+func TestConsumeCodeAttempts(t *testing.T) {
+	s := newTestUserSvc(newFakeStg())
+	user := &model.User{ID: uuid.New(), Email: "user@cliplab.test"}
+	issueTestCode(t, s, user, "123456")
+
+	for i := 0; i < s.envs.Otp.MaxAttempts; i++ {
+		if err := s.consumeCode(user, model.CodePurposeEmailVerification, "654321"); !errs.IsCode(err, errs.InvalidArgument) {
+			t.Fatalf("wrong code: expected invalid argument, got %v", err)
+		}
+	}
+	if err := s.consumeCode(user, model.CodePurposeEmailVerification, "123456"); !errs.IsCode(err, errs.ResourceExhausted) {
+		t.Errorf("after the attempts: expected resource exhausted, got %v", err)
+	}
+}
+
+func TestConsumeCodeExpired(t *testing.T) {
+	stg := newFakeStg()
+	s := newTestUserSvc(stg)
+	user := &model.User{ID: uuid.New(), Email: "user@cliplab.test"}
+	issueTestCode(t, s, user, "123456")
+	for _, code := range stg.codes.table.rows {
+		code.ExpiresAt = time.Now().Add(-time.Second)
+	}
+
+	if err := s.consumeCode(user, model.CodePurposeEmailVerification, "123456"); !errs.IsCode(err, errs.InvalidArgument) {
+		t.Errorf("expected invalid argument, got %v", err)
+	}
+}
+
+func TestIssueCodeThrottles(t *testing.T) {
+	s := newTestUserSvc(newFakeStg())
+	user := &model.User{ID: uuid.New(), Email: "user@cliplab.test"}
+	issueTestCode(t, s, user, "123456")
+
+	err := s.issueCode(user, model.CodePurposeEmailVerification, "654321", time.Minute, mailer.Message{To: user.Email})
+	if !errs.IsCode(err, errs.ResourceExhausted) {
+		t.Errorf("expected resource exhausted, got %v", err)
+	}
+}
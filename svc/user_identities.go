@@ -0,0 +1,252 @@
+package svc
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/amahdian/cliplab-be/domain/contracts/req"
+	"github.com/amahdian/cliplab-be/domain/contracts/resp"
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/amahdian/cliplab-be/global/errs"
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+)
+
+const facebookGraphUrl = "https://graph.facebook.com/v19.0"
+
+// oauthIdentity is the provider account an OAuth token was issued for.
+type oauthIdentity struct {
+	Provider      model.Provider
+	ProviderId    string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Picture       string
+}
+
+func (i *oauthIdentity) toModel(userId uuid.UUID) *model.UserIdentity {
+	return &model.UserIdentity{
+		UserId:     userId,
+		Provider:   i.Provider,
+		ProviderId: i.ProviderId,
+		Email:      i.Email,
+	}
+}
+
+func (s *userSvc) verifyOauthToken(provider model.Provider, token string) (*oauthIdentity, error) {
+	switch provider {
+	case model.ProviderGoogle:
+		info, err := s.verifyGoogleToken(token)
+		if err != nil {
+			return nil, errs.Newf(errs.Unauthenticated, err, "invalid google token")
+		}
+		return &oauthIdentity{
+			Provider:      model.ProviderGoogle,
+			ProviderId:    info.Sub,
+			Email:         info.Email,
+			EmailVerified: info.EmailVerified,
+			Name:          info.Name,
+			Picture:       info.Picture,
+		}, nil
+	case model.ProviderFacebook:
+		return s.verifyFacebookToken(token)
+	}
+	return nil, errs.Newf(errs.InvalidArgument, nil, "provider %s is not supported", provider)
+}
+
+type facebookDebugToken struct {
+	Data struct {
+		AppId   string `json:"app_id"`
+		IsValid bool   `json:"is_valid"`
+		UserId  string `json:"user_id"`
+	} `json:"data"`
+}
+
+type facebookProfile struct {
+	Id      string `json:"id"`
+	Name    string `json:"name"`
+	Email   string `json:"email"`
+	Picture struct {
+		Data struct {
+			Url string `json:"url"`
+		} `json:"data"`
+	} `json:"picture"`
+}
+
+type facebookError struct {
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// verifyFacebookToken checks that the user access token was issued for our app before reading the profile.
+func (s *userSvc) verifyFacebookToken(token string) (*oauthIdentity, error) {
+	appID, appSecret := s.envs.Facebook.AppID, s.envs.Facebook.AppSecret
+	if appID == "" || appSecret == "" {
+		return nil, errs.Newf(errs.FailedPrecondition, nil, "facebook login is not configured")
+	}
+	client := &http.Client{
+		Timeout: 5 * time.Second,
+	}
+
+	debug := &facebookDebugToken{}
+	query := url.Values{
+		"input_token":  {token},
+		"access_token": {appID + "|" + appSecret},
+	}
+	if err := getFacebookJson(client, "/debug_token", query, debug); err != nil {
+		return nil, errs.Newf(errs.Unauthenticated, err, "invalid facebook token")
+	}
+	if !debug.Data.IsValid || debug.Data.AppId != appID {
+		return nil, errs.Newf(errs.Unauthenticated, nil, "invalid facebook token")
+	}
+
+	mac := hmac.New(sha256.New, []byte(appSecret))
+	mac.Write([]byte(token))
+	profile := &facebookProfile{}
+	query = url.Values{
+		"fields":          {"id,name,email,picture.type(large)"},
+		"access_token":    {token},
+		"appsecret_proof": {hex.EncodeToString(mac.Sum(nil))},
+	}
+	if err := getFacebookJson(client, "/me", query, profile); err != nil {
+		return nil, errs.Newf(errs.Unauthenticated, err, "failed to read the facebook profile")
+	}
+	if profile.Id != debug.Data.UserId {
+		return nil, errs.Newf(errs.Unauthenticated, nil, "invalid facebook token")
+	}
+
+	return &oauthIdentity{
+		Provider:   model.ProviderFacebook,
+		ProviderId: profile.Id,
+		Email:      profile.Email,
+		// facebook only shares confirmed email addresses
+		EmailVerified: profile.Email != "",
+		Name:          profile.Name,
+		Picture:       profile.Picture.Data.Url,
+	}, nil
+}
+
+func getFacebookJson(client *http.Client, path string, query url.Values, target any) error {
+	res, err := client.Get(facebookGraphUrl + path + "?" + query.Encode())
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		fbErr := &facebookError{}
+		if json.NewDecoder(res.Body).Decode(fbErr) == nil && fbErr.Error != nil {
+			return fmt.Errorf("facebook %s failed: %s", path, fbErr.Error.Message)
+		}
+		return fmt.Errorf("facebook %s failed with status: %d", path, res.StatusCode)
+	}
+	return json.NewDecoder(res.Body).Decode(target)
+}
+
+// linkByEmail prepares an existing user to be signed in with a provider account of the same email.
+func (s *userSvc) linkByEmail(user *model.User, identity *oauthIdentity) error {
+	if !identity.EmailVerified {
+		return errs.Newf(errs.FailedPrecondition, nil,
+			"an account with this email exists, sign in to it and link your %s account from your profile", identity.Provider)
+	}
+
+	if user.VerifiedAt == nil {
+		// nobody proved owning the email of the account, so it may have been registered by someone else
+		user.Password = nil
+		user.VerifiedAt = lo.ToPtr(time.Now())
+		if err := s.stg.UserSession(s.ctx).RevokeAll(user.ID); err != nil {
+			return errs.Newf(errs.Internal, err, "failed to revoke the sessions")
+		}
+	}
+	if user.Name == nil {
+		user.Name = lo.EmptyableToPtr(identity.Name)
+	}
+	if user.ProfileImage == nil {
+		user.ProfileImage = lo.EmptyableToPtr(identity.Picture)
+	}
+
+	return s.stg.User(s.ctx).UpdateOne(user, true)
+}
+
+func (s *userSvc) LinkIdentity(userID uuid.UUID, data *req.OauthLogin) (*model.UserIdentity, error) {
+	identity, err := s.verifyOauthToken(data.Provider, data.Token)
+	if err != nil {
+		return nil, err
+	}
+	return s.linkIdentity(userID, identity)
+}
+
+// linkIdentity links the provider account to the user, unless it is linked already or the user has one of the provider.
+func (s *userSvc) linkIdentity(userID uuid.UUID, identity *oauthIdentity) (*model.UserIdentity, error) {
+	linked, err := s.stg.UserIdentity(s.ctx).FindByProvider(identity.Provider, identity.ProviderId)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to find the identity")
+	}
+	if linked != nil {
+		if linked.UserId == userID {
+			return nil, errs.Newf(errs.AlreadyExists, nil, "this %s account is already linked", identity.Provider)
+		}
+		return nil, errs.Newf(errs.AlreadyExists, nil, "this %s account is linked to another user", identity.Provider)
+	}
+
+	identities, err := s.stg.UserIdentity(s.ctx).ListByUserId(userID)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to list the identities")
+	}
+	if lo.ContainsBy(identities, func(i *model.UserIdentity) bool { return i.Provider == identity.Provider }) {
+		return nil, errs.Newf(errs.AlreadyExists, nil, "a %s account is already linked, unlink it first", identity.Provider)
+	}
+
+	linked = identity.toModel(userID)
+	if err = s.stg.UserIdentity(s.ctx).CreateOne(linked); err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to link the %s account", identity.Provider)
+	}
+	return linked, nil
+}
+
+func (s *userSvc) UnlinkIdentity(userID uuid.UUID, provider model.Provider) error {
+	user, err := s.stg.User(s.ctx).FindById(userID)
+	if err != nil {
+		return errs.Newf(errs.NotFound, err, "user not found")
+	}
+	identities, err := s.stg.UserIdentity(s.ctx).ListByUserId(userID)
+	if err != nil {
+		return errs.Newf(errs.Internal, err, "failed to list the identities")
+	}
+
+	identity, found := lo.Find(identities, func(i *model.UserIdentity) bool { return i.Provider == provider })
+	if !found {
+		return errs.Newf(errs.NotFound, nil, "no %s account is linked", provider)
+	}
+	if user.Password == nil && len(identities) == 1 {
+		return errs.Newf(errs.FailedPrecondition, nil, "set a password or link another account before unlinking the last sign-in method")
+	}
+
+	if err = s.stg.UserIdentity(s.ctx).DeleteOne(identity); err != nil {
+		return errs.Newf(errs.Internal, err, "failed to unlink the %s account", provider)
+	}
+	return nil
+}
+
+func (s *userSvc) ListIdentities(userID uuid.UUID) (*resp.SignInMethods, error) {
+	user, err := s.stg.User(s.ctx).FindById(userID)
+	if err != nil {
+		return nil, errs.Newf(errs.NotFound, err, "user not found")
+	}
+	identities, err := s.stg.UserIdentity(s.ctx).ListByUserId(userID)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to list the identities")
+	}
+
+	return &resp.SignInMethods{
+		HasPassword: user.Password != nil,
+		Identities:  identities,
+	}, nil
+}
@@ -0,0 +1,66 @@
+package svc
+
+import (
+	"testing"
+
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/amahdian/cliplab-be/global/errs"
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+)
+
+func TestLoginIdentityLinksVerifiedEmail(t *testing.T) {
+	stg := newFakeStg()
+	s := newTestUserSvc(stg)
+	// nobody verified the email of the account, so its password may belong to someone else
+	user := &model.User{Email: "user@cliplab.test", Password: lo.ToPtr("password-hash")}
+	_ = stg.users.CreateOne(user)
+	identity := &oauthIdentity{Provider: model.ProviderGoogle, ProviderId: "google-1", Email: "USER@cliplab.test", EmailVerified: true}
+
+	auth, err := s.loginIdentity(identity, model.SessionDevice{})
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	if auth.User.ID != user.ID {
+		t.Errorf("expected to sign in the existing user")
+	}
+	if linked, _ := stg.identities.FindByProvider(model.ProviderGoogle, "google-1"); linked == nil || linked.UserId != user.ID {
+		t.Errorf("expected the identity to be linked to the user, got %+v", linked)
+	}
+	if user.Password != nil || user.VerifiedAt == nil {
+		t.Errorf("expected the unverified password to be dropped and the email verified")
+	}
+}
+
+func TestLoginIdentityRefusesUnverifiedEmail(t *testing.T) {
+	stg := newFakeStg()
+	s := newTestUserSvc(stg)
+	user := &model.User{Email: "user@cliplab.test", Password: lo.ToPtr("password-hash")}
+	_ = stg.users.CreateOne(user)
+	identity := &oauthIdentity{Provider: model.ProviderFacebook, ProviderId: "facebook-1", Email: user.Email}
+
+	if _, err := s.loginIdentity(identity, model.SessionDevice{}); !errs.IsCode(err, errs.FailedPrecondition) {
+		t.Fatalf("expected failed precondition, got %v", err)
+	}
+	if len(stg.identities.list) != 0 {
+		t.Errorf("expected no identity to be linked")
+	}
+	if user.Password == nil {
+		t.Errorf("expected the password to be kept")
+	}
+}
+
+func TestLinkIdentityLinkedToAnotherUser(t *testing.T) {
+	stg := newFakeStg()
+	s := newTestUserSvc(stg)
+	owner := uuid.New()
+	stg.identities.list = []*model.UserIdentity{{UserId: owner, Provider: model.ProviderGoogle, ProviderId: "google-1"}}
+	identity := &oauthIdentity{Provider: model.ProviderGoogle, ProviderId: "google-1", Email: "user@cliplab.test", EmailVerified: true}
+
+	if _, err := s.linkIdentity(uuid.New(), identity); !errs.IsCode(err, errs.AlreadyExists) {
+		t.Fatalf("expected already exists, got %v", err)
+	}
+	if len(stg.identities.list) != 1 {
+		t.Errorf("expected the identity to stay linked to its user only")
+	}
+}
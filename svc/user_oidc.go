@@ -0,0 +1,107 @@
+package svc
+
+import (
+	"crypto/subtle"
+	"time"
+
+	"github.com/amahdian/cliplab-be/clients/oidc"
+	"github.com/amahdian/cliplab-be/domain/contracts/req"
+	"github.com/amahdian/cliplab-be/domain/contracts/resp"
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/amahdian/cliplab-be/global/errs"
+	"github.com/amahdian/cliplab-be/svc/utils"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// oidcFlowTtl is the time the user has to sign in at the identity provider.
+	oidcFlowTtl     = 10 * time.Minute
+	oidcFlowType    = "oidc_flow"
+	oidcRandomBytes = 16
+)
+
+// oidcFlowClaims keep the secrets of a pending sign-in on the frontend, signed so they cannot be changed.
+// The state is the only one sent through the identity provider, the verifier never leaves the frontend and the api.
+type oidcFlowClaims struct {
+	jwt.RegisteredClaims
+	Type     string `json:"typ"`
+	State    string `json:"state"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+}
+
+func (s *userSvc) OidcAuthorize() (*resp.OidcAuthorization, error) {
+	if s.oidc == nil {
+		return nil, errs.Newf(errs.FailedPrecondition, nil, "single sign-on is not configured")
+	}
+
+	state, err := utils.GenerateToken(oidcRandomBytes)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to generate the state")
+	}
+	nonce, err := utils.GenerateToken(oidcRandomBytes)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to generate the nonce")
+	}
+	verifier, err := oidc.NewCodeVerifier()
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to generate the code verifier")
+	}
+
+	url, err := s.oidc.AuthCodeUrl(state, nonce, oidc.CodeChallenge(verifier))
+	if err != nil {
+		return nil, errs.Newf(errs.Unavailable, err, "the identity provider is not available")
+	}
+
+	now := time.Now()
+	claims := &oidcFlowClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(oidcFlowTtl)),
+		},
+		Type:     oidcFlowType,
+		State:    state,
+		Nonce:    nonce,
+		Verifier: verifier,
+	}
+	flow, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(s.envs.Server.JwtSecret))
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to sign the flow")
+	}
+
+	return &resp.OidcAuthorization{
+		Url:  url,
+		Flow: flow,
+	}, nil
+}
+
+func (s *userSvc) LoginOidc(data *req.OidcCallback, device model.SessionDevice) (*resp.AuthResponse, error) {
+	if s.oidc == nil {
+		return nil, errs.Newf(errs.FailedPrecondition, nil, "single sign-on is not configured")
+	}
+
+	flow := &oidcFlowClaims{}
+	_, err := jwt.ParseWithClaims(data.Flow, flow, func(t *jwt.Token) (interface{}, error) {
+		return []byte(s.envs.Server.JwtSecret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil || flow.Type != oidcFlowType {
+		return nil, errs.Newf(errs.Unauthenticated, err, "the sign-in has expired, please try again")
+	}
+	if subtle.ConstantTimeCompare([]byte(flow.State), []byte(data.State)) != 1 {
+		return nil, errs.Newf(errs.Unauthenticated, nil, "the sign-in state does not match")
+	}
+
+	claims, err := s.oidc.Exchange(data.Code, flow.Verifier, flow.Nonce)
+	if err != nil {
+		return nil, errs.Newf(errs.Unauthenticated, err, "failed to sign in with the identity provider")
+	}
+
+	return s.loginIdentity(&oauthIdentity{
+		Provider:      model.ProviderOidc,
+		ProviderId:    claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified,
+		Name:          claims.Name,
+		Picture:       claims.Picture,
+	}, device)
+}
@@ -0,0 +1,175 @@
+package svc
+
+import (
+	"time"
+
+	"github.com/amahdian/cliplab-be/domain/contracts/resp"
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/amahdian/cliplab-be/global/errs"
+	"github.com/amahdian/cliplab-be/pkg/logger"
+	"github.com/amahdian/cliplab-be/svc/auth"
+	"github.com/amahdian/cliplab-be/svc/utils"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+)
+
+// refreshTokenSize is the number of random bytes of a refresh token.
+const refreshTokenSize = 32
+
+// generateAuthResponse signs the user in on a new session for the device.
+func (s *userSvc) generateAuthResponse(user *model.User, device model.SessionDevice) (*resp.AuthResponse, error) {
+	if err := s.cancelDeletion(user); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	session := &model.UserSession{
+		UserId:     user.ID,
+		UserAgent:  device.UserAgent,
+		Ip:         lo.EmptyableToPtr(device.Ip),
+		ExpiresAt:  now.Add(s.envs.Auth.RefreshTokenTtl),
+		LastUsedAt: now,
+	}
+	if err := s.stg.UserSession(s.ctx).CreateOne(session); err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to create the session")
+	}
+
+	return s.issueTokens(user, session)
+}
+
+// issueTokens returns a new access token and refresh token for the session.
+func (s *userSvc) issueTokens(user *model.User, session *model.UserSession) (*resp.AuthResponse, error) {
+	refreshToken, err := utils.GenerateToken(refreshTokenSize)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to generate a refresh token")
+	}
+	err = s.stg.RefreshToken(s.ctx).CreateOne(&model.RefreshToken{
+		SessionId: session.ID,
+		TokenHash: utils.HashOtp(s.envs.Server.JwtSecret, refreshToken),
+	})
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to save the refresh token")
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(s.envs.Auth.AccessTokenTtl)
+	claims := jwt.MapClaims{
+		"id":    user.ID,
+		"name":  user.Name,
+		"email": user.Email,
+		"sid":   session.ID,
+		"iat":   now.Unix(),
+		"exp":   expiresAt.Unix(),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenStr, err := token.SignedString([]byte(s.envs.Server.JwtSecret))
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp.AuthResponse{
+		Token:        tokenStr,
+		ExpiresAt:    expiresAt,
+		RefreshToken: refreshToken,
+		User:         user,
+	}, nil
+}
+
+func (s *userSvc) Refresh(refreshToken string, device model.SessionDevice) (*resp.AuthResponse, error) {
+	token, err := s.stg.RefreshToken(s.ctx).FindByHash(utils.HashOtp(s.envs.Server.JwtSecret, refreshToken))
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to find the refresh token")
+	}
+	if token == nil {
+		return nil, errs.Newf(errs.Unauthenticated, nil, "invalid refresh token")
+	}
+
+	session, err := s.stg.UserSession(s.ctx).FindById(token.SessionId)
+	if err != nil {
+		return nil, errs.Newf(errs.Unauthenticated, err, "session not found")
+	}
+	if !session.Active() {
+		return nil, errs.Newf(errs.Unauthenticated, nil, "session has expired")
+	}
+
+	fresh, err := s.stg.RefreshToken(s.ctx).MarkUsed(token.ID)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to rotate the refresh token")
+	}
+	if !fresh {
+		// the token was stolen or the client lost the rotated one, either way nobody should keep the session
+		logger.Warnf("Refresh token reuse detected, revoking session %s of user %s", session.ID, session.UserId)
+		_, _ = s.stg.UserSession(s.ctx).Revoke(session.UserId, session.ID)
+		return nil, errs.Newf(errs.Unauthenticated, nil, "refresh token has already been used, please sign in again")
+	}
+
+	user, err := s.stg.User(s.ctx).FindById(session.UserId)
+	if err != nil {
+		return nil, errs.Newf(errs.Unauthenticated, err, "user not found")
+	}
+
+	now := time.Now()
+	session.LastUsedAt = now
+	session.ExpiresAt = now.Add(s.envs.Auth.RefreshTokenTtl)
+	session.UserAgent = device.UserAgent
+	session.Ip = lo.EmptyableToPtr(device.Ip)
+	if err = s.stg.UserSession(s.ctx).UpdateOne(session, false); err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to update the session")
+	}
+
+	return s.issueTokens(user, session)
+}
+
+func (s *userSvc) Logout(userInfo *auth.UserInfo) error {
+	if userInfo.SessionId == nil {
+		return nil
+	}
+	if _, err := s.stg.UserSession(s.ctx).Revoke(userInfo.Id, *userInfo.SessionId); err != nil {
+		return errs.Newf(errs.Internal, err, "failed to revoke the session")
+	}
+	return nil
+}
+
+func (s *userSvc) LogoutAll(userID uuid.UUID) error {
+	user, err := s.stg.User(s.ctx).FindById(userID)
+	if err != nil {
+		return errs.Newf(errs.NotFound, err, "user not found")
+	}
+
+	if err = s.stg.UserSession(s.ctx).RevokeAll(userID); err != nil {
+		return errs.Newf(errs.Internal, err, "failed to revoke the sessions")
+	}
+	// also rejects the access tokens issued until now, whatever their session
+	now := time.Now()
+	user.SessionsRevokedAt = &now
+	if err = s.stg.User(s.ctx).UpdateOne(user, false); err != nil {
+		return errs.Newf(errs.Internal, err, "failed to revoke the sessions")
+	}
+	return nil
+}
+
+func (s *userSvc) ListSessions(userInfo *auth.UserInfo) ([]*resp.UserSession, error) {
+	sessions, err := s.stg.UserSession(s.ctx).ListActiveByUserId(userInfo.Id)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to list the sessions")
+	}
+
+	return lo.Map(sessions, func(session *model.UserSession, _ int) *resp.UserSession {
+		return &resp.UserSession{
+			UserSession: session,
+			Current:     userInfo.SessionId != nil && *userInfo.SessionId == session.ID,
+		}
+	}), nil
+}
+
+func (s *userSvc) RevokeSession(userID, sessionID uuid.UUID) error {
+	revoked, err := s.stg.UserSession(s.ctx).Revoke(userID, sessionID)
+	if err != nil {
+		return errs.Newf(errs.Internal, err, "failed to revoke the session")
+	}
+	if !revoked {
+		return errs.Newf(errs.NotFound, nil, "session not found")
+	}
+	return nil
+}
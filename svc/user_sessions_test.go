@@ -0,0 +1,58 @@
+package svc
+
+import (
+	"testing"
+
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/amahdian/cliplab-be/global/errs"
+)
+
+func TestRefreshRotatesTheToken(t *testing.T) {
+	stg := newFakeStg()
+	s := newTestUserSvc(stg)
+	user := &model.User{Email: "user@cliplab.test"}
+	_ = stg.users.CreateOne(user)
+
+	signedIn, err := s.generateAuthResponse(user, model.SessionDevice{})
+	if err != nil {
+		t.Fatalf("sign in: %v", err)
+	}
+	refreshed, err := s.Refresh(signedIn.RefreshToken, model.SessionDevice{})
+	if err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if refreshed.RefreshToken == signedIn.RefreshToken {
+		t.Errorf("expected a new refresh token")
+	}
+	if _, err = s.Refresh(refreshed.RefreshToken, model.SessionDevice{}); err != nil {
+		t.Errorf("refresh with the rotated token: %v", err)
+	}
+}
+
+func TestRefreshReuseRevokesTheSession(t *testing.T) {
+	stg := newFakeStg()
+	s := newTestUserSvc(stg)
+	user := &model.User{Email: "user@cliplab.test"}
+	_ = stg.users.CreateOne(user)
+
+	signedIn, err := s.generateAuthResponse(user, model.SessionDevice{})
+	if err != nil {
+		t.Fatalf("sign in: %v", err)
+	}
+	refreshed, err := s.Refresh(signedIn.RefreshToken, model.SessionDevice{})
+	if err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+
+	if _, err = s.Refresh(signedIn.RefreshToken, model.SessionDevice{}); !errs.IsCode(err, errs.Unauthenticated) {
+		t.Fatalf("reuse: expected unauthenticated, got %v", err)
+	}
+	for _, session := range stg.sessions.table.rows {
+		if session.Active() {
+			t.Errorf("expected session %s to be revoked", session.ID)
+		}
+	}
+	if _, err = s.Refresh(refreshed.RefreshToken, model.SessionDevice{}); !errs.IsCode(err, errs.Unauthenticated) {
+		t.Errorf("rotated token after reuse: expected unauthenticated, got %v", err)
+	}
+}
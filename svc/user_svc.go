@@ -6,43 +6,93 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"time"
 
+	"github.com/amahdian/cliplab-be/clients/mailer"
+	"github.com/amahdian/cliplab-be/clients/oidc"
 	"github.com/amahdian/cliplab-be/domain/contracts/req"
 	"github.com/amahdian/cliplab-be/domain/contracts/resp"
 	"github.com/amahdian/cliplab-be/domain/model"
 	"github.com/amahdian/cliplab-be/global/env"
+	"github.com/amahdian/cliplab-be/global/errs"
+	"github.com/amahdian/cliplab-be/pkg/logger"
 	"github.com/amahdian/cliplab-be/storage"
 	"github.com/amahdian/cliplab-be/svc/auth"
 	"github.com/amahdian/cliplab-be/svc/utils"
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"github.com/samber/lo"
 )
 
 type UserSvc interface {
-	Register(data *req.Register) (*resp.AuthResponse, error)
-	Login(data *req.Login) (*resp.AuthResponse, error)
-	LoginOauth(data *req.OauthLogin) (*resp.AuthResponse, error)
-	Verify(email, otp string) (string, *model.User, error)
+	Register(data *req.Register, device model.SessionDevice) (*resp.AuthResponse, error)
+	Login(data *req.Login, device model.SessionDevice) (*resp.AuthResponse, error)
+	LoginOauth(data *req.OauthLogin, device model.SessionDevice) (*resp.AuthResponse, error)
+	// OidcAuthorize starts a single sign-on with the OpenID Connect provider, LoginOidc completes it.
+	OidcAuthorize() (*resp.OidcAuthorization, error)
+	LoginOidc(data *req.OidcCallback, device model.SessionDevice) (*resp.AuthResponse, error)
+	Verify(email, otp string, device model.SessionDevice) (*resp.AuthResponse, error)
+	ResendVerification(email string) error
+	ForgotPassword(email string) error
+	ResetPassword(data *req.ResetPassword, device model.SessionDevice) (*resp.AuthResponse, error)
+	ChangePassword(userID uuid.UUID, data *req.ChangePassword, device model.SessionDevice) (*resp.AuthResponse, error)
+	// Refresh rotates the refresh token of a session and returns new tokens for it.
+	// Presenting a refresh token that was already rotated revokes its session.
+	Refresh(refreshToken string, device model.SessionDevice) (*resp.AuthResponse, error)
+	Logout(userInfo *auth.UserInfo) error
+	LogoutAll(userID uuid.UUID) error
+	ListSessions(userInfo *auth.UserInfo) ([]*resp.UserSession, error)
+	RevokeSession(userID, sessionID uuid.UUID) error
+	LinkIdentity(userID uuid.UUID, data *req.OauthLogin) (*model.UserIdentity, error)
+	UnlinkIdentity(userID uuid.UUID, provider model.Provider) error
+	ListIdentities(userID uuid.UUID) (*resp.SignInMethods, error)
+	// LoginTwoFactor completes a sign-in that returned a two-factor challenge.
+	LoginTwoFactor(data *req.TwoFactorLogin, device model.SessionDevice) (*resp.AuthResponse, error)
+	SetupTotp(userID uuid.UUID) (*resp.TotpSetup, error)
+	EnableTotp(userID uuid.UUID, code string) (*resp.RecoveryCodes, error)
+	DisableTotp(userID uuid.UUID, code string) error
+	RegenerateRecoveryCodes(userID uuid.UUID, code string) (*resp.RecoveryCodes, error)
 	Update(userID uuid.UUID, updateData *req.UserUpdate) error
 	Me(userInfo *auth.UserInfo) (*model.User, error)
+	// ExportData returns a ZIP archive of the data of the user, one JSON file per kind of data.
+	ExportData(userID uuid.UUID) ([]byte, error)
+	// SendDeletionCode emails the code confirming the deletion of an account without a password or two-factor authentication.
+	SendDeletionCode(userID uuid.UUID) error
+	// DeleteAccount schedules the deletion of the account after the grace period and signs the user out everywhere.
+	DeleteAccount(userID uuid.UUID, data *req.DeleteAccount) (*resp.AccountDeletion, error)
+	// PurgeDeletedAccounts deletes the accounts whose grace period is over and returns how many were deleted.
+	PurgeDeletedAccounts() (int, error)
 }
 
+const (
+	verificationEmailSubject = "Verify your ClipLab email"
+	verificationEmailBody    = "Your ClipLab verification code is %s.\n\nThe code expires in %d minutes. If you did not sign up, you can ignore this email."
+
+	passwordResetEmailSubject = "Reset your ClipLab password"
+	passwordResetEmailBody    = "Use the link below to choose a new ClipLab password:\n\n%s\n\nThe link expires in %d minutes and can be used once. If you did not ask for it, you can ignore this email."
+	// passwordResetTokenSize is the number of random bytes of a password reset token.
+	passwordResetTokenSize = 32
+)
+
 type userSvc struct {
-	ctx  context.Context
-	stg  storage.PgStorage
-	envs *env.Envs
+	ctx    context.Context
+	stg    storage.PgStorage
+	envs   *env.Envs
+	mailer mailer.Mailer
+	oidc   oidc.Client
 }
 
-func newUserSvc(ctx context.Context, stg storage.PgStorage, envs *env.Envs) UserSvc {
+func newUserSvc(ctx context.Context, stg storage.PgStorage, envs *env.Envs, mailer mailer.Mailer, oidcClient oidc.Client) UserSvc {
 	return &userSvc{
-		ctx:  ctx,
-		stg:  stg,
-		envs: envs,
+		ctx:    ctx,
+		stg:    stg,
+		envs:   envs,
+		mailer: mailer,
+		oidc:   oidcClient,
 	}
 }
 
-func (s *userSvc) Register(data *req.Register) (*resp.AuthResponse, error) {
+func (s *userSvc) Register(data *req.Register, device model.SessionDevice) (*resp.AuthResponse, error) {
 	existingUser, err := s.stg.User(s.ctx).FindByEmail(data.Email)
 	if err != nil {
 		return nil, err
@@ -69,10 +119,15 @@ func (s *userSvc) Register(data *req.Register) (*resp.AuthResponse, error) {
 		return nil, err
 	}
 
-	return s.generateAuthResponse(user)
+	// the user can ask for a new code when this one does not arrive
+	if err = s.sendVerificationCode(user); err != nil {
+		logger.Errorf("Failed to send the verification code to user %s: %v", user.ID, err)
+	}
+
+	return s.generateAuthResponse(user, device)
 }
 
-func (s *userSvc) Login(data *req.Login) (*resp.AuthResponse, error) {
+func (s *userSvc) Login(data *req.Login, device model.SessionDevice) (*resp.AuthResponse, error) {
 	user, err := s.stg.User(s.ctx).FindByEmail(data.Email)
 	if err != nil {
 		return nil, err
@@ -89,74 +144,66 @@ func (s *userSvc) Login(data *req.Login) (*resp.AuthResponse, error) {
 		return nil, errors.New("invalid credentials")
 	}
 
-	return s.generateAuthResponse(user)
+	return s.signIn(user, device)
 }
 
-func (s *userSvc) LoginOauth(data *req.OauthLogin) (*resp.AuthResponse, error) {
-	var email, providerID, name, picture string
+func (s *userSvc) LoginOauth(data *req.OauthLogin, device model.SessionDevice) (*resp.AuthResponse, error) {
+	identity, err := s.verifyOauthToken(data.Provider, data.Token)
+	if err != nil {
+		return nil, err
+	}
+	return s.loginIdentity(identity, device)
+}
 
-	if data.Provider == model.ProviderGoogle {
-		googleInfo, err := s.verifyGoogleToken(data.Token)
+// loginIdentity signs in the user linked to the provider account, linking or creating one by its email the first time.
+func (s *userSvc) loginIdentity(identity *oauthIdentity, device model.SessionDevice) (*resp.AuthResponse, error) {
+	linked, err := s.stg.UserIdentity(s.ctx).FindByProvider(identity.Provider, identity.ProviderId)
+	if err != nil {
+		return nil, err
+	}
+	if linked != nil {
+		user, err := s.stg.User(s.ctx).FindById(linked.UserId)
 		if err != nil {
 			return nil, err
 		}
-		email = googleInfo.Email
-		providerID = googleInfo.Sub
-		name = googleInfo.Name
-		picture = googleInfo.Picture
-	} else {
-		return nil, errors.New("provider not supported yet")
+		return s.signIn(user, device)
+	}
+
+	if identity.Email == "" {
+		return nil, errs.Newf(errs.InvalidArgument, nil, "the %s account did not share an email address", identity.Provider)
 	}
 
-	user, err := s.stg.User(s.ctx).FindByProvider(data.Provider, providerID)
+	user, err := s.stg.User(s.ctx).FindByEmail(identity.Email)
 	if err != nil {
 		return nil, err
 	}
 
-	if user == nil {
-		// Try to find by email if provider ID not found
-		user, err = s.stg.User(s.ctx).FindByEmail(email)
+	if user != nil {
+		if err = s.linkByEmail(user, identity); err != nil {
+			return nil, err
+		}
+	} else {
+		// Create new user
+		user = &model.User{
+			Email:        identity.Email,
+			Name:         lo.EmptyableToPtr(identity.Name),
+			Provider:     identity.Provider,
+			ProfileImage: lo.EmptyableToPtr(identity.Picture),
+		}
+		if identity.EmailVerified {
+			user.VerifiedAt = lo.ToPtr(time.Now())
+		}
+		err = s.stg.User(s.ctx).CreateOne(user)
 		if err != nil {
 			return nil, err
 		}
+	}
 
-		if user != nil {
-			// Link provider to existing account
-			user.ProviderID = &providerID
-			user.Provider = data.Provider
-			if user.Name == nil {
-				user.Name = &name
-			}
-			if user.ProfileImage == nil && picture != "" {
-				user.ProfileImage = &picture
-			}
-			now := time.Now()
-			if user.VerifiedAt == nil {
-				user.VerifiedAt = &now
-			}
-			err = s.stg.User(s.ctx).UpdateOne(user, false)
-			if err != nil {
-				return nil, err
-			}
-		} else {
-			// Create new user
-			now := time.Now()
-			user = &model.User{
-				Email:        email,
-				Name:         &name,
-				Provider:     data.Provider,
-				ProviderID:   &providerID,
-				ProfileImage: &picture,
-				VerifiedAt:   &now,
-			}
-			err = s.stg.User(s.ctx).CreateOne(user)
-			if err != nil {
-				return nil, err
-			}
-		}
+	if err = s.stg.UserIdentity(s.ctx).CreateOne(identity.toModel(user.ID)); err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to link the %s account", identity.Provider)
 	}
 
-	return s.generateAuthResponse(user)
+	return s.signIn(user, device)
 }
 
 type googleTokenInfo struct {
@@ -203,36 +250,155 @@ func (s *userSvc) verifyGoogleToken(token string) (*googleTokenInfo, error) {
 	return &info, nil
 }
 
-func (s *userSvc) Verify(email, otp string) (string, *model.User, error) {
+func (s *userSvc) Verify(email, otp string, device model.SessionDevice) (*resp.AuthResponse, error) {
 	user, err := s.stg.User(s.ctx).FindByEmail(email)
 	if err != nil {
-		return "", nil, err
+		return nil, err
 	}
 
 	if user == nil {
-		return "", nil, errors.New("user not found")
+		return nil, errors.New("user not found")
+	}
+	if user.VerifiedAt != nil {
+		return nil, errs.Newf(errs.FailedPrecondition, nil, "email is already verified")
 	}
 
-	// Logic to verify OTP would go here.
-	// For now, we'll assume it's always successful.
+	if err = s.consumeCode(user, model.CodePurposeEmailVerification, otp); err != nil {
+		return nil, err
+	}
 
 	now := time.Now()
 	user.VerifiedAt = &now
 	err = s.stg.User(s.ctx).UpdateOne(user, false)
 	if err != nil {
-		return "", nil, err
+		return nil, err
+	}
+
+	return s.generateAuthResponse(user, device)
+}
+
+func (s *userSvc) ResendVerification(email string) error {
+	user, err := s.stg.User(s.ctx).FindByEmail(email)
+	if err != nil {
+		return err
+	}
+	// do not tell whether the email is registered
+	if user == nil || user.VerifiedAt != nil {
+		return nil
+	}
+
+	return s.sendVerificationCode(user)
+}
+
+func (s *userSvc) sendVerificationCode(user *model.User) error {
+	code, err := utils.GenerateOtp(s.envs.Otp.Length)
+	if err != nil {
+		return errs.Newf(errs.Internal, err, "failed to generate a code")
+	}
+
+	return s.issueCode(user, model.CodePurposeEmailVerification, code, s.envs.Otp.Ttl, mailer.Message{
+		To:      user.Email,
+		Subject: verificationEmailSubject,
+		Body:    fmt.Sprintf(verificationEmailBody, code, int(s.envs.Otp.Ttl.Minutes())),
+	})
+}
+
+func (s *userSvc) ForgotPassword(email string) error {
+	user, err := s.stg.User(s.ctx).FindByEmail(email)
+	if err != nil {
+		return err
+	}
+	// do not tell whether the email is registered, only accounts with a password can reset it
+	if user == nil || user.Password == nil {
+		return nil
+	}
+
+	token, err := utils.GenerateToken(passwordResetTokenSize)
+	if err != nil {
+		return errs.Newf(errs.Internal, err, "failed to generate a token")
+	}
+	link, err := url.Parse(s.envs.Otp.PasswordResetUrl)
+	if err != nil {
+		return errs.Newf(errs.Internal, err, "invalid password reset url")
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	err = s.issueCode(user, model.CodePurposePasswordReset, token, s.envs.Otp.PasswordResetTtl, mailer.Message{
+		To:      user.Email,
+		Subject: passwordResetEmailSubject,
+		Body:    fmt.Sprintf(passwordResetEmailBody, link.String(), int(s.envs.Otp.PasswordResetTtl.Minutes())),
+	})
+	if errs.IsCode(err, errs.ResourceExhausted) {
+		logger.Warnf("Throttled the password reset of user %s: %v", user.ID, err)
+		return nil
+	}
+	return err
+}
+
+func (s *userSvc) ResetPassword(data *req.ResetPassword, device model.SessionDevice) (*resp.AuthResponse, error) {
+	code, err := s.consumeToken(model.CodePurposePasswordReset, data.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.stg.User(s.ctx).FindById(code.UserId)
+	if err != nil {
+		return nil, errs.Newf(errs.NotFound, err, "user not found")
+	}
+	// the link was delivered to the inbox of the user
+	if user.VerifiedAt == nil {
+		now := time.Now()
+		user.VerifiedAt = &now
+	}
+
+	if err = s.setPassword(user, data.Password); err != nil {
+		return nil, err
+	}
+
+	return s.signIn(user, device)
+}
+
+func (s *userSvc) ChangePassword(userID uuid.UUID, data *req.ChangePassword, device model.SessionDevice) (*resp.AuthResponse, error) {
+	user, err := s.stg.User(s.ctx).FindById(userID)
+	if err != nil {
+		return nil, errs.Newf(errs.NotFound, err, "user not found")
+	}
+	if user.Password == nil {
+		return nil, errs.Newf(errs.FailedPrecondition, nil, "account has no password, use forgot password to set one")
+	}
+	if !utils.CheckPasswordHash(data.CurrentPassword, *user.Password) {
+		return nil, errs.Newf(errs.InvalidArgument, nil, "current password is incorrect")
 	}
 
-	claims := jwt.MapClaims{
-		"id":    user.ID,
-		"name":  user.Name,
-		"email": user.Email,
-		"exp":   time.Now().AddDate(1, 0, 0).Unix(),
+	if err = s.setPassword(user, data.NewPassword); err != nil {
+		return nil, err
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenStr, _ := token.SignedString([]byte(s.envs.Server.JwtSecret))
 
-	return tokenStr, user, nil
+	return s.generateAuthResponse(user, device)
+}
+
+// setPassword saves the new password of the user and revokes every session issued before it.
+func (s *userSvc) setPassword(user *model.User, password string) error {
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		return errs.Newf(errs.Internal, err, "failed to hash the password")
+	}
+
+	now := time.Now()
+	user.Password = &hashedPassword
+	user.SessionsRevokedAt = &now
+	if err = s.stg.User(s.ctx).UpdateOne(user, false); err != nil {
+		return errs.Newf(errs.Internal, err, "failed to save the password")
+	}
+	if err = s.stg.UserSession(s.ctx).RevokeAll(user.ID); err != nil {
+		return errs.Newf(errs.Internal, err, "failed to revoke the sessions")
+	}
+
+	// a reset link left over from before the change must not work anymore
+	_ = s.stg.UserCode(s.ctx).ConsumeAll(user.ID, model.CodePurposePasswordReset)
+	return nil
 }
 
 func (s *userSvc) Update(userID uuid.UUID, updateData *req.UserUpdate) error {
@@ -271,22 +437,3 @@ func (s *userSvc) Me(userInfo *auth.UserInfo) (*model.User, error) {
 
 	return user, nil
 }
-
-func (s *userSvc) generateAuthResponse(user *model.User) (*resp.AuthResponse, error) {
-	claims := jwt.MapClaims{
-		"id":    user.ID,
-		"name":  user.Name,
-		"email": user.Email,
-		"exp":   time.Now().AddDate(1, 0, 0).Unix(),
-	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenStr, err := token.SignedString([]byte(s.envs.Server.JwtSecret))
-	if err != nil {
-		return nil, err
-	}
-
-	return &resp.AuthResponse{
-		Token: tokenStr,
-		User:  user,
-	}, nil
-}
@@ -0,0 +1,148 @@
+package svc
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/amahdian/cliplab-be/clients/mailer"
+	"github.com/amahdian/cliplab-be/domain/contracts/req"
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/amahdian/cliplab-be/global/env"
+	"github.com/amahdian/cliplab-be/global/errs"
+	"github.com/amahdian/cliplab-be/pkg/logger"
+	"github.com/amahdian/cliplab-be/pkg/logger/logging"
+	"github.com/amahdian/cliplab-be/svc/utils"
+	"github.com/samber/lo"
+)
+
+// fakeMailer keeps the emails instead of sending them.
+type fakeMailer struct {
+	sent []mailer.Message
+}
+
+func (m *fakeMailer) Send(msg mailer.Message) error {
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+func newTestUserSvc(stg *fakeStg) *userSvc {
+	logger.Configure(logging.ErrorLevel, logging.TextFormat)
+	envs := &env.Envs{}
+	envs.Server.JwtSecret = "secret"
+	envs.Auth.AccessTokenTtl = 15 * time.Minute
+	envs.Auth.RefreshTokenTtl = time.Hour
+	envs.Otp.Length = 6
+	envs.Otp.Ttl = 15 * time.Minute
+	envs.Otp.MaxAttempts = 3
+	envs.Otp.ResendInterval = time.Minute
+	envs.Otp.MaxPerHour = 5
+	envs.Otp.PasswordResetUrl = "https://cliplab.test/reset-password"
+	envs.Otp.PasswordResetTtl = time.Hour
+	envs.Account.DeletionGracePeriod = 30 * 24 * time.Hour
+	return &userSvc{ctx: context.Background(), stg: stg, envs: envs, mailer: &fakeMailer{}}
+}
+
+// newPasswordUser creates a verified user signing in with the password.
+func newPasswordUser(t *testing.T, stg *fakeStg, password string) *model.User {
+	hash, err := utils.HashPassword(password)
+	if err != nil {
+		t.Fatalf("hash the password: %v", err)
+	}
+	user := &model.User{Email: "user@cliplab.test", Password: &hash, VerifiedAt: lo.ToPtr(time.Now())}
+	_ = stg.users.CreateOne(user)
+	return user
+}
+
+// sentResetToken returns the token of the last password reset link sent.
+func sentResetToken(t *testing.T, s *userSvc) string {
+	sent := s.mailer.(*fakeMailer).sent
+	if len(sent) == 0 {
+		t.Fatalf("no email sent")
+	}
+	link, err := url.Parse(regexp.MustCompile(`https://\S+`).FindString(sent[len(sent)-1].Body))
+	if err != nil {
+		t.Fatalf("parse the link: %v", err)
+	}
+	return link.Query().Get("token")
+}
+
+func TestResetPasswordTokenIsSingleUse(t *testing.T) {
+	stg := newFakeStg()
+	s := newTestUserSvc(stg)
+	user := newPasswordUser(t, stg, "old password")
+
+	if err := s.ForgotPassword(user.Email); err != nil {
+		t.Fatalf("forgot password: %v", err)
+	}
+	token := sentResetToken(t, s)
+
+	if _, err := s.ResetPassword(&req.ResetPassword{Token: token, Password: "new password"}, model.SessionDevice{}); err != nil {
+		t.Fatalf("reset: %v", err)
+	}
+	if !utils.CheckPasswordHash("new password", *user.Password) {
+		t.Errorf("expected the new password to be saved")
+	}
+	if _, err := s.ResetPassword(&req.ResetPassword{Token: token, Password: "another password"}, model.SessionDevice{}); !errs.IsCode(err, errs.InvalidArgument) {
+		t.Errorf("reused token: expected invalid argument, got %v", err)
+	}
+}
+
+func TestChangePasswordRevokesSessions(t *testing.T) {
+	stg := newFakeStg()
+	s := newTestUserSvc(stg)
+	user := newPasswordUser(t, stg, "old password")
+
+	signedIn, err := s.generateAuthResponse(user, model.SessionDevice{})
+	if err != nil {
+		t.Fatalf("sign in: %v", err)
+	}
+	_ = s.ForgotPassword(user.Email)
+	token := sentResetToken(t, s)
+
+	wrong := &req.ChangePassword{CurrentPassword: "wrong password", NewPassword: "new password"}
+	if _, err = s.ChangePassword(user.ID, wrong, model.SessionDevice{}); !errs.IsCode(err, errs.InvalidArgument) {
+		t.Fatalf("wrong current password: expected invalid argument, got %v", err)
+	}
+	changed, err := s.ChangePassword(user.ID, &req.ChangePassword{CurrentPassword: "old password", NewPassword: "new password"}, model.SessionDevice{})
+	if err != nil {
+		t.Fatalf("change: %v", err)
+	}
+
+	if user.SessionsRevokedAt == nil {
+		t.Errorf("expected the tokens issued before the change to be revoked")
+	}
+	active := lo.CountBy(lo.Values(stg.sessions.table.rows), func(session *model.UserSession) bool { return session.Active() })
+	if active != 1 {
+		t.Errorf("expected only the session of the change to stay active, got %d", active)
+	}
+	if _, err = s.Refresh(signedIn.RefreshToken, model.SessionDevice{}); !errs.IsCode(err, errs.Unauthenticated) {
+		t.Errorf("refresh token of a revoked session: expected unauthenticated, got %v", err)
+	}
+	if changed.Token == "" {
+		t.Errorf("expected the current device to get a new session")
+	}
+	if _, err = s.ResetPassword(&req.ResetPassword{Token: token, Password: "another password"}, model.SessionDevice{}); !errs.IsCode(err, errs.InvalidArgument) {
+		t.Errorf("reset link sent before the change: expected invalid argument, got %v", err)
+	}
+}
+
+func TestForgotPasswordThrottlesSilently(t *testing.T) {
+	stg := newFakeStg()
+	s := newTestUserSvc(stg)
+	user := newPasswordUser(t, stg, "old password")
+
+	for range 2 {
+		if err := s.ForgotPassword(user.Email); err != nil {
+			t.Fatalf("forgot password: %v", err)
+		}
+	}
+	if err := s.ForgotPassword("unknown@cliplab.test"); err != nil {
+		t.Fatalf("unknown email: %v", err)
+	}
+	if sent := s.mailer.(*fakeMailer).sent; len(sent) != 1 {
+		t.Errorf("expected a single email, got %d", len(sent))
+	}
+}
@@ -0,0 +1,273 @@
+package svc
+
+import (
+	"time"
+
+	"github.com/amahdian/cliplab-be/domain/contracts/req"
+	"github.com/amahdian/cliplab-be/domain/contracts/resp"
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/amahdian/cliplab-be/global/errs"
+	"github.com/amahdian/cliplab-be/svc/utils"
+	"github.com/google/uuid"
+)
+
+const (
+	totpIssuer = "ClipLab"
+	// twoFactorChallengeTtl is the time the user has to enter the code after the first factor.
+	twoFactorChallengeTtl = 5 * time.Minute
+	// twoFactorAttemptWindow is the period over which the codes entered by a signed-in user are counted.
+	twoFactorAttemptWindow = 15 * time.Minute
+	// maxTwoFactorChallengesPerHour limits the codes that can be guessed with a known password.
+	maxTwoFactorChallengesPerHour = 10
+	twoFactorChallengeSize        = 32
+	recoveryCodeCount             = 10
+)
+
+// signIn starts a session for a user who proved the first factor, or a two-factor challenge
+// when the user enabled two-factor authentication.
+func (s *userSvc) signIn(user *model.User, device model.SessionDevice) (*resp.AuthResponse, error) {
+	if user.TotpEnabledAt == nil {
+		return s.generateAuthResponse(user, device)
+	}
+
+	codes := s.stg.UserCode(s.ctx)
+	issued, err := codes.CountSince(user.ID, model.CodePurposeTwoFactorChallenge, time.Now().Add(-time.Hour))
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to count the challenges")
+	}
+	if issued >= maxTwoFactorChallengesPerHour {
+		return nil, errs.Newf(errs.ResourceExhausted, nil, "too many sign-in attempts, try again later")
+	}
+
+	token, err := utils.GenerateToken(twoFactorChallengeSize)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to generate the challenge")
+	}
+	challenge := &model.UserCode{
+		UserId:    user.ID,
+		Purpose:   model.CodePurposeTwoFactorChallenge,
+		CodeHash:  utils.HashOtp(s.envs.Server.JwtSecret, token),
+		ExpiresAt: time.Now().Add(twoFactorChallengeTtl),
+	}
+	if err = codes.CreateOne(challenge); err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to save the challenge")
+	}
+
+	return &resp.AuthResponse{
+		Challenge: &resp.TwoFactorChallenge{
+			Token:     token,
+			ExpiresAt: challenge.ExpiresAt,
+		},
+	}, nil
+}
+
+func (s *userSvc) LoginTwoFactor(data *req.TwoFactorLogin, device model.SessionDevice) (*resp.AuthResponse, error) {
+	codes := s.stg.UserCode(s.ctx)
+
+	challenge, err := codes.FindActiveByHash(model.CodePurposeTwoFactorChallenge, utils.HashOtp(s.envs.Server.JwtSecret, data.Challenge))
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to find the challenge")
+	}
+	if challenge == nil || time.Now().After(challenge.ExpiresAt) {
+		return nil, errs.Newf(errs.Unauthenticated, nil, "the sign-in has expired, please sign in again")
+	}
+	claimed, err := codes.ClaimAttempt(challenge.ID, s.envs.Otp.MaxAttempts)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to count the attempt")
+	}
+	if !claimed {
+		return nil, errs.Newf(errs.ResourceExhausted, nil, "too many wrong codes, please sign in again")
+	}
+
+	user, err := s.stg.User(s.ctx).FindById(challenge.UserId)
+	if err != nil {
+		return nil, errs.Newf(errs.NotFound, err, "user not found")
+	}
+	if err = s.checkSecondFactor(user, data.Code); err != nil {
+		return nil, err
+	}
+
+	consumed, err := codes.Consume(challenge.ID)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to consume the challenge")
+	}
+	if !consumed {
+		return nil, errs.Newf(errs.Unauthenticated, nil, "the sign-in has expired, please sign in again")
+	}
+
+	return s.generateAuthResponse(user, device)
+}
+
+// confirmSecondFactor checks the second factor of a signed-in user before a sensitive change.
+// Unlike the sign-in, no challenge bounds the attempts, so they are counted per user and window
+// to keep an access token from being enough to guess the code.
+func (s *userSvc) confirmSecondFactor(user *model.User, code string) error {
+	codes := s.stg.UserCode(s.ctx)
+
+	counter, err := codes.FindLatestActive(user.ID, model.CodePurposeTwoFactorAttempts)
+	if err != nil {
+		return errs.Newf(errs.Internal, err, "failed to count the attempts")
+	}
+	if counter == nil || time.Now().After(counter.ExpiresAt) {
+		counter = &model.UserCode{
+			UserId:    user.ID,
+			Purpose:   model.CodePurposeTwoFactorAttempts,
+			ExpiresAt: time.Now().Add(twoFactorAttemptWindow),
+		}
+		if err = codes.CreateOne(counter); err != nil {
+			return errs.Newf(errs.Internal, err, "failed to count the attempts")
+		}
+	}
+	claimed, err := codes.ClaimAttempt(counter.ID, s.envs.Otp.MaxAttempts)
+	if err != nil {
+		return errs.Newf(errs.Internal, err, "failed to count the attempt")
+	}
+	if !claimed {
+		return errs.Newf(errs.ResourceExhausted, nil, "too many codes entered, try again later")
+	}
+
+	return s.checkSecondFactor(user, code)
+}
+
+// checkSecondFactor accepts a code of the authenticator app or an unused recovery code of the user.
+func (s *userSvc) checkSecondFactor(user *model.User, code string) error {
+	if user.TotpEnabledAt == nil || user.TotpSecret == nil {
+		return errs.Newf(errs.FailedPrecondition, nil, "two-factor authentication is not enabled")
+	}
+
+	if utils.IsTotpCode(code) {
+		step, ok := utils.ValidateTotp(*user.TotpSecret, code, time.Now())
+		if !ok {
+			return errs.Newf(errs.InvalidArgument, nil, "invalid code")
+		}
+		claimed, err := s.stg.User(s.ctx).ClaimTotpStep(user.ID, step)
+		if err != nil {
+			return errs.Newf(errs.Internal, err, "failed to save the code")
+		}
+		if !claimed {
+			return errs.Newf(errs.InvalidArgument, nil, "the code was already used, wait for the next one")
+		}
+		return nil
+	}
+
+	hash := utils.HashOtp(s.envs.Server.JwtSecret, utils.NormalizeRecoveryCode(code))
+	claimed, err := s.stg.UserRecoveryCode(s.ctx).Claim(user.ID, hash)
+	if err != nil {
+		return errs.Newf(errs.Internal, err, "failed to use the recovery code")
+	}
+	if !claimed {
+		return errs.Newf(errs.InvalidArgument, nil, "invalid code")
+	}
+	return nil
+}
+
+func (s *userSvc) SetupTotp(userID uuid.UUID) (*resp.TotpSetup, error) {
+	user, err := s.stg.User(s.ctx).FindById(userID)
+	if err != nil {
+		return nil, errs.Newf(errs.NotFound, err, "user not found")
+	}
+	if user.TotpEnabledAt != nil {
+		return nil, errs.Newf(errs.FailedPrecondition, nil, "two-factor authentication is already enabled")
+	}
+	if user.VerifiedAt == nil {
+		return nil, errs.Newf(errs.FailedPrecondition, nil, "verify your email before enabling two-factor authentication")
+	}
+
+	secret, err := utils.GenerateTotpSecret()
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to generate the secret")
+	}
+	user.TotpSecret = &secret
+	if err = s.stg.User(s.ctx).UpdateOne(user, false); err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to save the secret")
+	}
+
+	return &resp.TotpSetup{
+		Secret: secret,
+		Uri:    utils.TotpUri(totpIssuer, user.Email, secret),
+	}, nil
+}
+
+func (s *userSvc) EnableTotp(userID uuid.UUID, code string) (*resp.RecoveryCodes, error) {
+	user, err := s.stg.User(s.ctx).FindById(userID)
+	if err != nil {
+		return nil, errs.Newf(errs.NotFound, err, "user not found")
+	}
+	if user.TotpEnabledAt != nil {
+		return nil, errs.Newf(errs.FailedPrecondition, nil, "two-factor authentication is already enabled")
+	}
+	if user.TotpSecret == nil {
+		return nil, errs.Newf(errs.FailedPrecondition, nil, "set up two-factor authentication first")
+	}
+
+	step, ok := utils.ValidateTotp(*user.TotpSecret, code, time.Now())
+	if !ok {
+		return nil, errs.Newf(errs.InvalidArgument, nil, "invalid code")
+	}
+	now := time.Now()
+	user.TotpEnabledAt = &now
+	user.TotpLastStep = step
+	if err = s.stg.User(s.ctx).UpdateOne(user, false); err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to enable two-factor authentication")
+	}
+
+	return s.replaceRecoveryCodes(user.ID)
+}
+
+func (s *userSvc) DisableTotp(userID uuid.UUID, code string) error {
+	user, err := s.stg.User(s.ctx).FindById(userID)
+	if err != nil {
+		return errs.Newf(errs.NotFound, err, "user not found")
+	}
+	if err = s.confirmSecondFactor(user, code); err != nil {
+		return err
+	}
+
+	user.TotpSecret = nil
+	user.TotpEnabledAt = nil
+	user.TotpLastStep = 0
+	if err = s.stg.User(s.ctx).UpdateOne(user, true); err != nil {
+		return errs.Newf(errs.Internal, err, "failed to disable two-factor authentication")
+	}
+	if err = s.stg.UserRecoveryCode(s.ctx).DeleteByUserId(user.ID); err != nil {
+		return errs.Newf(errs.Internal, err, "failed to delete the recovery codes")
+	}
+	return nil
+}
+
+func (s *userSvc) RegenerateRecoveryCodes(userID uuid.UUID, code string) (*resp.RecoveryCodes, error) {
+	user, err := s.stg.User(s.ctx).FindById(userID)
+	if err != nil {
+		return nil, errs.Newf(errs.NotFound, err, "user not found")
+	}
+	if err = s.confirmSecondFactor(user, code); err != nil {
+		return nil, err
+	}
+
+	return s.replaceRecoveryCodes(user.ID)
+}
+
+// replaceRecoveryCodes deletes the recovery codes of the user and returns new ones.
+func (s *userSvc) replaceRecoveryCodes(userID uuid.UUID) (*resp.RecoveryCodes, error) {
+	res := &resp.RecoveryCodes{}
+	models := make([]*model.UserRecoveryCode, 0, recoveryCodeCount)
+	for range recoveryCodeCount {
+		code, err := utils.GenerateRecoveryCode()
+		if err != nil {
+			return nil, errs.Newf(errs.Internal, err, "failed to generate a recovery code")
+		}
+		res.Codes = append(res.Codes, code)
+		models = append(models, &model.UserRecoveryCode{
+			UserId:   userID,
+			CodeHash: utils.HashOtp(s.envs.Server.JwtSecret, utils.NormalizeRecoveryCode(code)),
+		})
+	}
+
+	if err := s.stg.UserRecoveryCode(s.ctx).DeleteByUserId(userID); err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to delete the recovery codes")
+	}
+	if err := s.stg.UserRecoveryCode(s.ctx).CreateMany(models); err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to save the recovery codes")
+	}
+	return res, nil
+}
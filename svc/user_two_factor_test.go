@@ -0,0 +1,120 @@
+package svc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/amahdian/cliplab-be/domain/contracts/req"
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/amahdian/cliplab-be/global/errs"
+	"github.com/amahdian/cliplab-be/svc/utils"
+	"github.com/samber/lo"
+)
+
+const testPassword = "correct horse battery staple"
+
+// newTwoFactorUser creates a verified user with a password and two-factor authentication enabled.
+func newTwoFactorUser(t *testing.T, stg *fakeStg) *model.User {
+	secret, err := utils.GenerateTotpSecret()
+	if err != nil {
+		t.Fatalf("generate the secret: %v", err)
+	}
+	password, err := utils.HashPassword(testPassword)
+	if err != nil {
+		t.Fatalf("hash the password: %v", err)
+	}
+	user := &model.User{
+		Email:         "user@cliplab.test",
+		Password:      &password,
+		VerifiedAt:    lo.ToPtr(time.Now()),
+		TotpSecret:    &secret,
+		TotpEnabledAt: lo.ToPtr(time.Now()),
+	}
+	_ = stg.users.CreateOne(user)
+	return user
+}
+
+// currentTotpCode returns the code the authenticator app of the user shows now.
+func currentTotpCode(t *testing.T, user *model.User) string {
+	code, err := utils.TotpCode(*user.TotpSecret, time.Now().Unix()/30)
+	if err != nil {
+		t.Fatalf("totp code: %v", err)
+	}
+	return code
+}
+
+// passwordLogin signs in with the password and returns the two-factor challenge.
+func passwordLogin(t *testing.T, s *userSvc, user *model.User) string {
+	res, err := s.Login(&req.Login{Email: user.Email, Password: testPassword}, model.SessionDevice{})
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	if res.Challenge == nil || res.Token != "" || res.RefreshToken != "" {
+		t.Fatalf("expected a challenge without tokens, got %+v", res)
+	}
+	return res.Challenge.Token
+}
+
+func TestLoginTwoFactor(t *testing.T) {
+	stg := newFakeStg()
+	s := newTestUserSvc(stg)
+	user := newTwoFactorUser(t, stg)
+	code := currentTotpCode(t, user)
+
+	signedIn, err := s.LoginTwoFactor(&req.TwoFactorLogin{Challenge: passwordLogin(t, s, user), Code: code}, model.SessionDevice{})
+	if err != nil {
+		t.Fatalf("second factor: %v", err)
+	}
+	if signedIn.Token == "" || signedIn.RefreshToken == "" {
+		t.Errorf("expected the tokens, got %+v", signedIn)
+	}
+
+	_, err = s.LoginTwoFactor(&req.TwoFactorLogin{Challenge: passwordLogin(t, s, user), Code: code}, model.SessionDevice{})
+	if !errs.IsCode(err, errs.InvalidArgument) {
+		t.Errorf("replayed code: expected invalid argument, got %v", err)
+	}
+}
+
+func TestLoginTwoFactorWithRecoveryCode(t *testing.T) {
+	stg := newFakeStg()
+	s := newTestUserSvc(stg)
+	user := newTwoFactorUser(t, stg)
+	recovery, err := s.replaceRecoveryCodes(user.ID)
+	if err != nil {
+		t.Fatalf("recovery codes: %v", err)
+	}
+
+	challenge := passwordLogin(t, s, user)
+	if _, err = s.LoginTwoFactor(&req.TwoFactorLogin{Challenge: challenge, Code: recovery.Codes[0]}, model.SessionDevice{}); err != nil {
+		t.Fatalf("recovery code: %v", err)
+	}
+	if _, err = s.LoginTwoFactor(&req.TwoFactorLogin{Challenge: challenge, Code: currentTotpCode(t, user)}, model.SessionDevice{}); !errs.IsCode(err, errs.Unauthenticated) {
+		t.Errorf("used challenge: expected unauthenticated, got %v", err)
+	}
+
+	_, err = s.LoginTwoFactor(&req.TwoFactorLogin{Challenge: passwordLogin(t, s, user), Code: recovery.Codes[0]}, model.SessionDevice{})
+	if !errs.IsCode(err, errs.InvalidArgument) {
+		t.Errorf("used recovery code: expected invalid argument, got %v", err)
+	}
+}
+
+func TestDisableTotpLimitsAttempts(t *testing.T) {
+	stg := newFakeStg()
+	s := newTestUserSvc(stg)
+	user := newTwoFactorUser(t, stg)
+
+	for range s.envs.Otp.MaxAttempts {
+		if err := s.DisableTotp(user.ID, "wrong-recovery-code"); !errs.IsCode(err, errs.InvalidArgument) {
+			t.Fatalf("wrong code: expected invalid argument, got %v", err)
+		}
+	}
+	if err := s.DisableTotp(user.ID, currentTotpCode(t, user)); !errs.IsCode(err, errs.ResourceExhausted) {
+		t.Fatalf("after too many codes: expected resource exhausted, got %v", err)
+	}
+	if user.TotpEnabledAt == nil {
+		t.Errorf("expected two-factor authentication to stay enabled")
+	}
+	if _, err := s.RegenerateRecoveryCodes(user.ID, currentTotpCode(t, user)); !errs.IsCode(err, errs.ResourceExhausted) {
+		t.Errorf("regenerate: expected resource exhausted, got %v", err)
+	}
+}
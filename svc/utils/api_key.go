@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+)
+
+const (
+	// apiKeyMarker starts every api key, so leaked keys are easy to recognize.
+	apiKeyMarker = "clp_"
+	// apiKeyPrefixSize keeps the prefixes, which are unique, from colliding as the keys pile up.
+	apiKeyPrefixSize = 8
+	// legacyApiKeyPrefixSize is the prefix size of the keys issued before it was widened.
+	legacyApiKeyPrefixSize = 4
+	apiKeySecretSize       = 32
+)
+
+// GenerateApiKey returns a new api key and the prefix it is looked up by, e.g. "clp_1a2b3c4d5e6f7a8b_<secret>".
+func GenerateApiKey() (key, prefix string, err error) {
+	b := make([]byte, apiKeyPrefixSize)
+	if _, err = rand.Read(b); err != nil {
+		return "", "", err
+	}
+	prefix = hex.EncodeToString(b)
+
+	secret, err := GenerateToken(apiKeySecretSize)
+	if err != nil {
+		return "", "", err
+	}
+	return apiKeyMarker + prefix + "_" + secret, prefix, nil
+}
+
+// ParseApiKey returns the prefix of the api key, or false when it is not shaped like one.
+func ParseApiKey(key string) (string, bool) {
+	rest, ok := strings.CutPrefix(key, apiKeyMarker)
+	if !ok {
+		return "", false
+	}
+	for _, size := range []int{apiKeyPrefixSize, legacyApiKeyPrefixSize} {
+		prefixLength := hex.EncodedLen(size)
+		if len(rest) > prefixLength+1 && rest[prefixLength] == '_' {
+			return rest[:prefixLength], true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,26 @@
+package utils
+
+import "testing"
+
+func TestParseApiKey(t *testing.T) {
+	key, prefix, err := GenerateApiKey()
+	if err != nil {
+		t.Fatalf("GenerateApiKey: %v", err)
+	}
+	if got, ok := ParseApiKey(key); !ok || got != prefix {
+		t.Errorf("ParseApiKey(%q) = %q, %v, want %q", key, got, ok, prefix)
+	}
+
+	if len(prefix) != 16 {
+		t.Errorf("expected a prefix of 16 characters, got %q", prefix)
+	}
+	if got, ok := ParseApiKey("clp_1a2b3c4d_secret"); !ok || got != "1a2b3c4d" {
+		t.Errorf("ParseApiKey of a legacy key = %q, %v", got, ok)
+	}
+
+	for _, invalid := range []string{"", "clp_", "clp_1a2b3c4d", "clp_1a2b3c4d_", "clp_1a2b3c4dx_secret", "clp_1a2b3c4d5e6f7a8b_", "sk_1a2b3c4d_secret"} {
+		if _, ok := ParseApiKey(invalid); ok {
+			t.Errorf("ParseApiKey(%q) accepted an invalid key", invalid)
+		}
+	}
+}
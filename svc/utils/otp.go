@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"math/big"
+)
+
+// GenerateOtp returns a random numeric code of the given length.
+func GenerateOtp(length int) (string, error) {
+	digits := make([]byte, length)
+	for i := range digits {
+		n, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		digits[i] = byte('0' + n.Int64())
+	}
+	return string(digits), nil
+}
+
+// GenerateToken returns a random url safe token of the given number of bytes.
+func GenerateToken(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashOtp returns the hex encoded HMAC-SHA256 of the code. Codes are short,
+// so they are keyed with a server secret instead of being hashed alone.
+func HashOtp(secret, code string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(code))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// CheckOtpHash compares a code with its HMAC in constant time.
+func CheckOtpHash(secret, code, hash string) bool {
+	return hmac.Equal([]byte(HashOtp(secret, code)), []byte(hash))
+}
@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA-1 key of the RFC 6238 test vectors, "12345678901234567890" in base32.
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTotpCodeMatchesRfc6238(t *testing.T) {
+	// the last six digits of the eight digit codes of RFC 6238 appendix B
+	vectors := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1111111111: "050471",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+	for unix, want := range vectors {
+		got, err := TotpCode(rfc6238Secret, unix/totpPeriod)
+		if err != nil {
+			t.Fatalf("TotpCode: %v", err)
+		}
+		if got != want {
+			t.Errorf("code at %d = %s, want %s", unix, got, want)
+		}
+	}
+}
+
+func TestValidateTotpAcceptsAdjacentSteps(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	step := now.Unix() / totpPeriod
+
+	for _, offset := range []int64{-1, 0, 1} {
+		code, _ := TotpCode(rfc6238Secret, step+offset)
+		got, ok := ValidateTotp(rfc6238Secret, code, now)
+		if !ok || got != step+offset {
+			t.Errorf("offset %d: got step %d, ok %v", offset, got, ok)
+		}
+	}
+
+	code, _ := TotpCode(rfc6238Secret, step+2)
+	if _, ok := ValidateTotp(rfc6238Secret, code, now); ok {
+		t.Error("expected a code two steps ahead to be rejected")
+	}
+}
+
+func TestRecoveryCode(t *testing.T) {
+	code, err := GenerateRecoveryCode()
+	if err != nil {
+		t.Fatalf("GenerateRecoveryCode: %v", err)
+	}
+	if len(code) != recoveryCodeLength+1 || code[recoveryCodeLength/2] != '-' {
+		t.Errorf("unexpected recovery code %q", code)
+	}
+	if IsTotpCode(code) {
+		t.Errorf("recovery code %q looks like a TOTP code", code)
+	}
+	if got := NormalizeRecoveryCode(" " + strings.ToUpper(code)); got != strings.ReplaceAll(code, "-", "") {
+		t.Errorf("NormalizeRecoveryCode = %q", got)
+	}
+}
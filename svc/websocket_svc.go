@@ -15,6 +15,9 @@ type WebSocketSvc interface {
 	Unregister(userID uuid.UUID)
 }
 
+// WsEventAnalysisSection is sent when a section of a streamed analysis is ready.
+const WsEventAnalysisSection = "analysis_section"
+
 var connections map[uuid.UUID]*websocket.Conn
 
 type webSocketSvc struct {
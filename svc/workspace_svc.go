@@ -0,0 +1,417 @@
+package svc
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/amahdian/cliplab-be/clients/mailer"
+	"github.com/amahdian/cliplab-be/domain/contracts/req"
+	"github.com/amahdian/cliplab-be/domain/contracts/resp"
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/amahdian/cliplab-be/global/env"
+	"github.com/amahdian/cliplab-be/global/errs"
+	"github.com/amahdian/cliplab-be/storage"
+	"github.com/amahdian/cliplab-be/svc/utils"
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+)
+
+const (
+	workspaceInviteEmailSubject = "You are invited to a ClipLab workspace"
+	workspaceInviteEmailBody    = "You are invited to join the workspace \"%s\" on ClipLab as %s. Use the link below to accept:\n\n%s\n\nThe link expires in %d days. If you did not expect it, you can ignore this email."
+	// workspaceInviteTokenSize is the number of random bytes of an invitation token.
+	workspaceInviteTokenSize = 32
+)
+
+type WorkspaceSvc interface {
+	Create(userID uuid.UUID, data *req.CreateWorkspace) (*resp.Workspace, error)
+	List(userID uuid.UUID) ([]*resp.Workspace, error)
+	Get(userID, workspaceID uuid.UUID) (*resp.Workspace, error)
+	Update(userID, workspaceID uuid.UUID, data *req.UpdateWorkspace) (*resp.Workspace, error)
+	// Delete deletes the workspace, its analyzed posts go back to the personal libraries of the members who analyzed them.
+	Delete(userID, workspaceID uuid.UUID) error
+
+	ListMembers(userID, workspaceID uuid.UUID) ([]*model.WorkspaceMember, error)
+	UpdateMemberRole(userID, workspaceID, memberID uuid.UUID, role model.WorkspaceRole) (*model.WorkspaceMember, error)
+	// RemoveMember removes a member of the workspace, or lets the user leave it when memberID is the user.
+	RemoveMember(userID, workspaceID, memberID uuid.UUID) error
+
+	// Invite emails a link to join the workspace, replacing the pending invitation of the email.
+	Invite(userID, workspaceID uuid.UUID, data *req.InviteWorkspaceMember) (*model.WorkspaceInvitation, error)
+	ListInvitations(userID, workspaceID uuid.UUID) ([]*model.WorkspaceInvitation, error)
+	RevokeInvitation(userID, workspaceID, invitationID uuid.UUID) error
+	// AcceptInvitation adds the user to the workspace, the invitation must have been sent to the verified email of the user.
+	AcceptInvitation(userID uuid.UUID, token string) (*resp.Workspace, error)
+}
+
+type workspaceSvc struct {
+	ctx    context.Context
+	stg    storage.PgStorage
+	envs   *env.Envs
+	mailer mailer.Mailer
+}
+
+func newWorkspaceSvc(ctx context.Context, stg storage.PgStorage, envs *env.Envs, mailer mailer.Mailer) WorkspaceSvc {
+	return &workspaceSvc{
+		ctx:    ctx,
+		stg:    stg,
+		envs:   envs,
+		mailer: mailer,
+	}
+}
+
+func (s *workspaceSvc) Create(userID uuid.UUID, data *req.CreateWorkspace) (*resp.Workspace, error) {
+	name := strings.TrimSpace(data.Name)
+	if name == "" {
+		return nil, errs.Newf(errs.InvalidArgument, nil, "workspace name is empty")
+	}
+
+	workspace := &model.Workspace{
+		Name:      name,
+		CreatedBy: &userID,
+	}
+	if err := s.stg.Workspace(s.ctx).CreateOne(workspace); err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to save the workspace")
+	}
+	member := &model.WorkspaceMember{
+		WorkspaceId: workspace.ID,
+		UserId:      userID,
+		Role:        model.WorkspaceRoleOwner,
+	}
+	if err := s.stg.WorkspaceMember(s.ctx).CreateOne(member); err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to save the workspace owner")
+	}
+
+	return &resp.Workspace{Workspace: workspace, Role: member.Role}, nil
+}
+
+func (s *workspaceSvc) List(userID uuid.UUID) ([]*resp.Workspace, error) {
+	members, err := s.stg.WorkspaceMember(s.ctx).ListByUserId(userID)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to list the workspaces")
+	}
+	return lo.Map(members, func(m *model.WorkspaceMember, _ int) *resp.Workspace {
+		return &resp.Workspace{Workspace: m.Workspace, Role: m.Role}
+	}), nil
+}
+
+func (s *workspaceSvc) Get(userID, workspaceID uuid.UUID) (*resp.Workspace, error) {
+	member, err := requireWorkspaceRole(s.ctx, s.stg, userID, workspaceID, model.WorkspaceRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+	return &resp.Workspace{Workspace: member.Workspace, Role: member.Role}, nil
+}
+
+func (s *workspaceSvc) Update(userID, workspaceID uuid.UUID, data *req.UpdateWorkspace) (*resp.Workspace, error) {
+	member, err := requireWorkspaceRole(s.ctx, s.stg, userID, workspaceID, model.WorkspaceRoleOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	workspace := member.Workspace
+	if data.Name != nil {
+		name := strings.TrimSpace(*data.Name)
+		if name == "" {
+			return nil, errs.Newf(errs.InvalidArgument, nil, "workspace name is empty")
+		}
+		workspace.Name = name
+	}
+	if data.RequireTwoFactor != nil {
+		if *data.RequireTwoFactor && !workspace.RequireTwoFactor {
+			// the owner would otherwise lock themselves out of the workspace
+			user, err := s.stg.User(s.ctx).FindById(userID)
+			if err != nil {
+				return nil, errs.Newf(errs.NotFound, err, "user not found")
+			}
+			if user.TotpEnabledAt == nil {
+				return nil, errs.Newf(errs.FailedPrecondition, nil, "enable two-factor authentication before requiring it in the workspace")
+			}
+		}
+		workspace.RequireTwoFactor = *data.RequireTwoFactor
+	}
+
+	if err = s.stg.Workspace(s.ctx).UpdateOne(workspace, true); err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to update the workspace")
+	}
+	return &resp.Workspace{Workspace: workspace, Role: member.Role}, nil
+}
+
+func (s *workspaceSvc) Delete(userID, workspaceID uuid.UUID) error {
+	if _, err := requireWorkspaceRole(s.ctx, s.stg, userID, workspaceID, model.WorkspaceRoleOwner); err != nil {
+		return err
+	}
+	if err := s.stg.Workspace(s.ctx).DeleteById(workspaceID); err != nil {
+		return errs.Newf(errs.Internal, err, "failed to delete the workspace")
+	}
+	return nil
+}
+
+func (s *workspaceSvc) ListMembers(userID, workspaceID uuid.UUID) ([]*model.WorkspaceMember, error) {
+	if _, err := requireWorkspaceRole(s.ctx, s.stg, userID, workspaceID, model.WorkspaceRoleViewer); err != nil {
+		return nil, err
+	}
+	members, err := s.stg.WorkspaceMember(s.ctx).ListByWorkspaceId(workspaceID)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to list the members")
+	}
+	return members, nil
+}
+
+func (s *workspaceSvc) UpdateMemberRole(userID, workspaceID, memberID uuid.UUID, role model.WorkspaceRole) (*model.WorkspaceMember, error) {
+	if _, err := requireWorkspaceRole(s.ctx, s.stg, userID, workspaceID, model.WorkspaceRoleOwner); err != nil {
+		return nil, err
+	}
+
+	members := s.stg.WorkspaceMember(s.ctx)
+	member, err := members.Find(workspaceID, memberID)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to find the member")
+	}
+	if member == nil {
+		return nil, errs.Newf(errs.NotFound, nil, "member not found")
+	}
+	if member.Role == role {
+		return member, nil
+	}
+	if member.Role == model.WorkspaceRoleOwner {
+		if err = s.ensureAnotherOwner(workspaceID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = members.UpdateRole(workspaceID, memberID, role); err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to update the member")
+	}
+	member.Role = role
+	return member, nil
+}
+
+func (s *workspaceSvc) RemoveMember(userID, workspaceID, memberID uuid.UUID) error {
+	members := s.stg.WorkspaceMember(s.ctx)
+
+	var member *model.WorkspaceMember
+	var err error
+	if memberID == userID {
+		// leaving is allowed even when the workspace requires two-factor authentication
+		member, err = members.Find(workspaceID, userID)
+		if err != nil {
+			return errs.Newf(errs.Internal, err, "failed to find the member")
+		}
+		if member == nil {
+			return errs.Newf(errs.NotFound, nil, "workspace not found")
+		}
+	} else {
+		if _, err = requireWorkspaceRole(s.ctx, s.stg, userID, workspaceID, model.WorkspaceRoleOwner); err != nil {
+			return err
+		}
+		member, err = members.Find(workspaceID, memberID)
+		if err != nil {
+			return errs.Newf(errs.Internal, err, "failed to find the member")
+		}
+		if member == nil {
+			return errs.Newf(errs.NotFound, nil, "member not found")
+		}
+	}
+
+	if member.Role == model.WorkspaceRoleOwner {
+		if err = s.ensureAnotherOwner(workspaceID); err != nil {
+			return err
+		}
+	}
+	if err = members.Delete(workspaceID, memberID); err != nil {
+		return errs.Newf(errs.Internal, err, "failed to remove the member")
+	}
+	return nil
+}
+
+// ensureAnotherOwner keeps the last owner of a workspace from being demoted or removed.
+func (s *workspaceSvc) ensureAnotherOwner(workspaceID uuid.UUID) error {
+	owners, err := s.stg.WorkspaceMember(s.ctx).CountByRole(workspaceID, model.WorkspaceRoleOwner)
+	if err != nil {
+		return errs.Newf(errs.Internal, err, "failed to count the owners")
+	}
+	if owners <= 1 {
+		return errs.Newf(errs.FailedPrecondition, nil, "the workspace needs another owner first, or can be deleted instead")
+	}
+	return nil
+}
+
+func (s *workspaceSvc) Invite(userID, workspaceID uuid.UUID, data *req.InviteWorkspaceMember) (*model.WorkspaceInvitation, error) {
+	member, err := requireWorkspaceRole(s.ctx, s.stg, userID, workspaceID, model.WorkspaceRoleOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	email := strings.ToLower(strings.TrimSpace(data.Email))
+	invitee, err := s.stg.User(s.ctx).FindByEmail(email)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to find the user")
+	}
+	if invitee != nil {
+		existing, err := s.stg.WorkspaceMember(s.ctx).Find(workspaceID, invitee.ID)
+		if err != nil {
+			return nil, errs.Newf(errs.Internal, err, "failed to find the member")
+		}
+		if existing != nil {
+			return nil, errs.Newf(errs.AlreadyExists, nil, "%s is already a member of the workspace", email)
+		}
+	}
+
+	token, err := utils.GenerateToken(workspaceInviteTokenSize)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to generate a token")
+	}
+	link, err := url.Parse(s.envs.Workspace.InviteUrl)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "invalid workspace invitation url")
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	invitations := s.stg.WorkspaceInvitation(s.ctx)
+	if err = invitations.DeletePending(workspaceID, email); err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to replace the previous invitation")
+	}
+	invitation := &model.WorkspaceInvitation{
+		WorkspaceId: workspaceID,
+		Email:       email,
+		Role:        data.Role,
+		TokenHash:   utils.HashOtp(s.envs.Server.JwtSecret, token),
+		InvitedBy:   &userID,
+		ExpiresAt:   time.Now().Add(s.envs.Workspace.InviteTtl),
+	}
+	if err = invitations.CreateOne(invitation); err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to save the invitation")
+	}
+
+	err = s.mailer.Send(mailer.Message{
+		To:      email,
+		Subject: workspaceInviteEmailSubject,
+		Body: fmt.Sprintf(workspaceInviteEmailBody, member.Workspace.Name, data.Role, link.String(),
+			int(s.envs.Workspace.InviteTtl.Hours()/24)),
+	})
+	if err != nil {
+		return nil, errs.Newf(errs.Unavailable, err, "failed to send the invitation")
+	}
+	return invitation, nil
+}
+
+func (s *workspaceSvc) ListInvitations(userID, workspaceID uuid.UUID) ([]*model.WorkspaceInvitation, error) {
+	if _, err := requireWorkspaceRole(s.ctx, s.stg, userID, workspaceID, model.WorkspaceRoleOwner); err != nil {
+		return nil, err
+	}
+	invitations, err := s.stg.WorkspaceInvitation(s.ctx).ListPendingByWorkspaceId(workspaceID)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to list the invitations")
+	}
+	return invitations, nil
+}
+
+func (s *workspaceSvc) RevokeInvitation(userID, workspaceID, invitationID uuid.UUID) error {
+	if _, err := requireWorkspaceRole(s.ctx, s.stg, userID, workspaceID, model.WorkspaceRoleOwner); err != nil {
+		return err
+	}
+
+	invitations := s.stg.WorkspaceInvitation(s.ctx)
+	invitation, err := invitations.FindById(invitationID)
+	if err != nil || invitation.WorkspaceId != workspaceID || invitation.AcceptedAt != nil {
+		return errs.Newf(errs.NotFound, err, "invitation not found")
+	}
+	if err = invitations.DeleteById(invitationID); err != nil {
+		return errs.Newf(errs.Internal, err, "failed to revoke the invitation")
+	}
+	return nil
+}
+
+func (s *workspaceSvc) AcceptInvitation(userID uuid.UUID, token string) (*resp.Workspace, error) {
+	invitations := s.stg.WorkspaceInvitation(s.ctx)
+	invitation, err := invitations.FindPendingByHash(utils.HashOtp(s.envs.Server.JwtSecret, token))
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to find the invitation")
+	}
+	if invitation == nil || time.Now().After(invitation.ExpiresAt) {
+		return nil, errs.Newf(errs.InvalidArgument, nil, "the invitation has expired, ask for a new one")
+	}
+
+	user, err := s.stg.User(s.ctx).FindById(userID)
+	if err != nil {
+		return nil, errs.Newf(errs.NotFound, err, "user not found")
+	}
+	if user.VerifiedAt == nil {
+		return nil, errs.Newf(errs.FailedPrecondition, nil, "verify your email before joining a workspace")
+	}
+	if !strings.EqualFold(user.Email, invitation.Email) {
+		return nil, errs.Newf(errs.PermissionDenied, nil, "the invitation was sent to another email")
+	}
+
+	members := s.stg.WorkspaceMember(s.ctx)
+	member, err := members.Find(invitation.WorkspaceId, userID)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to find the member")
+	}
+	if member == nil {
+		err = members.CreateOne(&model.WorkspaceMember{
+			WorkspaceId: invitation.WorkspaceId,
+			UserId:      userID,
+			Role:        invitation.Role,
+		})
+		if err != nil {
+			return nil, errs.Newf(errs.Internal, err, "failed to add the member")
+		}
+		if member, err = members.Find(invitation.WorkspaceId, userID); err != nil || member == nil {
+			return nil, errs.Newf(errs.Internal, err, "failed to find the member")
+		}
+	}
+
+	invitation.AcceptedAt = lo.ToPtr(time.Now())
+	if err = invitations.UpdateOne(invitation, false); err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to accept the invitation")
+	}
+	return &resp.Workspace{Workspace: member.Workspace, Role: member.Role}, nil
+}
+
+// requireWorkspaceRole returns the membership of the user in the workspace when its role includes the required one.
+// Workspaces the user is not a member of are reported as not found.
+func requireWorkspaceRole(ctx context.Context, stg storage.PgStorage, userId, workspaceId uuid.UUID, role model.WorkspaceRole) (*model.WorkspaceMember, error) {
+	member, err := stg.WorkspaceMember(ctx).Find(workspaceId, userId)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, err, "failed to find the membership")
+	}
+	if member == nil || member.Workspace == nil {
+		return nil, errs.Newf(errs.NotFound, nil, "workspace not found")
+	}
+
+	if member.Workspace.RequireTwoFactor {
+		user, err := stg.User(ctx).FindById(userId)
+		if err != nil {
+			return nil, errs.Newf(errs.NotFound, err, "user not found")
+		}
+		if user.TotpEnabledAt == nil {
+			return nil, errs.Newf(errs.FailedPrecondition, nil, "the workspace requires two-factor authentication, enable it to continue")
+		}
+	}
+
+	if !member.Role.Allows(role) {
+		return nil, errs.Newf(errs.PermissionDenied, nil, "your role in the workspace does not allow this")
+	}
+	return member, nil
+}
+
+// resolveLibraryOwner returns the library of the workspace after checking the role of the user in it,
+// or the personal library of the user when no workspace is given.
+func resolveLibraryOwner(ctx context.Context, stg storage.PgStorage, userId uuid.UUID, workspaceId *uuid.UUID, role model.WorkspaceRole) (model.LibraryOwner, error) {
+	owner := model.LibraryOwner{UserId: userId}
+	if workspaceId == nil {
+		return owner, nil
+	}
+	if _, err := requireWorkspaceRole(ctx, stg, userId, *workspaceId, role); err != nil {
+		return owner, err
+	}
+	owner.WorkspaceId = workspaceId
+	return owner, nil
+}
@@ -0,0 +1,145 @@
+package svc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/amahdian/cliplab-be/domain/model"
+	"github.com/amahdian/cliplab-be/global/env"
+	"github.com/amahdian/cliplab-be/global/errs"
+	"github.com/amahdian/cliplab-be/svc/auth"
+	"github.com/amahdian/cliplab-be/svc/utils"
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+)
+
+// newTestWorkspace creates a workspace with the user as a member of the role.
+func newTestWorkspace(stg *fakeStg, userId uuid.UUID, role model.WorkspaceRole) *model.Workspace {
+	workspace := &model.Workspace{Name: "team"}
+	_ = stg.workspaces.CreateOne(workspace)
+	_ = stg.members.CreateOne(&model.WorkspaceMember{WorkspaceId: workspace.ID, Workspace: workspace, UserId: userId, Role: role})
+	return workspace
+}
+
+func TestRequireWorkspaceRole(t *testing.T) {
+	stg := newFakeStg()
+	viewer := &model.User{Email: "viewer@cliplab.test"}
+	_ = stg.users.CreateOne(viewer)
+	workspace := newTestWorkspace(stg, viewer.ID, model.WorkspaceRoleViewer)
+
+	cases := []struct {
+		name   string
+		userId uuid.UUID
+		role   model.WorkspaceRole
+		code   errs.ErrorCode
+	}{
+		{"member", viewer.ID, model.WorkspaceRoleViewer, errs.OK},
+		{"non-member", uuid.New(), model.WorkspaceRoleViewer, errs.NotFound},
+		{"role too low", viewer.ID, model.WorkspaceRoleEditor, errs.PermissionDenied},
+	}
+	for _, c := range cases {
+		_, err := requireWorkspaceRole(context.Background(), stg, c.userId, workspace.ID, c.role)
+		if !errs.IsCode(err, c.code) {
+			t.Errorf("%s: expected code %d, got %v", c.name, c.code, err)
+		}
+	}
+
+	workspace.RequireTwoFactor = true
+	if _, err := requireWorkspaceRole(context.Background(), stg, viewer.ID, workspace.ID, model.WorkspaceRoleViewer); !errs.IsCode(err, errs.FailedPrecondition) {
+		t.Errorf("without two-factor: expected failed precondition, got %v", err)
+	}
+	viewer.TotpEnabledAt = lo.ToPtr(time.Now())
+	if _, err := requireWorkspaceRole(context.Background(), stg, viewer.ID, workspace.ID, model.WorkspaceRoleViewer); err != nil {
+		t.Errorf("with two-factor: unexpected error %v", err)
+	}
+}
+
+func TestResolveLibraryOwner(t *testing.T) {
+	stg := newFakeStg()
+	userId := uuid.New()
+	workspace := newTestWorkspace(stg, userId, model.WorkspaceRoleViewer)
+
+	owner, err := resolveLibraryOwner(context.Background(), stg, userId, nil, model.WorkspaceRoleViewer)
+	if err != nil || owner.UserId != userId || owner.WorkspaceId != nil {
+		t.Errorf("personal library: unexpected owner %+v, %v", owner, err)
+	}
+	owner, err = resolveLibraryOwner(context.Background(), stg, userId, &workspace.ID, model.WorkspaceRoleViewer)
+	if err != nil || owner.WorkspaceId == nil || *owner.WorkspaceId != workspace.ID {
+		t.Errorf("workspace library: unexpected owner %+v, %v", owner, err)
+	}
+	if _, err = resolveLibraryOwner(context.Background(), stg, uuid.New(), &workspace.ID, model.WorkspaceRoleViewer); !errs.IsCode(err, errs.NotFound) {
+		t.Errorf("non-member: expected not found, got %v", err)
+	}
+}
+
+func TestAcceptInvitation(t *testing.T) {
+	stg := newFakeStg()
+	envs := &env.Envs{}
+	envs.Server.JwtSecret = "secret"
+	s := newWorkspaceSvc(context.Background(), stg, envs, &fakeMailer{})
+
+	invited := &model.User{Email: "invited@cliplab.test", VerifiedAt: lo.ToPtr(time.Now())}
+	other := &model.User{Email: "other@cliplab.test", VerifiedAt: lo.ToPtr(time.Now())}
+	_ = stg.users.CreateOne(invited)
+	_ = stg.users.CreateOne(other)
+	workspace := newTestWorkspace(stg, uuid.New(), model.WorkspaceRoleOwner)
+
+	invite := func(token string, expiresAt time.Time) {
+		_ = stg.invitations.CreateOne(&model.WorkspaceInvitation{
+			WorkspaceId: workspace.ID,
+			Email:       "Invited@cliplab.test",
+			Role:        model.WorkspaceRoleEditor,
+			TokenHash:   utils.HashOtp(envs.Server.JwtSecret, token),
+			ExpiresAt:   expiresAt,
+		})
+	}
+	invite("expired", time.Now().Add(-time.Minute))
+	invite("pending", time.Now().Add(time.Hour))
+
+	if _, err := s.AcceptInvitation(invited.ID, "expired"); !errs.IsCode(err, errs.InvalidArgument) {
+		t.Errorf("expired invitation: expected invalid argument, got %v", err)
+	}
+	if _, err := s.AcceptInvitation(other.ID, "pending"); !errs.IsCode(err, errs.PermissionDenied) {
+		t.Errorf("wrong email: expected permission denied, got %v", err)
+	}
+
+	accepted, err := s.AcceptInvitation(invited.ID, "pending")
+	if err != nil {
+		t.Fatalf("accept: %v", err)
+	}
+	if accepted.Role != model.WorkspaceRoleEditor {
+		t.Errorf("expected to join as an editor, got %s", accepted.Role)
+	}
+	if _, err = s.AcceptInvitation(invited.ID, "pending"); !errs.IsCode(err, errs.InvalidArgument) {
+		t.Errorf("accepted invitation: expected invalid argument, got %v", err)
+	}
+}
+
+func TestGetAnalyzeResultOfWorkspace(t *testing.T) {
+	stg := newFakeStg()
+	s := newAnalyzeSvc(context.Background(), stg, &env.Envs{}, nil, nil)
+	memberId := uuid.New()
+	workspace := newTestWorkspace(stg, memberId, model.WorkspaceRoleViewer)
+	request := &model.AnalyzeRequest{WorkspaceId: &workspace.ID, Status: model.RequestStatusPending}
+	_ = stg.requests.CreateOne(request)
+
+	cases := []struct {
+		name string
+		user auth.UserInfo
+		code errs.ErrorCode
+	}{
+		{"anonymous", auth.UserInfo{}, errs.Unauthenticated},
+		{"non-member", auth.UserInfo{Id: uuid.New()}, errs.NotFound},
+		{"member", auth.UserInfo{Id: memberId}, errs.OK},
+	}
+	for _, c := range cases {
+		res, err := s.GetAnalyzeResult(request.ID, &c.user)
+		if !errs.IsCode(err, c.code) {
+			t.Errorf("%s: expected code %d, got %v", c.name, c.code, err)
+		}
+		if err == nil && res.Status != model.RequestStatusPending {
+			t.Errorf("%s: unexpected result %+v", c.name, res)
+		}
+	}
+}